@@ -2,42 +2,272 @@ package carta
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var mapperCache = newCache()
+// global cache of mappers, used unless WithCache or WithNoCache is passed
+var mapperCache = NewCache()
 
-type cache struct {
-	mapCache sync.Map
+// incremented whenever cached mappers become stale, ie, when a converter is registered, for all caches
+var cacheGeneration uint64
+
+// Cache holds mappers planned for combinations of columns, destination types and options,
+// mappers which were not used recently are evicted once the limit is reached. Map uses a global cache,
+// tests and multi-tenant services with divergent schemas can isolate mappers in their own caches with WithCache.
+// Mappers are loaded under a read lock, so that concurrent calls of Map only contend while new mappers are stored
+type Cache struct {
+	// accessed atomically, first in the struct so that they are aligned on 32 bit platforms
+	hits   uint64
+	misses uint64
+	clock  uint64 // incremented whenever a mapper is used, orders mappers by their last use
+
+	mu        sync.RWMutex
+	entries   map[string]*cacheItem
+	limit     int    // maximum number of mappers, 0 if unlimited
+	disabled  bool   // mappers are neither loaded nor stored
+	gen       uint64 // cacheGeneration the entries were planned in
+	evictions uint64
+}
+
+// items are replaced rather than updated once they are stored, except for their counters
+type cacheItem struct {
+	// accessed atomically
+	hits uint64
+	used uint64 // clock of the cache when the mapper was last used
+
+	key    string
+	entry  mapperEntry
+	mapper *Mapper
+	built  time.Duration // time taken to plan the mapper
+}
+
+// CacheStatistics are counters of a cache of mappers since it was created, which show whether the cost of planning mappers
+// is amortized, and help sizing the limit of the cache, ie, a high number of evictions suggests that the limit is too low
+type CacheStatistics struct {
+	Hits      uint64 // mappers loaded from the cache
+	Misses    uint64 // mappers planned because they were not in the cache
+	Evictions uint64 // mappers evicted because of the limit
+
+	Entries []CacheEntry // mappers in the cache, the most recently used first
+}
+
+// CacheEntry describes a mapper in the cache
+type CacheEntry struct {
+	Columns       []string
+	ColumnTypes   []string // database type names of the columns, empty if unknown
+	Destination   reflect.Type
+	BuildDuration time.Duration // time taken to plan the mapper
+	Hits          uint64        // times the mapper was loaded from the cache
+}
+
+// NewCache creates an empty cache of mappers, without a limit, to be passed to WithCache
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]*cacheItem{},
+		gen:     atomic.LoadUint64(&cacheGeneration),
+	}
+}
+
+// WithCache loads and stores mappers in the cache instead of the global cache
+func WithCache(c *Cache) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithNoCache plans a new mapper for the call, without loading or storing it in any cache
+func WithNoCache() Option {
+	return func(o *options) {
+		o.noCache = true
+	}
+}
+
+// cache used by a call, nil if mappers are not cached
+func (o *options) mapperCache() *Cache {
+	switch {
+	case o.noCache, o.uncached && o.funcsKey == "":
+		return nil
+	case o.cache != nil:
+		return o.cache
+	}
+	return mapperCache
+}
+
+// removes mappers of all caches, ie, after registering a converter the mappers were planned without
+func invalidateCaches() {
+	atomic.AddUint64(&cacheGeneration, 1)
+}
+
+// ClearCache removes all mappers of the global cache, ie, after a schema change
+func ClearCache() {
+	mapperCache.Clear()
+}
+
+// SetCacheLimit limits the number of cached mappers, mappers which were not used recently are evicted once the limit is reached,
+// which bounds memory of services generating dynamic column lists, 0 removes the limit, which is the default
+func SetCacheLimit(limit int) {
+	mapperCache.SetLimit(limit)
+}
+
+// CacheStats returns the statistics of the global cache
+func CacheStats() CacheStatistics {
+	return mapperCache.Stats()
 }
 
-func newCache() *cache {
-	return &cache{}
+// DisableCache plans a new mapper on every call, instead of using the cache of mappers, cached mappers are removed
+func DisableCache() {
+	mapperCache.mu.Lock()
+	mapperCache.disabled = true
+	mapperCache.mu.Unlock()
+	mapperCache.Clear()
+}
+
+// EnableCache enables the cache of mappers, after DisableCache
+func EnableCache() {
+	mapperCache.mu.Lock()
+	mapperCache.disabled = false
+	mapperCache.mu.Unlock()
 }
 
 type mapperEntry struct {
-	columns []string
-	dst     reflect.Type
+	columns     []string
+	columnTypes []string // database type names of the columns, converters and unmarshalers are chosen by them
+	dst         reflect.Type
+	opts        string // key of the options the mapper was planned with
 }
 
 func (m *mapperEntry) raw() string {
 	// TODO: test how this works with unexported types
 	// TODO: add a way to provide fully qualified name for the type, since m.typ is always a pointer to a struct or slice
 	// return strings.Join(m.columns, ",") + "|" + m.dst.PkgPath() + "." + m.dst.String()
-	return strings.Join(m.columns, ",") + "|" + m.dst.String()
+	return strings.Join(m.columns, ",") + "|" + strings.Join(m.columnTypes, ",") + "|" + m.dst.String() + "|" + m.opts
+}
+
+// loads the mapper of the key, the key of the mapper entry is computed by the caller, outside of the lock
+func (c *Cache) loadMap(key string) (mapper *Mapper, ok bool) {
+	c.mu.RLock()
+	if c.disabled {
+		c.mu.RUnlock()
+		return nil, false
+	}
+	if atomic.LoadUint64(&cacheGeneration) != c.gen {
+		// stale mappers are removed under the write lock
+		c.mu.RUnlock()
+		c.mu.Lock()
+		c.refresh()
+		c.mu.Unlock()
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	item, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	atomic.AddUint64(&item.hits, 1)
+	atomic.StoreUint64(&item.used, atomic.AddUint64(&c.clock, 1))
+	return item.mapper, true
 }
 
-func (c *cache) loadMap(columns []string, dst reflect.Type) (mapper *Mapper, ok bool) {
-	entry := mapperEntry{columns, dst}
-	vmap, ok := c.mapCache.Load(entry.raw())
-	if ok {
-		mapper = vmap.(*Mapper)
+func (c *Cache) storeMap(key string, entry mapperEntry, mapper *Mapper, built time.Duration) {
+	item := &cacheItem{key: key, entry: entry, mapper: mapper, built: built, used: atomic.AddUint64(&c.clock, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled {
+		return
+	}
+	c.refresh()
+	if prev, ok := c.entries[key]; ok {
+		item.hits = atomic.LoadUint64(&prev.hits)
 	}
-	return
+	c.entries[key] = item
+	c.evict()
+}
+
+// number of mappers sampled for each eviction, the least recently used of them is evicted
+const evictionSamples = 8
+
+// evicts mappers above the limit, must be called with the lock held. Eviction approximates LRU, as Redis does,
+// since the order of iterating maps is random, the least recently used of a few mappers is evicted,
+// so that loading mappers only needs the read lock, instead of moving them in a list under the write lock
+func (c *Cache) evict() {
+	for c.limit > 0 && len(c.entries) > c.limit {
+		var oldest *cacheItem
+		n := 0
+		for _, item := range c.entries {
+			if oldest == nil || atomic.LoadUint64(&item.used) < atomic.LoadUint64(&oldest.used) {
+				oldest = item
+			}
+			if n++; n == evictionSamples {
+				break
+			}
+		}
+		delete(c.entries, oldest.key)
+		c.evictions++
+	}
+}
+
+// removes stale mappers, planned before the cache generation changed, must be called with the lock held
+func (c *Cache) refresh() {
+	if gen := atomic.LoadUint64(&cacheGeneration); gen != c.gen {
+		c.reset()
+		c.gen = gen
+	}
+}
+
+// SetLimit limits the number of mappers in the cache, mappers which were not used recently are evicted once the limit is reached,
+// 0 removes the limit, which is the default
+func (c *Cache) SetLimit(limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limit = limit
+	c.evict()
+}
+
+// Stats returns the statistics of the cache
+func (c *Cache) Stats() CacheStatistics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refresh()
+	items := make([]*cacheItem, 0, len(c.entries))
+	for _, item := range c.entries {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return atomic.LoadUint64(&items[i].used) > atomic.LoadUint64(&items[j].used)
+	})
+	stats := CacheStatistics{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: c.evictions,
+		Entries:   make([]CacheEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		stats.Entries = append(stats.Entries, CacheEntry{
+			Columns:       item.entry.columns,
+			ColumnTypes:   item.entry.columnTypes,
+			Destination:   item.entry.dst,
+			BuildDuration: item.built,
+			Hits:          atomic.LoadUint64(&item.hits),
+		})
+	}
+	return stats
+}
+
+// Clear removes all mappers of the cache, statistics are kept
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
 }
 
-func (c *cache) storeMap(columns []string, dst reflect.Type, mapper *Mapper) {
-	entry := mapperEntry{columns, dst}
-	c.mapCache.Store(entry.raw(), mapper)
+// must be called with the lock held
+func (c *Cache) reset() {
+	c.entries = map[string]*cacheItem{}
 }
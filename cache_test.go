@@ -0,0 +1,188 @@
+package carta_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type cached struct {
+	Id int
+}
+
+// maps a row of the columns onto cached, reporting whether the mapper was loaded from the global cache
+func mapCached(t *testing.T, columns ...string) bool {
+	t.Helper()
+	before := carta.CacheStats().Hits
+	row := make([]interface{}, len(columns))
+	for i := range row {
+		row[i] = int64(1)
+	}
+	dst := []cached{}
+	if err := carta.MapValues(columns, [][]interface{}{row}, &dst); err != nil {
+		t.Fatal(err)
+	}
+	return carta.CacheStats().Hits > before
+}
+
+func TestSetCacheLimit(t *testing.T) {
+	carta.ClearCache()
+	carta.SetCacheLimit(2)
+	defer carta.SetCacheLimit(0)
+	evictions := carta.CacheStats().Evictions
+	mapCached(t, "id", "a")
+	mapCached(t, "id", "b")
+	if !mapCached(t, "id", "a") {
+		t.Fatal("expected the mapper of a to be cached")
+	}
+	// b is the least recently used
+	mapCached(t, "id", "c")
+	stats := carta.CacheStats()
+	if len(stats.Entries) != 2 || stats.Evictions != evictions+1 {
+		t.Fatalf("expected 2 mappers and an eviction, got %d and %d", len(stats.Entries), stats.Evictions-evictions)
+	}
+	if !mapCached(t, "id", "a") || !mapCached(t, "id", "c") {
+		t.Fatal("expected the recently used mappers to be kept")
+	}
+	if mapCached(t, "id", "b") {
+		t.Fatal("expected the least recently used mapper to be evicted")
+	}
+
+	carta.SetCacheLimit(1)
+	if stats := carta.CacheStats(); len(stats.Entries) != 1 || stats.Entries[0].Columns[1] != "b" {
+		t.Fatalf("expected lowering the limit to keep the most recently used mapper, got %+v", stats.Entries)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	mapCached(t, "id")
+	if !mapCached(t, "id") {
+		t.Fatal("expected the mapper to be cached")
+	}
+	carta.ClearCache()
+	if len(carta.CacheStats().Entries) != 0 {
+		t.Fatal("expected no mappers after clearing the cache")
+	}
+	if mapCached(t, "id") {
+		t.Fatal("expected the mapper to be planned again after clearing the cache")
+	}
+}
+
+func TestDisableCache(t *testing.T) {
+	mapCached(t, "id")
+	carta.DisableCache()
+	defer carta.EnableCache()
+	if len(carta.CacheStats().Entries) != 0 {
+		t.Fatal("expected disabling the cache to remove mappers")
+	}
+	if mapCached(t, "id") || mapCached(t, "id") {
+		t.Fatal("expected mappers not to be cached while the cache is disabled")
+	}
+	if len(carta.CacheStats().Entries) != 0 {
+		t.Fatal("expected mappers not to be stored while the cache is disabled")
+	}
+	carta.EnableCache()
+	mapCached(t, "id")
+	if !mapCached(t, "id") {
+		t.Fatal("expected mappers to be cached once the cache is enabled")
+	}
+}
+
+// run with -race, mappers are loaded and stored concurrently, while the limit evicts them
+func TestCacheConcurrent(t *testing.T) {
+	cache := carta.NewCache()
+	cache.SetLimit(4)
+	columns := [][]string{{"id"}, {"id", "a"}, {"id", "b"}, {"id", "c"}, {"id", "d"}, {"id", "e"}}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				cols := columns[(g+n)%len(columns)]
+				row := make([]interface{}, len(cols))
+				for i := range row {
+					row[i] = int64(n)
+				}
+				dst := []cached{}
+				if err := carta.MapValues(cols, [][]interface{}{row}, &dst, carta.WithCache(cache)); err != nil || len(dst) != 1 || dst[0].Id != n {
+					t.Errorf("unexpected %+v and %v", dst, err)
+					return
+				}
+				cache.Stats()
+			}
+		}(g)
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	if len(stats.Entries) > 4 || stats.Hits+stats.Misses != 8*50 {
+		t.Fatalf("expected at most 4 mappers and a hit or miss per call, got %d, %d hits and %d misses", len(stats.Entries), stats.Hits, stats.Misses)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	carta.ClearCache()
+	before := carta.CacheStats()
+	mapCached(t, "id")
+	mapCached(t, "id")
+	mapCached(t, "id", "a")
+	mapCached(t, "id")
+	stats := carta.CacheStats()
+	if stats.Hits-before.Hits != 2 || stats.Misses-before.Misses != 2 {
+		t.Fatalf("expected 2 hits and 2 misses, got %d and %d", stats.Hits-before.Hits, stats.Misses-before.Misses)
+	}
+	if len(stats.Entries) != 2 {
+		t.Fatalf("expected 2 mappers, got %+v", stats.Entries)
+	}
+	// the most recently used first
+	entry := stats.Entries[0]
+	if len(entry.Columns) != 1 || entry.Columns[0] != "id" || entry.Hits != 2 || entry.Destination.String() != "*[]carta_test.cached" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if entry := stats.Entries[1]; len(entry.Columns) != 2 || entry.Hits != 0 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	global := carta.CacheStats()
+	cache := carta.NewCache()
+	for i := 0; i < 3; i++ {
+		dst := []cached{}
+		if err := carta.MapValues([]string{"id"}, [][]interface{}{{int64(i)}}, &dst, carta.WithCache(cache)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || len(stats.Entries) != 1 {
+		t.Fatalf("expected the mapper to be cached in the private cache, got %+v", stats)
+	}
+	if stats := carta.CacheStats(); stats.Hits != global.Hits || stats.Misses != global.Misses {
+		t.Fatal("expected the global cache not to be used")
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); len(stats.Entries) != 0 || stats.Hits != 2 {
+		t.Fatalf("expected clearing to remove mappers and keep statistics, got %+v", stats)
+	}
+}
+
+func TestWithNoCache(t *testing.T) {
+	global := carta.CacheStats()
+	cache := carta.NewCache()
+	for i := 0; i < 2; i++ {
+		dst := []cached{}
+		if err := carta.MapValues([]string{"id"}, [][]interface{}{{int64(i)}}, &dst, carta.WithCache(cache), carta.WithNoCache()); err != nil {
+			t.Fatal(err)
+		}
+		if len(dst) != 1 || dst[0].Id != i {
+			t.Fatalf("unexpected %+v", dst)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 || len(stats.Entries) != 0 {
+		t.Fatalf("expected the cache not to be used, got %+v", stats)
+	}
+	if stats := carta.CacheStats(); stats.Hits != global.Hits || stats.Misses != global.Misses {
+		t.Fatal("expected the global cache not to be used")
+	}
+}
@@ -0,0 +1,150 @@
+// Package cartaarrow maps arrow records, ie, returned by ADBC, the BigQuery Storage API or Flight SQL, onto structs with carta.
+// Values are read from the typed arrays of the columns and set onto carta's cells as they are,
+// without boxing them as interface{} as database/sql does, example:
+//
+//	blogs := []Blog{}
+//	err := cartaarrow.Map(reader, &blogs)
+//
+// It is a module of its own, so that carta does not depend on arrow.
+package cartaarrow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/jackskj/carta"
+)
+
+// Map maps the records of the reader onto dst as carta.Map does, the reader is released
+func Map(r array.RecordReader, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(Rows(r), dst, opts...)
+}
+
+// Rows adapts a reader of arrow records to a source of rows of carta, ie, to pass them to carta.MapAll,
+// columns are the fields of the schema of the reader
+func Rows(r array.RecordReader) carta.RowsSource {
+	return &source{r: r, row: -1}
+}
+
+// source of rows of arrow records, implements carta.RowsSource and carta.ColumnTypeSource
+type source struct {
+	r   array.RecordReader
+	rec arrow.Record // current record, valid until the reader advances
+	row int          // current row of the record
+}
+
+func (s *source) Columns() ([]string, error) {
+	fields := s.r.Schema().Fields()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	return columns, nil
+}
+
+// DatabaseTypeNames returns upper case names of the arrow types of the columns, ie, "INT64", "UTF8" or "TIMESTAMP"
+func (s *source) DatabaseTypeNames() ([]string, error) {
+	fields := s.r.Schema().Fields()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = strings.ToUpper(f.Type.Name())
+	}
+	return names, nil
+}
+
+// Next advances to the next row of the current record, or to the first row of the next record which is not empty
+func (s *source) Next() bool {
+	s.row++
+	for s.rec == nil || s.row >= int(s.rec.NumRows()) {
+		if !s.r.Next() {
+			s.rec = nil
+			return false
+		}
+		s.rec, s.row = s.r.Record(), 0
+	}
+	return true
+}
+
+func (s *source) Scan(dest ...interface{}) error {
+	if int(s.rec.NumCols()) != len(dest) {
+		return fmt.Errorf("cartaarrow: expected %d destinations, got %d", s.rec.NumCols(), len(dest))
+	}
+	for i, d := range dest {
+		setter, ok := d.(carta.ValueSetter)
+		if !ok {
+			return fmt.Errorf("cartaarrow: destination of column %s does not implement carta.ValueSetter", s.rec.ColumnName(i))
+		}
+		setValue(setter, s.rec.Column(i), s.row)
+	}
+	return nil
+}
+
+func (s *source) Err() error {
+	return s.r.Err()
+}
+
+func (s *source) Close() error {
+	s.r.Release()
+	return nil
+}
+
+// sets the value of the row of the array, values of types without a native representation in carta,
+// ie, decimals, durations or nested types, are set as text
+func setValue(setter carta.ValueSetter, arr arrow.Array, row int) {
+	if arr.IsNull(row) {
+		setter.SetNull()
+		return
+	}
+	switch a := arr.(type) {
+	case *array.Boolean:
+		setter.SetBool(a.Value(row))
+	case *array.Int8:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Int16:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Int32:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Int64:
+		setter.SetInt64(a.Value(row))
+	case *array.Uint8:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Uint16:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Uint32:
+		setter.SetInt64(int64(a.Value(row)))
+	case *array.Uint64:
+		// values above the range of int64 are set as text, which is parsed onto unsigned fields
+		if v := a.Value(row); v <= 1<<63-1 {
+			setter.SetInt64(int64(v))
+		} else {
+			setter.SetString(strconv.FormatUint(v, 10))
+		}
+	case *array.Float16:
+		setter.SetFloat64(float64(a.Value(row).Float32()))
+	case *array.Float32:
+		setter.SetFloat64(float64(a.Value(row)))
+	case *array.Float64:
+		setter.SetFloat64(a.Value(row))
+	case *array.String:
+		setter.SetString(a.Value(row))
+	case *array.LargeString:
+		setter.SetString(a.Value(row))
+	case *array.Binary:
+		setter.SetBytes(a.Value(row))
+	case *array.LargeBinary:
+		setter.SetBytes(a.Value(row))
+	case *array.FixedSizeBinary:
+		setter.SetBytes(a.Value(row))
+	case *array.Timestamp:
+		setter.SetTime(a.Value(row).ToTime(a.DataType().(*arrow.TimestampType).Unit))
+	case *array.Date32:
+		setter.SetTime(a.Value(row).ToTime())
+	case *array.Date64:
+		setter.SetTime(a.Value(row).ToTime())
+	default:
+		setter.SetString(arr.ValueStr(row))
+	}
+}
@@ -0,0 +1,172 @@
+package cartaarrow_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartaarrow"
+)
+
+type post struct {
+	PostId int32
+	Score  *float32
+}
+
+type blog struct {
+	BlogId  int64
+	Title   string
+	Views   uint64
+	Rating  float64
+	Public  bool
+	Logo    string
+	Created time.Time
+	Day     time.Time
+	Price   string
+	Summary *string
+	Posts   []post
+}
+
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "blog_id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "title", Type: arrow.BinaryTypes.String},
+	{Name: "views", Type: arrow.PrimitiveTypes.Uint64},
+	{Name: "rating", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "public", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "logo", Type: arrow.BinaryTypes.Binary},
+	{Name: "created", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+	{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+	{Name: "price", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}},
+	{Name: "summary", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "post_id", Type: arrow.PrimitiveTypes.Int32},
+	{Name: "score", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+}, nil)
+
+var created = time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+// appends a row of a blog and a post, summary and score are null if they are empty
+func appendRow(b *array.RecordBuilder, blogId int64, views uint64, summary string, postId int32, score float32) {
+	b.Field(0).(*array.Int64Builder).Append(blogId)
+	b.Field(1).(*array.StringBuilder).Append("blog")
+	b.Field(2).(*array.Uint64Builder).Append(views)
+	b.Field(3).(*array.Float64Builder).Append(4.5)
+	b.Field(4).(*array.BooleanBuilder).Append(true)
+	b.Field(5).(*array.BinaryBuilder).Append([]byte{0xca, 0xfe})
+	b.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(created.UnixMilli()))
+	b.Field(7).(*array.Date32Builder).Append(arrow.Date32FromTime(created))
+	b.Field(8).(*array.Decimal128Builder).Append(decimal128.FromI64(12345))
+	if summary == "" {
+		b.Field(9).AppendNull()
+	} else {
+		b.Field(9).(*array.StringBuilder).Append(summary)
+	}
+	b.Field(10).(*array.Int32Builder).Append(postId)
+	if score == 0 {
+		b.Field(11).AppendNull()
+	} else {
+		b.Field(11).(*array.Float32Builder).Append(score)
+	}
+}
+
+// reader of records of the rows appended by each function, records are released once the reader is
+func newReader(t *testing.T, records ...func(b *array.RecordBuilder)) array.RecordReader {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	t.Cleanup(func() { mem.AssertSize(t, 0) })
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	recs := make([]arrow.Record, len(records))
+	for i, appendRows := range records {
+		appendRows(b)
+		recs[i] = b.NewRecord()
+	}
+	r, err := array.NewRecordReader(schema, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		rec.Release()
+	}
+	return r
+}
+
+func TestMap(t *testing.T) {
+	r := newReader(t,
+		func(b *array.RecordBuilder) {
+			appendRow(b, 1, math.MaxUint64, "about", 10, 0.5)
+			appendRow(b, 1, math.MaxUint64, "about", 11, 0)
+		},
+		// empty records are skipped
+		func(b *array.RecordBuilder) {},
+		// rows of a blog span records
+		func(b *array.RecordBuilder) {
+			appendRow(b, 1, math.MaxUint64, "about", 12, 0)
+			appendRow(b, 2, 7, "", 20, 1.5)
+		},
+	)
+	blogs := []blog{}
+	if err := cartaarrow.Map(r, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.BlogId != 1 || first.Title != "blog" || first.Views != math.MaxUint64 || first.Rating != 4.5 || !first.Public {
+		t.Errorf("unexpected scalars %+v", first)
+	}
+	if first.Logo != "\xca\xfe" {
+		t.Errorf("unexpected binary %x", first.Logo)
+	}
+	if !first.Created.Equal(created) {
+		t.Errorf("expected timestamp %v, got %v", created, first.Created)
+	}
+	if y, m, d := first.Day.Date(); y != 2020 || m != time.January || d != 2 {
+		t.Errorf("unexpected date %v", first.Day)
+	}
+	if first.Price != "123.45" {
+		t.Errorf("expected decimals as text, got %q", first.Price)
+	}
+	if first.Summary == nil || *first.Summary != "about" {
+		t.Errorf("unexpected summary %v", first.Summary)
+	}
+	if len(first.Posts) != 3 || first.Posts[2].PostId != 12 {
+		t.Fatalf("expected posts of the blog across records, got %+v", first.Posts)
+	}
+	if first.Posts[0].Score == nil || *first.Posts[0].Score != 0.5 || first.Posts[1].Score != nil {
+		t.Errorf("unexpected scores %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.BlogId != 2 || second.Views != 7 || second.Summary != nil || len(second.Posts) != 1 || *second.Posts[0].Score != 1.5 {
+		t.Errorf("unexpected second blog %+v", second)
+	}
+}
+
+func TestDatabaseTypeNames(t *testing.T) {
+	rows := cartaarrow.Rows(newReader(t))
+	defer rows.Close()
+	types, err := rows.(carta.ColumnTypeSource).DatabaseTypeNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0] != "INT64" || types[1] != "UTF8" || types[6] != "TIMESTAMP" {
+		t.Fatalf("unexpected types %v", types)
+	}
+}
+
+func TestNullOntoValue(t *testing.T) {
+	r := newReader(t, func(b *array.RecordBuilder) {
+		appendRow(b, 1, 1, "", 10, 0)
+	})
+	rows := []struct {
+		BlogId int64
+		Score  float32
+	}{}
+	if err := cartaarrow.Map(r, &rows); err == nil {
+		t.Fatal("expected an error loading null onto a value")
+	}
+}
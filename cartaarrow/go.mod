@@ -0,0 +1,20 @@
+module github.com/jackskj/carta/cartaarrow
+
+go 1.22.0
+
+require (
+	github.com/apache/arrow-go/v18 v18.0.0
+	github.com/jackskj/carta v0.5.0
+)
+
+require (
+	github.com/goccy/go-json v0.10.3 // indirect
+	github.com/golang/protobuf v1.5.0 // indirect
+	github.com/google/flatbuffers v24.3.25+incompatible // indirect
+	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
+	github.com/zeebo/xxh3 v1.0.2 // indirect
+	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/xerrors v0.0.0-20231012003039-104605ab7028 // indirect
+	google.golang.org/protobuf v1.35.1 // indirect
+)
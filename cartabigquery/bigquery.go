@@ -0,0 +1,199 @@
+// Package cartabigquery maps results of BigQuery onto the nested structs and protos used for queries of other databases with carta,
+// example:
+//
+//	blogs := []Blog{}
+//	err := cartabigquery.Query(ctx, client.Query("select * from blog join post ..."), &blogs)
+//
+// It is a module of its own, so that carta does not depend on BigQuery.
+package cartabigquery
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/jackskj/carta"
+	"google.golang.org/api/iterator"
+)
+
+// Query runs the query, and maps the results onto dst as carta.MapContext does
+func Query(ctx context.Context, q *bigquery.Query, dst interface{}, opts ...carta.Option) error {
+	it, err := q.Read(ctx)
+	if err != nil {
+		return err
+	}
+	return carta.MapContext(ctx, Rows(it), dst, opts...)
+}
+
+// Map maps the rows of the iterator onto dst as carta.Map does
+func Map(it *bigquery.RowIterator, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(Rows(it), dst, opts...)
+}
+
+// Rows adapts a row iterator to a source of rows of carta, ie, to pass them to carta.MapAll,
+// columns are the fields of the schema of the results. Values of numeric columns are loaded as text,
+// dates and datetimes as time.Time in UTC, and repeated or record columns as JSON
+func Rows(it *bigquery.RowIterator) carta.RowsSource {
+	return &source{it: it}
+}
+
+// source of rows of a row iterator, implements carta.RowsSource and carta.ColumnTypeSource
+type source struct {
+	it      *bigquery.RowIterator
+	started bool             // the first row was fetched, which fetches the schema
+	next    []bigquery.Value // first row, fetched before it is read
+	row     []bigquery.Value
+	done    bool
+	err     error
+}
+
+// fetches the first row, the schema of the iterator is only set once rows are fetched
+func (s *source) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.done, s.err = s.fetch(&s.next)
+}
+
+func (s *source) fetch(row *[]bigquery.Value) (bool, error) {
+	err := s.it.Next(row)
+	if errors.Is(err, iterator.Done) {
+		return true, nil
+	}
+	return err != nil, err
+}
+
+func (s *source) Columns() ([]string, error) {
+	s.start()
+	if s.err != nil {
+		return nil, s.err
+	}
+	columns := make([]string, len(s.it.Schema))
+	for i, f := range s.it.Schema {
+		columns[i] = f.Name
+	}
+	return columns, nil
+}
+
+// DatabaseTypeNames returns the BigQuery types of the columns, ie, "INTEGER" or "TIMESTAMP", repeated columns are "ARRAY"
+func (s *source) DatabaseTypeNames() ([]string, error) {
+	s.start()
+	if s.err != nil {
+		return nil, s.err
+	}
+	names := make([]string, len(s.it.Schema))
+	for i, f := range s.it.Schema {
+		names[i] = string(f.Type)
+		if f.Repeated {
+			names[i] = "ARRAY"
+		}
+	}
+	return names, nil
+}
+
+func (s *source) Next() bool {
+	s.start()
+	if s.done {
+		return false
+	}
+	if s.next != nil {
+		s.row, s.next = s.next, nil
+		return true
+	}
+	s.done, s.err = s.fetch(&s.row)
+	return !s.done
+}
+
+func (s *source) Scan(dest ...interface{}) error {
+	if len(s.row) != len(dest) {
+		return fmt.Errorf("cartabigquery: expected %d destinations, got %d", len(s.row), len(dest))
+	}
+	for i, v := range s.row {
+		field := s.it.Schema[i]
+		dv, err := driverValue(v, field)
+		if err != nil {
+			return fmt.Errorf("cartabigquery: cannot load column %s: %w", field.Name, err)
+		}
+		if err = dest[i].(sql.Scanner).Scan(dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *source) Err() error {
+	return s.err
+}
+
+func (s *source) Close() error {
+	return nil
+}
+
+// converts a value of a BigQuery row to a driver value
+func driverValue(v bigquery.Value, field *bigquery.FieldSchema) (driver.Value, error) {
+	if field.Repeated || field.Type == bigquery.RecordFieldType {
+		if v == nil {
+			return nil, nil
+		}
+		return json.Marshal(jsonValue(v, field, field.Repeated))
+	}
+	switch v := v.(type) {
+	case nil, int64, float64, bool, []byte, string, time.Time:
+		return v, nil
+	case civil.Date:
+		return v.In(time.UTC), nil
+	case civil.DateTime:
+		return v.In(time.UTC), nil
+	case civil.Time:
+		return v.String(), nil
+	case *big.Rat:
+		if field.Type == bigquery.BigNumericFieldType {
+			return bigquery.BigNumericString(v), nil
+		}
+		return bigquery.NumericString(v), nil
+	case fmt.Stringer:
+		// ie, intervals
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("unsupported value %v of type %T", v, v)
+}
+
+// converts repeated values to slices, and records to maps keyed by the names of their fields, so that they are marshaled as JSON
+func jsonValue(v bigquery.Value, field *bigquery.FieldSchema, repeated bool) interface{} {
+	switch {
+	case v == nil:
+		return nil
+	case repeated:
+		values := v.([]bigquery.Value)
+		elems := make([]interface{}, len(values))
+		for i, elem := range values {
+			elems[i] = jsonValue(elem, field, false)
+		}
+		return elems
+	case field.Type == bigquery.RecordFieldType:
+		values := v.([]bigquery.Value)
+		record := make(map[string]interface{}, len(values))
+		for i, f := range field.Schema {
+			record[f.Name] = jsonValue(values[i], f, f.Repeated)
+		}
+		return record
+	}
+	switch v := v.(type) {
+	case *big.Rat:
+		if field.Type == bigquery.BigNumericFieldType {
+			return bigquery.BigNumericString(v)
+		}
+		return bigquery.NumericString(v)
+	case civil.Date, civil.DateTime, civil.Time:
+		return fmt.Sprint(v)
+	}
+	return v
+}
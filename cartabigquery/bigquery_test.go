@@ -0,0 +1,167 @@
+package cartabigquery_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartabigquery"
+	"google.golang.org/api/option"
+)
+
+// response of jobs.query, which returns the rows of queries completing within its timeout
+const queryResponse = `{
+	"kind": "bigquery#queryResponse",
+	"jobComplete": true,
+	"jobReference": {"projectId": "project", "jobId": "job", "location": "US"},
+	"totalRows": "3",
+	"schema": {"fields": [
+		{"name": "blog_id", "type": "INTEGER"},
+		{"name": "title", "type": "STRING"},
+		{"name": "rating", "type": "FLOAT"},
+		{"name": "public", "type": "BOOLEAN"},
+		{"name": "created", "type": "TIMESTAMP"},
+		{"name": "day", "type": "DATE"},
+		{"name": "price", "type": "NUMERIC"},
+		{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+		{"name": "author", "type": "RECORD", "fields": [
+			{"name": "name", "type": "STRING"},
+			{"name": "since", "type": "DATE"}
+		]},
+		{"name": "post_id", "type": "INTEGER"},
+		{"name": "summary", "type": "STRING"}
+	]},
+	"rows": [
+		{"f": [{"v": "1"}, {"v": "first"}, {"v": "4.5"}, {"v": "true"}, {"v": "1577934245000000"}, {"v": "2020-01-02"}, {"v": "123.45"},
+			{"v": [{"v": "go"}, {"v": "sql"}]}, {"v": {"f": [{"v": "ann"}, {"v": "2019-05-06"}]}}, {"v": "10"}, {"v": "about"}]},
+		{"f": [{"v": "1"}, {"v": "first"}, {"v": "4.5"}, {"v": "true"}, {"v": "1577934245000000"}, {"v": "2020-01-02"}, {"v": "123.45"},
+			{"v": [{"v": "go"}, {"v": "sql"}]}, {"v": {"f": [{"v": "ann"}, {"v": "2019-05-06"}]}}, {"v": "11"}, {"v": null}]},
+		{"f": [{"v": "2"}, {"v": "second"}, {"v": "3"}, {"v": "false"}, {"v": "1577934245000000"}, {"v": "2020-01-03"}, {"v": "0"},
+			{"v": []}, {"v": null}, {"v": "20"}, {"v": null}]}
+	]
+}`
+
+// client of a fake BigQuery, which answers every query with the response, as well as requests of the results of the job,
+// which are sent if the response of the query holds no rows
+func newClient(t *testing.T, response string) *bigquery.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		query := r.Method == http.MethodPost && r.URL.Path == "/projects/project/queries"
+		results := r.Method == http.MethodGet && r.URL.Path == "/projects/project/queries/job"
+		if !query && !results {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := bigquery.NewClient(context.Background(), "project",
+		option.WithEndpoint(srv.URL), option.WithoutAuthentication(), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+type tags []string
+
+type author struct {
+	Name  string `json:"name"`
+	Since string `json:"since"`
+}
+
+type post struct {
+	PostId  int64
+	Summary *string
+}
+
+type blog struct {
+	BlogId  int64
+	Title   string
+	Rating  float64
+	Public  bool
+	Created time.Time
+	Day     time.Time
+	Price   string
+	Tags    carta.Raw
+	Author  carta.Raw
+	Posts   []post
+}
+
+func TestQuery(t *testing.T) {
+	client := newClient(t, queryResponse)
+	blogs := []blog{}
+	if err := cartabigquery.Query(context.Background(), client.Query("select * from blog"), &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.BlogId != 1 || first.Title != "first" || first.Rating != 4.5 || !first.Public {
+		t.Errorf("unexpected scalars %+v", first)
+	}
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC); !first.Created.Equal(want) {
+		t.Errorf("expected timestamp %v, got %v", want, first.Created)
+	}
+	if want := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC); !first.Day.Equal(want) {
+		t.Errorf("expected dates in UTC, got %v", first.Day)
+	}
+	if first.Price != "123.450000000" {
+		t.Errorf("expected numerics as text with the scale of NUMERIC, got %q", first.Price)
+	}
+	if tags, ok := first.Tags.Value.([]byte); !ok || string(tags) != `["go","sql"]` {
+		t.Errorf("expected repeated columns as JSON, got %v", first.Tags.Value)
+	}
+	if a, ok := first.Author.Value.([]byte); !ok || string(a) != `{"name":"ann","since":"2019-05-06"}` {
+		t.Errorf("expected records as JSON, got %v", first.Author.Value)
+	}
+	if len(first.Posts) != 2 || first.Posts[0].PostId != 10 || *first.Posts[0].Summary != "about" || first.Posts[1].Summary != nil {
+		t.Errorf("unexpected posts %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.Public || second.Price != "0.000000000" || string(second.Tags.Value.([]byte)) != `[]` || second.Author.Value != nil {
+		t.Errorf("unexpected second blog %+v", second)
+	}
+}
+
+func TestDatabaseTypeNames(t *testing.T) {
+	client := newClient(t, queryResponse)
+	it, err := client.Query("select * from blog").Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := cartabigquery.Rows(it)
+	types, err := rows.(carta.ColumnTypeSource).DatabaseTypeNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0] != "INTEGER" || types[4] != "TIMESTAMP" || types[7] != "ARRAY" || types[8] != "RECORD" {
+		t.Fatalf("unexpected types %v", types)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	client := newClient(t, `{
+		"kind": "bigquery#queryResponse",
+		"jobComplete": true,
+		"jobReference": {"projectId": "project", "jobId": "job", "location": "US"},
+		"totalRows": "0",
+		"schema": {"fields": [{"name": "blog_id", "type": "INTEGER"}]}
+	}`)
+	blogs := []blog{}
+	if err := cartabigquery.Query(context.Background(), client.Query("select * from blog"), &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 0 {
+		t.Fatalf("expected no blogs, got %+v", blogs)
+	}
+}
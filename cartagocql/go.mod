@@ -0,0 +1,16 @@
+module github.com/jackskj/carta/cartagocql
+
+go 1.18
+
+require (
+	github.com/gocql/gocql v1.6.0
+	github.com/jackskj/carta v0.5.0
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/golang/snappy v0.0.3 // indirect
+	github.com/hailocab/go-hostpool v0.0.0-20160125115350-e80d13ce29ed // indirect
+	google.golang.org/protobuf v1.23.0 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
+)
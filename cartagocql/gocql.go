@@ -0,0 +1,144 @@
+// Package cartagocql maps rows of Cassandra, read with gocql, onto nested structs with carta, example:
+//
+//	blogs := []Blog{}
+//	err := cartagocql.Query(ctx, session.Query("select * from blog where author_id = ?", authorId), &blogs)
+//
+// Values are loaded as carta loads values of other drivers, null values are null, uuids are loaded as text,
+// elements of tuples are loaded as columns of their own, ie, "point[0]" and "point[1]" of the point column,
+// and lists, sets, maps and user defined types are loaded as JSON, which is decoded onto fields of types
+// registered with carta.RegisterJSON, ie, map[string]int, or passing carta.WithUnnest, lists and sets are loaded
+// as rows of their elements, onto slices of basic types, or onto collections of nested structs.
+// It is a module of its own, so that carta does not depend on gocql.
+package cartagocql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/jackskj/carta"
+)
+
+// Query runs the query, and maps the rows onto dst as carta.MapContext does
+func Query(ctx context.Context, q *gocql.Query, dst interface{}, opts ...carta.Option) error {
+	return carta.MapContext(ctx, Rows(q.WithContext(ctx).Iter()), dst, opts...)
+}
+
+// Map maps the rows of the iterator onto dst as carta.Map does, the iterator is closed
+func Map(iter *gocql.Iter, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(Rows(iter), dst, opts...)
+}
+
+// Rows adapts an iterator to a source of rows of carta, ie, to pass them to carta.MapAll
+func Rows(iter *gocql.Iter) carta.RowsSource {
+	return &source{iter: iter}
+}
+
+// methods of *gocql.Iter which rows are read with
+type iterator interface {
+	Columns() []gocql.ColumnInfo
+	RowData() (gocql.RowData, error)
+	Scan(dest ...interface{}) bool
+	Close() error
+}
+
+// source of rows of an iterator, implements carta.RowsSource and carta.ColumnTypeSource
+type source struct {
+	iter   iterator
+	values []interface{} // values of the current row, pointers to pointers, which are nil for null values
+	closed bool
+	err    error
+}
+
+// Columns returns the names of the columns, tuples are scanned as a column per element, as gocql scans them,
+// named as gocql.TupleColumnName names them, ie, "point[0]"
+func (s *source) Columns() ([]string, error) {
+	var names []string
+	for _, c := range s.iter.Columns() {
+		if tuple, ok := c.TypeInfo.(gocql.TupleTypeInfo); ok {
+			for i := range tuple.Elems {
+				names = append(names, gocql.TupleColumnName(c.Name, i))
+			}
+			continue
+		}
+		names = append(names, c.Name)
+	}
+	return names, nil
+}
+
+// DatabaseTypeNames returns upper case names of the CQL types of the columns, ie, "BIGINT", "TEXT", "LIST" or "UDT",
+// and of the elements of tuples
+func (s *source) DatabaseTypeNames() ([]string, error) {
+	var names []string
+	for _, c := range s.iter.Columns() {
+		if tuple, ok := c.TypeInfo.(gocql.TupleTypeInfo); ok {
+			for _, elem := range tuple.Elems {
+				names = append(names, typeName(elem))
+			}
+			continue
+		}
+		names = append(names, typeName(c.TypeInfo))
+	}
+	return names, nil
+}
+
+// upper case name of the CQL type, gocql does not name user defined types
+func typeName(info gocql.TypeInfo) string {
+	if info.Type() == gocql.TypeUDT {
+		return "UDT"
+	}
+	return strings.ToUpper(info.Type().String())
+}
+
+func (s *source) Next() bool {
+	if s.closed {
+		return false
+	}
+	data, err := s.iter.RowData()
+	if err != nil {
+		s.err = err
+		return false
+	}
+	// values are scanned onto pointers to pointers, which gocql sets to nil for null values
+	s.values = make([]interface{}, len(data.Values))
+	for i, v := range data.Values {
+		s.values[i] = reflect.New(reflect.TypeOf(v)).Interface()
+	}
+	return s.iter.Scan(s.values...)
+}
+
+// Scan passes the values of the row to dest, carta loads them as the values they point to
+func (s *source) Scan(dest ...interface{}) error {
+	if len(s.values) != len(dest) {
+		return fmt.Errorf("cartagocql: expected %d destinations, got %d", len(s.values), len(dest))
+	}
+	for i, v := range s.values {
+		if err := dest[i].(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Err closes the iterator, which returns errors of the query
+func (s *source) Err() error {
+	s.close()
+	return s.err
+}
+
+func (s *source) Close() error {
+	s.close()
+	return nil
+}
+
+func (s *source) close() {
+	if !s.closed {
+		s.closed = true
+		if err := s.iter.Close(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+}
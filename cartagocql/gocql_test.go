@@ -0,0 +1,203 @@
+package cartagocql
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/jackskj/carta"
+)
+
+// iterator of rows encoded as Cassandra returns them, which scans them as *gocql.Iter does
+type fakeIter struct {
+	columns []gocql.ColumnInfo
+	rows    [][][]byte // encoded values of the columns of each row, nil if null
+	n       int
+	err     error
+	closed  bool
+}
+
+func (it *fakeIter) Columns() []gocql.ColumnInfo { return it.columns }
+
+func (it *fakeIter) RowData() (gocql.RowData, error) {
+	var data gocql.RowData
+	for _, c := range it.columns {
+		infos := []gocql.TypeInfo{c.TypeInfo}
+		if tuple, ok := c.TypeInfo.(gocql.TupleTypeInfo); ok {
+			infos = tuple.Elems
+		}
+		for i, info := range infos {
+			v, err := info.NewWithError()
+			if err != nil {
+				return gocql.RowData{}, err
+			}
+			data.Columns = append(data.Columns, gocql.TupleColumnName(c.Name, i))
+			data.Values = append(data.Values, v)
+		}
+	}
+	return data, nil
+}
+
+func (it *fakeIter) Scan(dest ...interface{}) bool {
+	if it.err != nil || it.n >= len(it.rows) {
+		return false
+	}
+	i := 0
+	for c, col := range it.columns {
+		data := it.rows[it.n][c]
+		if tuple, ok := col.TypeInfo.(gocql.TupleTypeInfo); ok {
+			if it.err = gocql.Unmarshal(col.TypeInfo, data, dest[i:i+len(tuple.Elems)]); it.err != nil {
+				return false
+			}
+			i += len(tuple.Elems)
+			continue
+		}
+		if it.err = gocql.Unmarshal(col.TypeInfo, data, dest[i]); it.err != nil {
+			return false
+		}
+		i++
+	}
+	it.n++
+	return true
+}
+
+func (it *fakeIter) Close() error {
+	it.closed = true
+	return it.err
+}
+
+func native(typ gocql.Type) gocql.TypeInfo {
+	return gocql.NewNativeType(4, typ, "")
+}
+
+var columns = []gocql.ColumnInfo{
+	{Name: "blog_id", TypeInfo: native(gocql.TypeBigInt)},
+	{Name: "title", TypeInfo: native(gocql.TypeText)},
+	{Name: "rating", TypeInfo: native(gocql.TypeDouble)},
+	{Name: "public", TypeInfo: native(gocql.TypeBoolean)},
+	{Name: "uid", TypeInfo: native(gocql.TypeUUID)},
+	{Name: "created", TypeInfo: native(gocql.TypeTimestamp)},
+	{Name: "tags", TypeInfo: gocql.CollectionType{NativeType: native(gocql.TypeList).(gocql.NativeType), Elem: native(gocql.TypeText)}},
+	{Name: "counts", TypeInfo: gocql.CollectionType{NativeType: native(gocql.TypeMap).(gocql.NativeType), Key: native(gocql.TypeText), Elem: native(gocql.TypeInt)}},
+	{Name: "author", TypeInfo: gocql.UDTTypeInfo{NativeType: native(gocql.TypeUDT).(gocql.NativeType), Name: "author", Elements: []gocql.UDTField{
+		{Name: "name", Type: native(gocql.TypeText)},
+		{Name: "age", Type: native(gocql.TypeInt)},
+	}}},
+	{Name: "point", TypeInfo: gocql.TupleTypeInfo{NativeType: native(gocql.TypeTuple).(gocql.NativeType), Elems: []gocql.TypeInfo{native(gocql.TypeInt), native(gocql.TypeInt)}}},
+	{Name: "post_id", TypeInfo: native(gocql.TypeInt)},
+	{Name: "summary", TypeInfo: native(gocql.TypeText)},
+}
+
+var (
+	created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	uid     = gocql.UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+)
+
+// encodes the values of a row, nil values are null
+func encodeRow(t *testing.T, values ...interface{}) [][]byte {
+	row := make([][]byte, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		b, err := gocql.Marshal(columns[i].TypeInfo, v)
+		if err != nil {
+			t.Fatalf("cannot marshal %s: %v", columns[i].Name, err)
+		}
+		row[i] = b
+	}
+	return row
+}
+
+func blogRow(t *testing.T, blogId int64, postId int, summary interface{}) [][]byte {
+	return encodeRow(t, blogId, "first", 4.5, true, uid, created, []string{"go", "sql"}, map[string]int{"likes": 3},
+		map[string]interface{}{"name": "ann", "age": 30}, []interface{}{1, 2}, postId, summary)
+}
+
+type post struct {
+	PostId  int
+	Summary *string
+}
+
+type blog struct {
+	BlogId  int64
+	Title   string
+	Rating  float64
+	Public  bool
+	Uid     string
+	Created time.Time
+	Tags    *string
+	Counts  *map[string]int
+	Author  *string
+	X       int `db:"point[0]"`
+	Y       int `db:"point[1]"`
+	Posts   []post
+}
+
+func TestMap(t *testing.T) {
+	carta.RegisterJSON(map[string]int{})
+	it := &fakeIter{columns: columns, rows: [][][]byte{
+		blogRow(t, 1, 10, "about"),
+		blogRow(t, 1, 11, nil),
+		encodeRow(t, int64(2), "second", 3.0, false, uid, created, nil, nil, nil, []interface{}{0, 0}, 20, nil),
+	}}
+	blogs := []blog{}
+	if err := carta.Map(&source{iter: it}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if !it.closed {
+		t.Error("expected the iterator to be closed")
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.BlogId != 1 || first.Title != "first" || first.Rating != 4.5 || !first.Public || !first.Created.Equal(created) {
+		t.Errorf("unexpected scalars %+v", first)
+	}
+	if first.Uid != uid.String() {
+		t.Errorf("expected uuids as text, got %q", first.Uid)
+	}
+	if first.Tags == nil || *first.Tags != `["go","sql"]` || first.Counts == nil || (*first.Counts)["likes"] != 3 ||
+		first.Author == nil || *first.Author != `{"age":30,"name":"ann"}` {
+		t.Errorf("expected collections and user defined types as JSON, got %v, %v and %v", first.Tags, first.Counts, first.Author)
+	}
+	if first.X != 1 || first.Y != 2 {
+		t.Errorf("expected elements of the tuple as columns, got %d and %d", first.X, first.Y)
+	}
+	if len(first.Posts) != 2 || *first.Posts[0].Summary != "about" || first.Posts[1].Summary != nil {
+		t.Errorf("unexpected posts %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.BlogId != 2 || second.Tags != nil || second.Counts != nil || second.Author != nil || len(second.Posts) != 1 || second.Posts[0].PostId != 20 {
+		t.Errorf("expected nulls to leave fields unset, got %+v", second)
+	}
+}
+
+func TestDatabaseTypeNames(t *testing.T) {
+	s := &source{iter: &fakeIter{columns: columns}}
+	names, err := s.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := s.DatabaseTypeNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 13 || len(types) != 13 || names[9] != "point[0]" || names[10] != "point[1]" {
+		t.Fatalf("expected a column per element of tuples, got %v", names)
+	}
+	if types[0] != "BIGINT" || types[6] != "LIST" || types[7] != "MAP" || types[8] != "UDT" || types[9] != "INT" {
+		t.Fatalf("unexpected types %v", types)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	failed := errors.New("unavailable")
+	it := &fakeIter{columns: columns, err: failed}
+	blogs := []blog{}
+	if err := carta.Map(&source{iter: it}, &blogs); err != failed {
+		t.Fatalf("expected the error of the iterator, got %v", err)
+	}
+}
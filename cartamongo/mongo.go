@@ -0,0 +1,247 @@
+// Package cartamongo maps documents of MongoDB onto structs with carta, matching keys of documents with fields
+// as columns are matched, so that read models of SQL and Mongo share one mapping convention, example:
+//
+//	cursor, err := collection.Find(ctx, bson.M{"author_id": 1})
+//	...
+//	blogs := []Blog{}
+//	err = cartamongo.Map(ctx, cursor, &blogs)
+//
+// Keys of the documents are the columns, in the order they first appear, missing keys are null, ids are loaded as hex text,
+// and embedded documents and arrays as JSON, which map onto fields of types registered with carta.RegisterNested or carta.RegisterJSON.
+// It is a module of its own, so that carta does not depend on the driver of MongoDB.
+package cartamongo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackskj/carta"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Find finds documents of the collection matching the filter, and maps them onto dst as Map does
+func Find(ctx context.Context, coll *mongo.Collection, filter interface{}, dst interface{}, opts ...carta.Option) error {
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return Map(ctx, cursor, dst, opts...)
+}
+
+// Map reads the documents of the cursor, and maps them onto dst as carta.MapValues does, the cursor is closed.
+// All documents are read before they are mapped, since the columns are the keys of all of them,
+// use Rows to stream documents of large results
+func Map(ctx context.Context, cursor *mongo.Cursor, dst interface{}, opts ...carta.Option) error {
+	defer cursor.Close(ctx)
+	var (
+		columns []string
+		indexes = map[string]int{}
+		docs    []map[int]interface{}
+	)
+	for cursor.Next(ctx) {
+		elems, err := cursor.Current.Elements()
+		if err != nil {
+			return err
+		}
+		doc := make(map[int]interface{}, len(elems))
+		for _, e := range elems {
+			v, err := driverValue(e.Value())
+			if err != nil {
+				return fmt.Errorf("cartamongo: cannot load key %s: %w", e.Key(), err)
+			}
+			i, ok := indexes[e.Key()]
+			if !ok {
+				i = len(columns)
+				indexes[e.Key()] = i
+				columns = append(columns, e.Key())
+			}
+			doc[i] = v
+		}
+		docs = append(docs, doc)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	rows := make([][]interface{}, len(docs))
+	for n, doc := range docs {
+		rows[n] = make([]interface{}, len(columns))
+		for i, v := range doc {
+			rows[n][i] = v
+		}
+	}
+	return carta.MapValues(columns, rows, dst, opts...)
+}
+
+// Rows adapts a cursor to a source of rows of carta, which reads documents as they are mapped, ie, to pass them to carta.MapEach,
+// so that large results are not held in memory, example:
+//
+//	err = carta.MapEach(cartamongo.Rows(ctx, cursor), (*Blog)(nil), func(dst interface{}) error {
+//		...
+//	})
+//
+// the columns are the keys, or the keys of the first document if none are passed, since keys which are only in later documents
+// are not known before they are read, other keys are not loaded. The cursor is closed with the rows
+func Rows(ctx context.Context, cursor *mongo.Cursor, columns ...string) carta.RowsSource {
+	s := &source{ctx: ctx, cursor: cursor, columns: columns}
+	if len(columns) > 0 {
+		s.index()
+	}
+	return s
+}
+
+// source of rows of a cursor, implements carta.RowsSource
+type source struct {
+	ctx     context.Context
+	cursor  *mongo.Cursor
+	columns []string
+	indexes map[string]int // indexes of the columns
+	first   bool           // the cursor is at the first document, which was read for its keys
+	row     []interface{}
+	err     error
+}
+
+func (s *source) index() {
+	s.indexes = make(map[string]int, len(s.columns))
+	for i, c := range s.columns {
+		s.indexes[c] = i
+	}
+}
+
+func (s *source) Columns() ([]string, error) {
+	if s.indexes == nil {
+		// the keys of the first document
+		s.indexes = map[string]int{}
+		if s.first = s.cursor.Next(s.ctx); s.first {
+			elems, err := s.cursor.Current.Elements()
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range elems {
+				s.columns = append(s.columns, e.Key())
+			}
+			s.index()
+		}
+	}
+	return s.columns, s.cursor.Err()
+}
+
+func (s *source) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.first {
+		s.first = false
+	} else if !s.cursor.Next(s.ctx) {
+		return false
+	}
+	elems, err := s.cursor.Current.Elements()
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.row = make([]interface{}, len(s.columns))
+	for _, e := range elems {
+		i, ok := s.indexes[e.Key()]
+		if !ok {
+			continue
+		}
+		if s.row[i], err = driverValue(e.Value()); err != nil {
+			s.err = fmt.Errorf("cartamongo: cannot load key %s: %w", e.Key(), err)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *source) Scan(dest ...interface{}) error {
+	if len(s.row) != len(dest) {
+		return fmt.Errorf("cartamongo: expected %d destinations, got %d", len(s.row), len(dest))
+	}
+	for i, v := range s.row {
+		if err := dest[i].(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *source) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.cursor.Err()
+}
+
+func (s *source) Close() error {
+	return s.cursor.Close(s.ctx)
+}
+
+// converts a value of a document to a driver value
+func driverValue(v bson.RawValue) (interface{}, error) {
+	switch v.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return nil, nil
+	case bsontype.Double:
+		return v.Double(), nil
+	case bsontype.String:
+		return v.StringValue(), nil
+	case bsontype.Int32:
+		return int64(v.Int32()), nil
+	case bsontype.Int64:
+		return v.Int64(), nil
+	case bsontype.Boolean:
+		return v.Boolean(), nil
+	case bsontype.DateTime:
+		return v.Time(), nil
+	case bsontype.ObjectID:
+		return v.ObjectID().Hex(), nil
+	case bsontype.Decimal128:
+		return v.Decimal128().String(), nil
+	case bsontype.Binary:
+		_, data := v.Binary()
+		return data, nil
+	case bsontype.EmbeddedDocument, bsontype.Array:
+		var decoded interface{}
+		if err := v.Unmarshal(&decoded); err != nil {
+			return nil, err
+		}
+		return json.Marshal(jsonValue(decoded))
+	}
+	return v.String(), nil
+}
+
+// converts decoded documents to maps and arrays to slices, with values which marshal to plain JSON
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case primitive.D:
+		m := make(map[string]interface{}, len(v))
+		for _, e := range v {
+			m[e.Key] = jsonValue(e.Value)
+		}
+		return m
+	case primitive.M:
+		m := make(map[string]interface{}, len(v))
+		for key, e := range v {
+			m[key] = jsonValue(e)
+		}
+		return m
+	case primitive.A:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = jsonValue(e)
+		}
+		return a
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time()
+	case primitive.Decimal128:
+		return v.String()
+	}
+	return v
+}
@@ -0,0 +1,135 @@
+package cartamongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartamongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	oid     = primitive.NewObjectIDFromTimestamp(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	price   = func() primitive.Decimal128 { d, _ := primitive.ParseDecimal128("123.45"); return d }()
+)
+
+func newCursor(t *testing.T, docs ...interface{}) *mongo.Cursor {
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cursor
+}
+
+// document of the keys and values
+func doc(kv ...interface{}) bson.D {
+	d := bson.D{}
+	for i := 0; i < len(kv); i += 2 {
+		d = append(d, bson.E{Key: kv[i].(string), Value: kv[i+1]})
+	}
+	return d
+}
+
+// documents of two blogs, the first with two posts, the second without a title, and with a key the first does not have
+func blogDocs() []interface{} {
+	author := doc("name", "ann", "since", int32(2019))
+	return []interface{}{
+		doc("_id", oid, "blog_id", int32(1), "title", "first", "rating", 4.5, "public", true, "created", created,
+			"price", price, "tags", bson.A{"go", "sql"}, "author", author, "post_id", int64(10)),
+		doc("_id", oid, "blog_id", int32(1), "title", "first", "rating", 4.5, "public", true, "created", created,
+			"price", price, "tags", bson.A{"go", "sql"}, "author", author, "post_id", int64(11)),
+		doc("blog_id", int32(2), "rating", 3.0, "public", false, "post_id", int64(20), "summary", "about"),
+	}
+}
+
+type post struct {
+	PostId  int64
+	Summary *string
+}
+
+type blog struct {
+	Id      *string `db:"_id"`
+	BlogId  int
+	Title   *string
+	Rating  float64
+	Public  bool
+	Created *time.Time
+	Price   *string
+	Tags    *string
+	Author  *string
+	Posts   []post
+}
+
+func TestMap(t *testing.T) {
+	blogs := []blog{}
+	if err := cartamongo.Map(context.Background(), newCursor(t, blogDocs()...), &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if *first.Id != oid.Hex() || first.BlogId != 1 || *first.Title != "first" || first.Rating != 4.5 || !first.Public {
+		t.Errorf("unexpected scalars %+v", first)
+	}
+	if !first.Created.Equal(created) || *first.Price != "123.45" {
+		t.Errorf("unexpected date or decimal %v and %v", first.Created, *first.Price)
+	}
+	if *first.Tags != `["go","sql"]` || *first.Author != `{"name":"ann","since":2019}` {
+		t.Errorf("expected arrays and embedded documents as JSON, got %s and %s", *first.Tags, *first.Author)
+	}
+	if len(first.Posts) != 2 || first.Posts[1].PostId != 11 || first.Posts[0].Summary != nil {
+		t.Errorf("unexpected posts %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.Id != nil || second.Title != nil || second.Tags != nil || len(second.Posts) != 1 || *second.Posts[0].Summary != "about" {
+		t.Errorf("expected missing keys to be null, and keys of later documents to be loaded, got %+v", second)
+	}
+}
+
+func TestRows(t *testing.T) {
+	ids := []int{}
+	err := carta.MapEach(cartamongo.Rows(context.Background(), newCursor(t, blogDocs()...)), (*blog)(nil), func(dst interface{}) error {
+		b := dst.(*blog)
+		ids = append(ids, b.BlogId)
+		if b.BlogId == 1 && len(b.Posts) != 2 {
+			t.Errorf("expected the posts of the first blog, got %+v", b.Posts)
+		}
+		if b.BlogId == 2 && (b.Title != nil || b.Posts[0].Summary != nil) {
+			t.Errorf("expected keys of later documents not to be loaded, got %+v", b)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected blogs 1 and 2, got %v", ids)
+	}
+}
+
+func TestRowsColumns(t *testing.T) {
+	rows := cartamongo.Rows(context.Background(), newCursor(t, blogDocs()...), "blog_id", "post_id", "summary")
+	blogs, err := carta.MapAll[blog](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 || blogs[0].Title != nil || len(blogs[1].Posts) != 1 || *blogs[1].Posts[0].Summary != "about" {
+		t.Fatalf("expected only the columns to be loaded, got %+v", blogs)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	blogs := []blog{}
+	if err := cartamongo.Map(context.Background(), newCursor(t), &blogs); err != nil || len(blogs) != 0 {
+		t.Fatalf("expected no blogs, got %+v and %v", blogs, err)
+	}
+	if err := carta.Map(cartamongo.Rows(context.Background(), newCursor(t)), &blogs); err != nil || len(blogs) != 0 {
+		t.Fatalf("expected no blogs, got %+v and %v", blogs, err)
+	}
+}
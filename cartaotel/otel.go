@@ -0,0 +1,74 @@
+// Package cartaotel traces calls of carta with OpenTelemetry, recording a span per call of Map, so that slow mapping
+// of big joins shows up in distributed traces, example:
+//
+//	carta.SetTracer(cartaotel.NewTracer(otel.GetTracerProvider()))
+//	// or for a single call
+//	err := carta.MapContext(ctx, rows, &blogs, carta.WithTracer(cartaotel.NewTracer(tp)))
+//
+// Spans are children of the span of the context passed to MapContext, they have the type of the destination, the number of rows,
+// the number of entities, whether the mapper was cached and the duration of the mapping as attributes,
+// and an event for every unmatched column or field. Spans of failed calls record the error.
+// It is a module of its own, so that carta does not depend on OpenTelemetry.
+package cartaotel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackskj/carta"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/jackskj/carta/cartaotel"
+
+// Attributes of the spans
+const (
+	DestinationKey = attribute.Key("carta.destination")
+	RowsKey        = attribute.Key("carta.rows")
+	EntitiesKey    = attribute.Key("carta.entities")
+	CacheHitKey    = attribute.Key("carta.cache_hit")
+	DurationKey    = attribute.Key("carta.duration_ms")
+	ColumnKey      = attribute.Key("carta.column")
+	FieldKey       = attribute.Key("carta.field")
+)
+
+// NewTracer returns a tracer of carta which records spans with a tracer of the provider
+func NewTracer(tp trace.TracerProvider) carta.Tracer {
+	return &tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) StartMap(ctx context.Context, dst reflect.Type) func(stats carta.MapStats) {
+	_, span := t.tracer.Start(ctx, "carta.Map",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(DestinationKey.String(fmt.Sprint(dst))),
+	)
+	return func(stats carta.MapStats) {
+		span.SetAttributes(
+			RowsKey.Int(stats.Rows),
+			EntitiesKey.Int(stats.Entities),
+			CacheHitKey.Bool(stats.CacheHit),
+			DurationKey.Float64(float64(stats.Duration.Microseconds())/1000),
+		)
+		for _, w := range stats.Warnings {
+			attrs := []attribute.KeyValue{}
+			if w.Column != "" {
+				attrs = append(attrs, ColumnKey.String(w.Column))
+			} else {
+				attrs = append(attrs, FieldKey.String(w.Field))
+			}
+			span.AddEvent("carta.warning", trace.WithAttributes(attrs...))
+		}
+		if stats.Err != nil {
+			span.RecordError(stats.Err)
+			span.SetStatus(codes.Error, stats.Err.Error())
+		}
+		span.End()
+	}
+}
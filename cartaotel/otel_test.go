@@ -0,0 +1,140 @@
+package cartaotel_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartaotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type post struct {
+	PostId int
+}
+
+type blog struct {
+	BlogId int
+	Title  string
+	Posts  []post
+}
+
+// rows of two blogs, the first with two posts, and an unmatched column
+const recorded = `{
+	"columns": ["blog_id", "title", "post_id", "extra"],
+	"types": ["INT", "TEXT", "INT", "TEXT"],
+	"rows": [
+		[{"int": 1}, {"string": "first"}, {"int": 1}, null],
+		[{"int": 1}, {"string": "first"}, {"int": 2}, null],
+		[{"int": 2}, {"string": "second"}, {"int": 3}, null]
+	]
+}`
+
+func newTracer() (carta.Tracer, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	return cartaotel.NewTracer(tp), sr, tp
+}
+
+func replay(t *testing.T) carta.RowsSource {
+	rows, err := carta.ReplayRows(strings.NewReader(recorded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpan(t *testing.T) {
+	tracer, sr, tp := newTracer()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "handler")
+	cache := carta.NewCache()
+	for i := 0; i < 2; i++ {
+		blogs := []blog{}
+		if err := carta.MapContext(ctx, replay(t), &blogs, carta.WithTracer(tracer), carta.WithCache(cache)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parent.End()
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected spans of both calls and of the parent, got %d", len(spans))
+	}
+	for i, span := range spans[:2] {
+		if span.Name() != "carta.Map" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected carta.Map spans as children of the parent, got %s", span.Name())
+		}
+		attrs := attributes(span)
+		if attrs[cartaotel.DestinationKey].AsString() != "*[]cartaotel_test.blog" {
+			t.Errorf("unexpected destination %v", attrs[cartaotel.DestinationKey])
+		}
+		if attrs[cartaotel.RowsKey].AsInt64() != 3 || attrs[cartaotel.EntitiesKey].AsInt64() != 2 {
+			t.Errorf("expected 3 rows and 2 entities, got %v and %v", attrs[cartaotel.RowsKey], attrs[cartaotel.EntitiesKey])
+		}
+		if hit := attrs[cartaotel.CacheHitKey].AsBool(); hit != (i == 1) {
+			t.Errorf("expected the second call to hit the cache, call %d hit is %t", i+1, hit)
+		}
+		if _, ok := attrs[cartaotel.DurationKey]; !ok {
+			t.Error("expected the duration")
+		}
+		if span.Status().Code == codes.Error {
+			t.Errorf("unexpected error status %v", span.Status())
+		}
+	}
+
+	events := spans[0].Events()
+	if len(events) != 1 || events[0].Name != "carta.warning" {
+		t.Fatalf("expected a warning event for the unmatched column, got %+v", events)
+	}
+	if len(events[0].Attributes) != 1 || events[0].Attributes[0] != cartaotel.ColumnKey.String("extra") {
+		t.Errorf("unexpected attributes of the warning %v", events[0].Attributes)
+	}
+}
+
+func TestSpanFieldWarning(t *testing.T) {
+	tracer, sr, _ := newTracer()
+	rows := []struct {
+		BlogId int
+		Rating *float64
+	}{}
+	if err := carta.MapValues([]string{"blog_id"}, [][]interface{}{{1}}, &rows, carta.WithTracer(tracer)); err != nil {
+		t.Fatal(err)
+	}
+	events := sr.Ended()[0].Events()
+	if len(events) != 1 || events[0].Attributes[0] != cartaotel.FieldKey.String("Rating") {
+		t.Fatalf("expected a warning event for the unmatched field, got %+v", events)
+	}
+}
+
+func TestSpanError(t *testing.T) {
+	tracer, sr, _ := newTracer()
+	blogs := []blog{}
+	err := carta.MapContext(context.Background(), replay(t), &blogs, carta.WithTracer(tracer), carta.WithStrictColumns())
+	if !errors.Is(err, carta.ErrUnmatchedColumn) {
+		t.Fatalf("expected ErrUnmatchedColumn, got %v", err)
+	}
+	span := sr.Ended()[0]
+	if span.Status().Code != codes.Error || span.Status().Description != err.Error() {
+		t.Fatalf("expected the error status, got %+v", span.Status())
+	}
+	recorded := false
+	for _, e := range span.Events() {
+		recorded = recorded || e.Name == "exception"
+	}
+	if !recorded {
+		t.Fatalf("expected the error to be recorded, got %+v", span.Events())
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/jackskj/carta/cartapgx
+
+go 1.21
+
+require (
+	github.com/jackc/pgx/v5 v5.5.5
+	github.com/jackskj/carta v0.5.0
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/protobuf v1.23.0 // indirect
+)
@@ -0,0 +1,221 @@
+// Package cartapgx maps rows of pgx v5 onto structs with carta, without the database/sql layer.
+// Values decoded by pgx, ie, pgtype.Numeric, timestamps or arrays, are passed to carta as the driver values the database/sql
+// driver of pgx returns, ie, numerics and inet as text, and arrays as postgres array literals, so that fields are loaded as they are
+// from database/sql, fields of pgx types, ie, pgtype.Numeric, are not scanned by pgx. Columns keep the names of their postgres types,
+// so that converters registered for them are chosen, example:
+//
+//	rows, err := conn.Query(ctx, "select * from blog")
+//	...
+//	blogs := []Blog{}
+//	err = cartapgx.Map(rows, &blogs)
+//
+// It is a module of its own, so that carta does not depend on pgx.
+package cartapgx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackskj/carta"
+)
+
+// Querier runs queries, it is implemented by *pgx.Conn, *pgxpool.Pool and pgx.Tx
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// Map maps rows of pgx onto dst as carta.Map does, rows are closed
+func Map(rows pgx.Rows, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(Rows(rows), dst, opts...)
+}
+
+// MapContext maps rows of pgx onto dst as carta.MapContext does, rows are closed
+func MapContext(ctx context.Context, rows pgx.Rows, dst interface{}, opts ...carta.Option) error {
+	return carta.MapContext(ctx, Rows(rows), dst, opts...)
+}
+
+// Query runs the query with args on db, and maps the rows onto dst as carta.Query does
+func Query(ctx context.Context, db Querier, query string, args []interface{}, dst interface{}, opts ...carta.Option) error {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return MapContext(ctx, rows, dst, opts...)
+}
+
+// Rows adapts rows of pgx to a source of rows of carta, ie, to pass them to carta.MapAll
+func Rows(rows pgx.Rows) carta.RowsSource {
+	return &source{rows: rows}
+}
+
+// source of rows of pgx, implements carta.RowsSource and carta.ColumnTypeSource
+type source struct {
+	rows pgx.Rows
+}
+
+func (s *source) Columns() ([]string, error) {
+	fields := s.rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	return columns, nil
+}
+
+// DatabaseTypeNames returns upper case names of the postgres types of the columns, as the database/sql driver of pgx does,
+// types unknown to the type map of the connection have empty names
+func (s *source) DatabaseTypeNames() ([]string, error) {
+	typeMap := pgtype.NewMap()
+	if conn := s.rows.Conn(); conn != nil {
+		typeMap = conn.TypeMap()
+	}
+	fields := s.rows.FieldDescriptions()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if t, ok := typeMap.TypeForOID(f.DataTypeOID); ok {
+			names[i] = strings.ToUpper(t.Name)
+		}
+	}
+	return names, nil
+}
+
+func (s *source) Next() bool {
+	return s.rows.Next()
+}
+
+// Scan passes the values decoded by pgx to dest as driver values
+func (s *source) Scan(dest ...interface{}) error {
+	values, err := s.rows.Values()
+	if err != nil {
+		return err
+	}
+	if len(values) != len(dest) {
+		return fmt.Errorf("cartapgx: expected %d destinations, got %d", len(values), len(dest))
+	}
+	fields := s.rows.FieldDescriptions()
+	for i, v := range values {
+		scanner, ok := dest[i].(sql.Scanner)
+		if !ok {
+			return fmt.Errorf("cartapgx: destination of column %s does not implement sql.Scanner", fields[i].Name)
+		}
+		dv, err := driverValue(v)
+		if err != nil {
+			return fmt.Errorf("cartapgx: cannot load column %s: %w", fields[i].Name, err)
+		}
+		if err = scanner.Scan(dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *source) Err() error {
+	return s.rows.Err()
+}
+
+func (s *source) Close() error {
+	s.rows.Close()
+	return nil
+}
+
+// converts a value decoded by pgx to a driver value, as the database/sql driver of pgx would return it,
+// types implementing driver.Valuer, ie, pgtype.Numeric or pgtype.Interval, return their values,
+// types implementing encoding.TextMarshaler or fmt.Stringer, ie, netip.Prefix of inet columns or net.HardwareAddr
+// of macaddr columns, are formatted as text, uuids are formatted, arrays are formatted as postgres array literals,
+// and other maps and structs, ie, of json columns, are marshaled
+func driverValue(v interface{}) (driver.Value, error) {
+	switch v := v.(type) {
+	case nil, int64, float64, bool, []byte, string, time.Time:
+		return v, nil
+	case [16]byte:
+		return formatUUID(v), nil
+	case driver.Valuer:
+		return driver.DefaultParameterConverter.ConvertValue(v)
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		return dv, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return arrayLiteral(rv)
+	case reflect.Map, reflect.Struct:
+		return json.Marshal(v)
+	}
+	return nil, fmt.Errorf("unsupported value %v of type %T", v, v)
+}
+
+func formatUUID(u [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// formats elements of an array as a postgres array literal, ie, {1,2,NULL} or {"a b","c"}
+func arrayLiteral(rv reflect.Value) (string, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < rv.Len(); i++ {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Interface {
+			elem = elem.Elem()
+		}
+		if !elem.IsValid() {
+			b.WriteString("NULL")
+			continue
+		}
+		if k := elem.Kind(); (k == reflect.Slice && elem.Type().Elem().Kind() != reflect.Uint8) || k == reflect.Array && elem.Type() != reflect.TypeOf([16]byte{}) {
+			nested, err := arrayLiteral(elem)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(nested)
+			continue
+		}
+		dv, err := driverValue(elem.Interface())
+		if err != nil {
+			return "", err
+		}
+		switch dv := dv.(type) {
+		case nil:
+			b.WriteString("NULL")
+		case int64:
+			b.WriteString(strconv.FormatInt(dv, 10))
+		case float64:
+			b.WriteString(strconv.FormatFloat(dv, 'g', -1, 64))
+		case bool:
+			b.WriteString(strconv.FormatBool(dv))
+		case time.Time:
+			b.WriteString(quoteElement(dv.Format(time.RFC3339Nano)))
+		case []byte:
+			b.WriteString(quoteElement(string(dv)))
+		case string:
+			b.WriteString(quoteElement(dv))
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+func quoteElement(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,40 @@
+package cartapgx
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDriverValue(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want driver.Value
+	}{
+		{int64(1), int64(1)},
+		{int32(1), int64(1)},
+		{"text", "text"},
+		{[16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}, "12345678-9abc-def0-1234-56789abcdef0"},
+		{netip.MustParsePrefix("10.0.0.0/8"), "10.0.0.0/8"},
+		{netip.MustParseAddr("192.168.0.1"), "192.168.0.1"},
+		{net.HardwareAddr{0x08, 0x00, 0x2b, 0x01, 0x02, 0x03}, "08:00:2b:01:02:03"},
+		{pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, "123.45"},
+		{[]interface{}{int64(1), nil, "a b"}, `{1,NULL,"a b"}`},
+		{map[string]interface{}{"a": float64(1)}, []byte(`{"a":1}`)},
+	}
+	for _, test := range tests {
+		got, err := driverValue(test.v)
+		if err != nil {
+			t.Errorf("driver value of %v of type %T failed with %s", test.v, test.v, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("driver value of %v of type %T is %#v, expected %#v", test.v, test.v, got, test.want)
+		}
+	}
+}
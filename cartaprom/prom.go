@@ -0,0 +1,108 @@
+// Package cartaprom collects metrics of calls of carta.Map for Prometheus, labelled by the type of the destination,
+// so that latency of mapping and efficiency of the cache of mappers can be dashboarded, example:
+//
+//	collector := cartaprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	carta.SetMetricsHook(collector)
+//
+// It is a module of its own, so that carta does not depend on the client of Prometheus.
+package cartaprom
+
+import (
+	"reflect"
+
+	"github.com/jackskj/carta"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a carta.MetricsHook, and a prometheus.Collector of the metrics:
+//
+//	carta_map_duration_seconds     histogram of durations of calls
+//	carta_map_in_flight            calls which have not ended
+//	carta_map_rows_total           rows read
+//	carta_map_entities_total       top level entities mapped
+//	carta_map_cache_hits_total     calls which used a cached mapper
+//	carta_map_cache_misses_total   calls which built a mapper
+//	carta_map_errors_total         calls which failed
+//
+// every metric is labelled by the type of the destination, ie, destination="*[]main.Blog"
+type Collector struct {
+	duration    *prometheus.HistogramVec
+	inFlight    *prometheus.GaugeVec
+	rows        *prometheus.CounterVec
+	entities    *prometheus.CounterVec
+	cacheHits   *prometheus.CounterVec
+	cacheMisses *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+}
+
+// NewCollector returns a collector with the default buckets of Prometheus
+func NewCollector() *Collector {
+	return NewCollectorWithBuckets(prometheus.DefBuckets)
+}
+
+// NewCollectorWithBuckets returns a collector with the buckets, in seconds, of the histogram of durations
+func NewCollectorWithBuckets(buckets []float64) *Collector {
+	labels := []string{"destination"}
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: "carta", Subsystem: "map", Name: name, Help: help}, labels)
+	}
+	return &Collector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "carta", Subsystem: "map", Name: "duration_seconds",
+			Help: "Duration of calls of carta.Map.", Buckets: buckets,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "carta", Subsystem: "map", Name: "in_flight",
+			Help: "Calls of carta.Map which have not ended.",
+		}, labels),
+		rows:        counter("rows_total", "Rows read by calls of carta.Map."),
+		entities:    counter("entities_total", "Top level entities mapped by calls of carta.Map."),
+		cacheHits:   counter("cache_hits_total", "Calls of carta.Map which used a cached mapper."),
+		cacheMisses: counter("cache_misses_total", "Calls of carta.Map which built a mapper."),
+		errors:      counter("errors_total", "Calls of carta.Map which failed."),
+	}
+}
+
+func (c *Collector) OnMapStart(dst reflect.Type) {
+	c.inFlight.WithLabelValues(destination(dst)).Inc()
+}
+
+func (c *Collector) OnMapDone(stats carta.MapStats) {
+	dst := destination(stats.Destination)
+	c.inFlight.WithLabelValues(dst).Dec()
+	c.duration.WithLabelValues(dst).Observe(stats.Duration.Seconds())
+	c.rows.WithLabelValues(dst).Add(float64(stats.Rows))
+	c.entities.WithLabelValues(dst).Add(float64(stats.Entities))
+	if stats.CacheHit {
+		c.cacheHits.WithLabelValues(dst).Inc()
+	} else {
+		c.cacheMisses.WithLabelValues(dst).Inc()
+	}
+	if stats.Err != nil {
+		c.errors.WithLabelValues(dst).Inc()
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.duration, c.inFlight, c.rows, c.entities, c.cacheHits, c.cacheMisses, c.errors}
+}
+
+func destination(typ reflect.Type) string {
+	if typ == nil {
+		return "<nil>"
+	}
+	return typ.String()
+}
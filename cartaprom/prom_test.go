@@ -0,0 +1,89 @@
+package cartaprom_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartaprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type blog struct {
+	Id int
+}
+
+func TestCollector(t *testing.T) {
+	c := cartaprom.NewCollectorWithBuckets([]float64{1})
+	dst := reflect.TypeOf(&[]blog{})
+	for _, stats := range []carta.MapStats{
+		{Destination: dst, Rows: 3, Entities: 2, Duration: time.Millisecond},
+		{Destination: dst, Rows: 1, Entities: 1, CacheHit: true, Duration: time.Millisecond},
+		{Destination: dst, CacheHit: true, Duration: 2 * time.Second, Err: errors.New("carta: failed")},
+	} {
+		c.OnMapStart(dst)
+		c.OnMapDone(stats)
+	}
+	c.OnMapStart(dst)
+
+	expected := `
+# HELP carta_map_cache_hits_total Calls of carta.Map which used a cached mapper.
+# TYPE carta_map_cache_hits_total counter
+carta_map_cache_hits_total{destination="*[]cartaprom_test.blog"} 2
+# HELP carta_map_cache_misses_total Calls of carta.Map which built a mapper.
+# TYPE carta_map_cache_misses_total counter
+carta_map_cache_misses_total{destination="*[]cartaprom_test.blog"} 1
+# HELP carta_map_duration_seconds Duration of calls of carta.Map.
+# TYPE carta_map_duration_seconds histogram
+carta_map_duration_seconds_bucket{destination="*[]cartaprom_test.blog",le="1"} 2
+carta_map_duration_seconds_bucket{destination="*[]cartaprom_test.blog",le="+Inf"} 3
+carta_map_duration_seconds_sum{destination="*[]cartaprom_test.blog"} 2.002
+carta_map_duration_seconds_count{destination="*[]cartaprom_test.blog"} 3
+# HELP carta_map_entities_total Top level entities mapped by calls of carta.Map.
+# TYPE carta_map_entities_total counter
+carta_map_entities_total{destination="*[]cartaprom_test.blog"} 3
+# HELP carta_map_errors_total Calls of carta.Map which failed.
+# TYPE carta_map_errors_total counter
+carta_map_errors_total{destination="*[]cartaprom_test.blog"} 1
+# HELP carta_map_in_flight Calls of carta.Map which have not ended.
+# TYPE carta_map_in_flight gauge
+carta_map_in_flight{destination="*[]cartaprom_test.blog"} 1
+# HELP carta_map_rows_total Rows read by calls of carta.Map.
+# TYPE carta_map_rows_total counter
+carta_map_rows_total{destination="*[]cartaprom_test.blog"} 4
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorRegister(t *testing.T) {
+	c := cartaprom.NewCollector()
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := carta.ReplayRows(strings.NewReader(`{"columns": ["id"], "types": ["INT"], "rows": [[{"int": 1}], [{"int": 2}]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blogs := []blog{}
+	if err = carta.Map(rows, &blogs, carta.WithMetricsHook(c)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+# HELP carta_map_rows_total Rows read by calls of carta.Map.
+# TYPE carta_map_rows_total counter
+carta_map_rows_total{destination="*[]cartaprom_test.blog"} 2
+`
+	if err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "carta_map_rows_total"); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := testutil.GatherAndLint(reg); err != nil || len(problems) > 0 {
+		t.Fatalf("expected metrics to pass the linter, got %v, %v", problems, err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package cartaspanner maps rows of Cloud Spanner onto structs with carta, so that services using Spanner and other databases
+// share the same mapping, example:
+//
+//	blogs := []Blog{}
+//	err := cartaspanner.Query(ctx, client.Single(), spanner.Statement{SQL: "select * from blog join post ..."}, &blogs)
+//
+// It is a module of its own, so that carta does not depend on Spanner.
+package cartaspanner
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/jackskj/carta"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Querier runs queries, it is implemented by *spanner.ReadOnlyTransaction and *spanner.ReadWriteTransaction
+type Querier interface {
+	Query(ctx context.Context, statement spanner.Statement) *spanner.RowIterator
+}
+
+// Query runs the statement on txn, and maps the rows onto dst as carta.MapContext does
+func Query(ctx context.Context, txn Querier, statement spanner.Statement, dst interface{}, opts ...carta.Option) error {
+	return carta.MapContext(ctx, Rows(txn.Query(ctx, statement)), dst, opts...)
+}
+
+// Map maps the rows of the iterator onto dst as carta.Map does, the iterator is stopped
+func Map(it *spanner.RowIterator, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(Rows(it), dst, opts...)
+}
+
+// Rows adapts a row iterator to a source of rows of carta, ie, to pass them to carta.MapAll.
+// Null values of Spanner, ie, of spanner.NullInt64, are null, numerics are loaded as text,
+// dates as time.Time in UTC, json as raw JSON, and arrays and structs as JSON of their values, as Spanner encodes them
+func Rows(it *spanner.RowIterator) carta.RowsSource {
+	return &source{it: it}
+}
+
+// source of rows of a row iterator, implements carta.RowsSource and carta.ColumnTypeSource
+type source struct {
+	it      *spanner.RowIterator
+	started bool         // the first row was fetched, which fetches the metadata
+	next    *spanner.Row // first row, fetched before it is read
+	row     *spanner.Row
+	done    bool
+	err     error
+}
+
+// fetches the first row, the metadata of the iterator is only set once rows are fetched
+func (s *source) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.next, s.done, s.err = s.fetch()
+}
+
+func (s *source) fetch() (*spanner.Row, bool, error) {
+	row, err := s.it.Next()
+	if errors.Is(err, iterator.Done) {
+		return nil, true, nil
+	}
+	return row, err != nil, err
+}
+
+func (s *source) fields() []*sppb.StructType_Field {
+	if s.it.Metadata == nil {
+		return nil
+	}
+	return s.it.Metadata.GetRowType().GetFields()
+}
+
+func (s *source) Columns() ([]string, error) {
+	s.start()
+	if s.err != nil {
+		return nil, s.err
+	}
+	fields := s.fields()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.GetName()
+	}
+	return columns, nil
+}
+
+// DatabaseTypeNames returns the Spanner types of the columns, ie, "INT64" or "TIMESTAMP"
+func (s *source) DatabaseTypeNames() ([]string, error) {
+	s.start()
+	if s.err != nil {
+		return nil, s.err
+	}
+	fields := s.fields()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.GetType().GetCode().String()
+	}
+	return names, nil
+}
+
+func (s *source) Next() bool {
+	s.start()
+	if s.done {
+		return false
+	}
+	if s.next != nil {
+		s.row, s.next = s.next, nil
+		return true
+	}
+	s.row, s.done, s.err = s.fetch()
+	return !s.done
+}
+
+func (s *source) Scan(dest ...interface{}) error {
+	if s.row.Size() != len(dest) {
+		return fmt.Errorf("cartaspanner: expected %d destinations, got %d", s.row.Size(), len(dest))
+	}
+	fields := s.fields()
+	for i := range dest {
+		v, err := columnValue(s.row, i, fields[i].GetType())
+		if err != nil {
+			return fmt.Errorf("cartaspanner: cannot load column %s: %w", fields[i].GetName(), err)
+		}
+		if err = dest[i].(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *source) Err() error {
+	return s.err
+}
+
+func (s *source) Close() error {
+	s.it.Stop()
+	return nil
+}
+
+// decodes the ith column of the row to a driver value
+func columnValue(row *spanner.Row, i int, t *sppb.Type) (driver.Value, error) {
+	switch t.GetCode() {
+	case sppb.TypeCode_INT64:
+		var v spanner.NullInt64
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.Int64, err)
+	case sppb.TypeCode_FLOAT64:
+		var v spanner.NullFloat64
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.Float64, err)
+	case sppb.TypeCode_FLOAT32:
+		var v spanner.NullFloat32
+		err := row.Column(i, &v)
+		return nullable(v.Valid, float64(v.Float32), err)
+	case sppb.TypeCode_BOOL:
+		var v spanner.NullBool
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.Bool, err)
+	case sppb.TypeCode_STRING:
+		var v spanner.NullString
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.StringVal, err)
+	case sppb.TypeCode_BYTES:
+		var v []byte
+		err := row.Column(i, &v)
+		return nullable(v != nil, v, err)
+	case sppb.TypeCode_TIMESTAMP:
+		var v spanner.NullTime
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.Time, err)
+	case sppb.TypeCode_DATE:
+		var v spanner.NullDate
+		err := row.Column(i, &v)
+		return nullable(v.Valid, v.Date.In(time.UTC), err)
+	case sppb.TypeCode_NUMERIC:
+		if t.GetTypeAnnotation() == sppb.TypeAnnotationCode_PG_NUMERIC {
+			var v spanner.PGNumeric
+			err := row.Column(i, &v)
+			return nullable(v.Valid, v.Numeric, err)
+		}
+		var v spanner.NullNumeric
+		err := row.Column(i, &v)
+		return nullable(v.Valid, spanner.NumericString(&v.Numeric), err)
+	case sppb.TypeCode_JSON:
+		if t.GetTypeAnnotation() == sppb.TypeAnnotationCode_PG_JSONB {
+			var v spanner.PGJsonB
+			err := row.Column(i, &v)
+			return nullableJSON(v.Valid, v.Value, err)
+		}
+		var v spanner.NullJSON
+		err := row.Column(i, &v)
+		return nullableJSON(v.Valid, v.Value, err)
+	}
+	// arrays, structs and other types are loaded as JSON of their values
+	var v spanner.GenericColumnValue
+	if err := row.Column(i, &v); err != nil {
+		return nil, err
+	}
+	if _, null := v.Value.GetKind().(*structpb.Value_NullValue); null {
+		return nil, nil
+	}
+	return protojson.Marshal(v.Value)
+}
+
+func nullable(valid bool, v driver.Value, err error) (driver.Value, error) {
+	if err != nil || !valid {
+		return nil, err
+	}
+	return v, nil
+}
+
+func nullableJSON(valid bool, v interface{}, err error) (driver.Value, error) {
+	if err != nil || !valid {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
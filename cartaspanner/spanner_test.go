@@ -0,0 +1,148 @@
+package cartaspanner_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"cloud.google.com/go/spanner/spannertest"
+	"cloud.google.com/go/spanner/spansql"
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartaspanner"
+)
+
+const ddl = `CREATE TABLE blog (
+	blog_id INT64 NOT NULL,
+	title STRING(MAX),
+	rating FLOAT64,
+	public BOOL,
+	logo BYTES(MAX),
+	created TIMESTAMP,
+	day DATE,
+	tags ARRAY<STRING(MAX)>,
+) PRIMARY KEY (blog_id);
+CREATE TABLE post (
+	post_id INT64 NOT NULL,
+	blog_id INT64,
+	summary STRING(MAX),
+) PRIMARY KEY (post_id)`
+
+var created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// client of an in-memory Spanner holding two blogs, the first with two posts
+func newClient(t *testing.T) *spanner.Client {
+	srv, err := spannertest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	stmts, err := spansql.ParseDDL("ddl", ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = srv.UpdateDDL(stmts); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPANNER_EMULATOR_HOST", srv.Addr)
+	ctx := context.Background()
+	client, err := spanner.NewClient(ctx, "projects/project/instances/instance/databases/database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	_, err = client.Apply(ctx, []*spanner.Mutation{
+		spanner.Insert("blog", []string{"blog_id", "title", "rating", "public", "logo", "created", "day", "tags"},
+			[]interface{}{1, "first", 4.5, true, []byte{0xca, 0xfe}, created, civil.DateOf(created), []string{"go", "sql"}}),
+		spanner.Insert("blog", []string{"blog_id", "title"}, []interface{}{2, "second"}),
+		spanner.Insert("post", []string{"post_id", "blog_id", "summary"}, []interface{}{10, 1, "about"}),
+		spanner.Insert("post", []string{"post_id", "blog_id"}, []interface{}{11, 1}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+type post struct {
+	PostId  *int64
+	Summary *string
+}
+
+type blog struct {
+	BlogId  int64
+	Title   string
+	Rating  *float64
+	Public  *bool
+	Logo    *string
+	Created *time.Time
+	Day     *time.Time
+	Tags    carta.Raw
+	Posts   []post
+}
+
+const query = `SELECT b.blog_id, b.title, b.rating, b.public, b.logo, b.created, b.day, b.tags, p.post_id, p.summary
+	FROM blog AS b LEFT JOIN post AS p ON b.blog_id = p.blog_id ORDER BY b.blog_id, p.post_id`
+
+func TestQuery(t *testing.T) {
+	client := newClient(t)
+	blogs := []blog{}
+	if err := cartaspanner.Query(context.Background(), client.Single(), spanner.Statement{SQL: query}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.BlogId != 1 || first.Title != "first" || *first.Rating != 4.5 || !*first.Public || *first.Logo != "\xca\xfe" {
+		t.Errorf("unexpected scalars %+v", first)
+	}
+	if !first.Created.Equal(created) {
+		t.Errorf("expected timestamp %v, got %v", created, first.Created)
+	}
+	if want := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC); !first.Day.Equal(want) {
+		t.Errorf("expected dates in UTC, got %v", first.Day)
+	}
+	// protojson does not produce stable whitespace, so the JSON is compared decoded
+	var tags []string
+	if b, ok := first.Tags.Value.([]byte); !ok || json.Unmarshal(b, &tags) != nil || len(tags) != 2 || tags[0] != "go" || tags[1] != "sql" {
+		t.Errorf("expected arrays as JSON, got %s", first.Tags.Value)
+	}
+	if len(first.Posts) != 2 || *first.Posts[0].PostId != 10 || *first.Posts[0].Summary != "about" || first.Posts[1].Summary != nil {
+		t.Errorf("unexpected posts %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.Rating != nil || second.Public != nil || second.Logo != nil || second.Created != nil || second.Day != nil || second.Tags.Value != nil {
+		t.Errorf("expected nulls to leave fields unset, got %+v", second)
+	}
+	if len(second.Posts) != 1 || second.Posts[0].PostId != nil {
+		t.Errorf("expected an empty post of the left join, got %+v", second.Posts)
+	}
+}
+
+func TestDatabaseTypeNames(t *testing.T) {
+	client := newClient(t)
+	rows := cartaspanner.Rows(client.Single().Query(context.Background(), spanner.Statement{SQL: query}))
+	defer rows.Close()
+	types, err := rows.(carta.ColumnTypeSource).DatabaseTypeNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0] != "INT64" || types[5] != "TIMESTAMP" || types[7] != "ARRAY" {
+		t.Fatalf("unexpected types %v", types)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	client := newClient(t)
+	blogs := []blog{}
+	stmt := spanner.Statement{SQL: "SELECT blog_id, title FROM blog WHERE blog_id = @id", Params: map[string]interface{}{"id": 3}}
+	if err := cartaspanner.Query(context.Background(), client.Single(), stmt, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 0 {
+		t.Fatalf("expected no blogs, got %+v", blogs)
+	}
+}
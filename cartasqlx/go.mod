@@ -0,0 +1,14 @@
+module github.com/jackskj/carta/cartasqlx
+
+go 1.18
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/jackskj/carta v0.5.0
+	github.com/jmoiron/sqlx v1.3.5
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	google.golang.org/protobuf v1.23.0 // indirect
+)
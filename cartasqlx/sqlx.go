@@ -0,0 +1,66 @@
+// Package cartasqlx helps adopting carta in codebases using sqlx, ie, to map nested structs and slices
+// of selected queries, while keeping sqlx for the rest. *sqlx.Rows can be passed to carta.Map as they are,
+// this package adds Select and Get with the signatures of sqlx, example:
+//
+//	blogs := []Blog{}
+//	err := cartasqlx.Select(ctx, db, &blogs, "select * from blog join post on ...", authorId)
+//
+// Fields match columns as they do in sqlx, by their "db" tags, or by their lower case names, which carta matches as well.
+// sqlx matches fields of nested structs with columns prefixed with their parents, ie, "author.name", pass carta.WithAutoPrefix
+// to SelectWith, GetWith, NamedSelect or Map for the same behavior, carta matches them by their names by default.
+// It is a module of its own, so that carta does not depend on sqlx.
+package cartasqlx
+
+import (
+	"context"
+
+	"github.com/jackskj/carta"
+	"github.com/jmoiron/sqlx"
+)
+
+// Select runs the query with args on q, as sqlx.SelectContext does, and maps the rows onto dst with carta,
+// dst is any destination of carta.Map, ie, a pointer to a slice of structs with nested slices
+func Select(ctx context.Context, q sqlx.QueryerContext, dst interface{}, query string, args ...interface{}) error {
+	return SelectWith(ctx, q, dst, query, args)
+}
+
+// SelectWith runs the query as Select does, and maps the rows with the options, since args of Select are variadic, example:
+//
+//	err := cartasqlx.SelectWith(ctx, db, &blogs, "select * from blog join post on ...", []interface{}{authorId}, carta.WithStrictColumns())
+func SelectWith(ctx context.Context, q sqlx.QueryerContext, dst interface{}, query string, args []interface{}, opts ...carta.Option) error {
+	rows, err := q.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return carta.MapContext(ctx, rows, dst, opts...)
+}
+
+// Get runs the query with args on q, as sqlx.GetContext does, and maps the rows onto exactly one struct with carta.MapSingle,
+// returns sql.ErrNoRows if there are no rows, as sqlx does, and carta.ErrTooManyRows if rows map onto more than one struct
+func Get(ctx context.Context, q sqlx.QueryerContext, dst interface{}, query string, args ...interface{}) error {
+	return GetWith(ctx, q, dst, query, args)
+}
+
+// GetWith runs the query as Get does, and maps the rows with the options
+func GetWith(ctx context.Context, q sqlx.QueryerContext, dst interface{}, query string, args []interface{}, opts ...carta.Option) error {
+	rows, err := q.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return carta.MapSingle(rows, dst, opts...)
+}
+
+// NamedSelect runs the query with named parameters taken from arg on e, as sqlx.NamedQueryContext does,
+// and maps the rows onto dst with carta and the options
+func NamedSelect(ctx context.Context, e sqlx.ExtContext, dst interface{}, query string, arg interface{}, opts ...carta.Option) error {
+	rows, err := sqlx.NamedQueryContext(ctx, e, query, arg)
+	if err != nil {
+		return err
+	}
+	return carta.MapContext(ctx, rows, dst, opts...)
+}
+
+// Map maps *sqlx.Rows onto dst as carta.Map does, ie, rows of sqlx.DB.Queryx or sqlx.DB.NamedQuery, rows are closed
+func Map(rows *sqlx.Rows, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(rows, dst, opts...)
+}
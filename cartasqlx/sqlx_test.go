@@ -0,0 +1,129 @@
+package cartasqlx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartasqlx"
+	"github.com/jmoiron/sqlx"
+)
+
+type post struct {
+	PostId int
+}
+
+type blog struct {
+	BlogId int `db:"blog_id"`
+	Title  string
+	Posts  []post
+}
+
+func newDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return sqlx.NewDb(db, "mysql"), mock
+}
+
+func blogRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"blog_id", "title", "post_id"}).
+		AddRow(1, "first", 1).
+		AddRow(1, "first", 2).
+		AddRow(2, "second", 3)
+}
+
+func TestSelect(t *testing.T) {
+	db, mock := newDB(t)
+	mock.ExpectQuery("select * from blog where author_id = ?").WithArgs(7).WillReturnRows(blogRows())
+	blogs := []blog{}
+	if err := cartasqlx.Select(context.Background(), db, &blogs, "select * from blog where author_id = ?", 7); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 || len(blogs[0].Posts) != 2 || blogs[0].Posts[1].PostId != 2 || blogs[1].Title != "second" {
+		t.Fatalf("unexpected blogs %+v", blogs)
+	}
+}
+
+func TestSelectWith(t *testing.T) {
+	db, mock := newDB(t)
+	rows := sqlmock.NewRows([]string{"blog_id", "title", "post_id", "extra"}).AddRow(1, "first", 1, "x")
+	mock.ExpectQuery("select * from blog where author_id = ?").WithArgs(7).WillReturnRows(rows)
+	blogs := []blog{}
+	err := cartasqlx.SelectWith(context.Background(), db, &blogs, "select * from blog where author_id = ?", []interface{}{7}, carta.WithStrictColumns())
+	if !errors.Is(err, carta.ErrUnmatchedColumn) {
+		t.Fatalf("expected options to be passed to carta, got %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	db, mock := newDB(t)
+	mock.ExpectQuery("select * from blog where blog_id = ?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"blog_id", "title", "post_id"}).AddRow(1, "first", 1).AddRow(1, "first", 2))
+	mock.ExpectQuery("select * from blog where blog_id = ?").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"blog_id", "title", "post_id"}))
+	mock.ExpectQuery("select * from blog").WillReturnRows(blogRows())
+
+	var b blog
+	if err := cartasqlx.Get(context.Background(), db, &b, "select * from blog where blog_id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if b.BlogId != 1 || len(b.Posts) != 2 {
+		t.Fatalf("unexpected blog %+v", b)
+	}
+	if err := cartasqlx.Get(context.Background(), db, &b, "select * from blog where blog_id = ?", 3); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+	if err := cartasqlx.GetWith(context.Background(), db, &b, "select * from blog", nil, carta.WithoutDedup()); !errors.Is(err, carta.ErrTooManyRows) {
+		t.Fatalf("expected carta.ErrTooManyRows, got %v", err)
+	}
+}
+
+func TestNamedSelect(t *testing.T) {
+	db, mock := newDB(t)
+	mock.ExpectQuery("select * from blog where author_id = ?").WithArgs(7).WillReturnRows(blogRows())
+	blogs := []blog{}
+	arg := map[string]interface{}{"author_id": 7}
+	if err := cartasqlx.NamedSelect(context.Background(), db, &blogs, "select * from blog where author_id = :author_id", arg, carta.WithReplace()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 || len(blogs[0].Posts) != 2 {
+		t.Fatalf("unexpected blogs %+v", blogs)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	db, mock := newDB(t)
+	failed := errors.New("connection refused")
+	mock.ExpectQuery("select * from blog").WillReturnError(failed)
+	blogs := []blog{}
+	if err := cartasqlx.Select(context.Background(), db, &blogs, "select * from blog"); err != failed {
+		t.Fatalf("expected the error of the query, got %v", err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	db, mock := newDB(t)
+	mock.ExpectQuery("select * from blog").WillReturnRows(blogRows())
+	rows, err := db.Queryx("select * from blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blogs := []blog{}
+	if err := cartasqlx.Map(rows, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("unexpected blogs %+v", blogs)
+	}
+}
@@ -0,0 +1,206 @@
+// Package cartatest declares result sets as Go literals or YAML fixtures, and asserts what carta maps them onto,
+// so that mappings of complex joins can be unit tested without a database, example:
+//
+//	func TestBlogs(t *testing.T) {
+//		rows := cartatest.NewRows("blog_id", "title", "post_id", "post_title").
+//			AddRow(1, "first", 10, "hello").
+//			AddRow(1, "first", 11, "world")
+//		cartatest.Assert(t, rows, []Blog{{Id: 1, Title: "first", Posts: []Post{{Id: 10, Title: "hello"}, {Id: 11, Title: "world"}}}})
+//	}
+//
+// Rows are returned by a database/sql connection of sqlmock, so that values are scanned as they are from a driver,
+// and column types, when declared, are returned by the rows as the database would, which chooses registered converters.
+// It is a module of its own, so that carta does not depend on sqlmock or yaml.
+package cartatest
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackskj/carta"
+	"gopkg.in/yaml.v3"
+)
+
+// Rows declares a result set, as columns, their database types, and rows of values
+type Rows struct {
+	Columns []string        `yaml:"columns"`
+	Types   []string        `yaml:"types"` // database type names of the columns, ie, "INT8", optional
+	Values  [][]interface{} `yaml:"rows"`
+}
+
+// NewRows declares a result set of the columns, without rows
+func NewRows(columns ...string) *Rows {
+	return &Rows{Columns: columns}
+}
+
+// WithTypes declares the database type names of the columns, ie, to test converters registered for types of columns
+func (r *Rows) WithTypes(types ...string) *Rows {
+	r.Types = types
+	return r
+}
+
+// AddRow adds a row with a value for each column, nil values are null
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	r.Values = append(r.Values, values)
+	return r
+}
+
+// LoadFixture reads rows from a YAML file, example:
+//
+//	columns: [blog_id, title, post_id, post_title]
+//	types: [INT8, TEXT, INT8, TEXT]
+//	rows:
+//	  - [1, first, 10, hello]
+//	  - [1, first, 11, null]
+//
+// types are optional, and values which are mappings or sequences are loaded as JSON,
+// ie, onto fields registered with carta.RegisterNested or carta.RegisterJSON
+func LoadFixture(path string) (*Rows, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rows{}
+	if err = yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("cartatest: cannot read fixture %s: %w", path, err)
+	}
+	if len(r.Columns) == 0 {
+		return nil, fmt.Errorf("cartatest: fixture %s has no columns", path)
+	}
+	return r, nil
+}
+
+// MustLoadFixture reads rows from a YAML file as LoadFixture does, and fails the test if the file cannot be read
+func MustLoadFixture(t testing.TB, path string) *Rows {
+	t.Helper()
+	r, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// SQLMock returns the rows for expectations of sqlmock, to test code which queries and maps rows, example:
+//
+//	mockRows, err := rows.SQLMock()
+//	...
+//	mock.ExpectQuery("select").WillReturnRows(mockRows)
+func (r *Rows) SQLMock() (*sqlmock.Rows, error) {
+	if len(r.Types) != 0 && len(r.Types) != len(r.Columns) {
+		return nil, fmt.Errorf("cartatest: %d types declared for %d columns", len(r.Types), len(r.Columns))
+	}
+	cols := make([]*sqlmock.Column, len(r.Columns))
+	for i, name := range r.Columns {
+		typ := ""
+		if len(r.Types) != 0 {
+			typ = r.Types[i]
+		}
+		cols[i] = sqlmock.NewColumn(name).OfType(typ, nil).Nullable(true)
+	}
+	rows := sqlmock.NewRowsWithColumnDefinition(cols...)
+	for n, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, fmt.Errorf("cartatest: row %d has %d values, expected a value for each of %d columns", n+1, len(row), len(r.Columns))
+		}
+		values := make([]driver.Value, len(row))
+		for i, v := range row {
+			dv, err := driverValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("cartatest: cannot load value of column %s of row %d: %w", r.Columns[i], n+1, err)
+			}
+			values[i] = dv
+		}
+		rows.AddRow(values...)
+	}
+	return rows, nil
+}
+
+// Map maps the rows onto dst as carta.Map does, the rows are queried from a connection of sqlmock
+func (r *Rows) Map(dst interface{}, opts ...carta.Option) error {
+	mockRows, err := r.SQLMock()
+	if err != nil {
+		return err
+	}
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(string, string) error { return nil })))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	mock.ExpectQuery("").WillReturnRows(mockRows)
+	rows, err := db.Query("cartatest")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return carta.Map(rows, dst, opts...)
+}
+
+// Assert maps the rows onto a new value of the type of want, which is a slice or a struct, or a pointer to either,
+// and fails the test unless the mapped value equals want, the values are printed as JSON when they differ
+func Assert(t testing.TB, rows *Rows, want interface{}, opts ...carta.Option) {
+	t.Helper()
+	got, err := mapOnto(rows, reflect.TypeOf(want), opts...)
+	if err != nil {
+		t.Fatalf("cartatest: cannot map rows: %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cartatest: mapped rows differ\ngot:  %s\nwant: %s", format(got), format(want))
+	}
+}
+
+// AssertFixture maps the rows of the YAML file onto a new value of the type of want, as Assert does
+func AssertFixture(t testing.TB, path string, want interface{}, opts ...carta.Option) {
+	t.Helper()
+	Assert(t, MustLoadFixture(t, path), want, opts...)
+}
+
+// AssertError fails the test unless mapping the rows onto dst fails
+func AssertError(t testing.TB, rows *Rows, dst interface{}, opts ...carta.Option) error {
+	t.Helper()
+	err := rows.Map(dst, opts...)
+	if err == nil {
+		t.Errorf("cartatest: expected mapping onto %T to fail", dst)
+	}
+	return err
+}
+
+// maps the rows onto a new value of typ, and returns it as typ
+func mapOnto(rows *Rows, typ reflect.Type, opts ...carta.Option) (interface{}, error) {
+	if typ == nil {
+		return nil, fmt.Errorf("cartatest: expected value is nil")
+	}
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+	dst := reflect.New(typ)
+	if err := rows.Map(dst.Interface(), opts...); err != nil {
+		return nil, err
+	}
+	if isPtr {
+		return dst.Interface(), nil
+	}
+	return dst.Elem().Interface(), nil
+}
+
+// converts a value declared in go or in a fixture to a driver value
+func driverValue(v interface{}) (driver.Value, error) {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return json.Marshal(v)
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+func format(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(data)
+}
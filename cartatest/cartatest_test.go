@@ -0,0 +1,130 @@
+package cartatest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/cartatest"
+)
+
+type post struct {
+	Id    *int
+	Title *string
+}
+
+type summary struct {
+	BlogId int
+	Title  string
+}
+
+type blog struct {
+	BlogId int
+	Title  string
+	Counts map[string]int
+	Tags   []string
+	Posts  []post
+}
+
+func init() {
+	carta.RegisterJSON(map[string]int{})
+	carta.RegisterJSON([]string{})
+}
+
+func hello() *string {
+	s := "hello"
+	return &s
+}
+
+func id(i int) *int {
+	return &i
+}
+
+var fixtureBlogs = []blog{
+	{BlogId: 1, Title: "first", Counts: map[string]int{"views": 10}, Tags: []string{"go", "sql"}, Posts: []post{{Id: id(10), Title: hello()}, {Id: id(11)}}},
+	{BlogId: 2, Title: "second", Posts: []post{{Id: id(12)}}},
+}
+
+func TestAssert(t *testing.T) {
+	rows := cartatest.NewRows("blog_id", "title", "posts_id", "posts_title").
+		AddRow(1, "first", 10, "hello").
+		AddRow(1, "first", 11, nil)
+	cartatest.Assert(t, rows, []blog{{BlogId: 1, Title: "first", Posts: []post{{Id: id(10), Title: hello()}, {Id: id(11)}}}})
+	cartatest.Assert(t, rows, &blog{BlogId: 1, Title: "first", Posts: []post{{Id: id(10), Title: hello()}, {Id: id(11)}}})
+}
+
+func TestAssertFixture(t *testing.T) {
+	rows, err := cartatest.LoadFixture("testdata/blogs.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows.Types) != 6 || rows.Types[2] != "JSONB" {
+		t.Fatalf("expected the types of the fixture, got %v", rows.Types)
+	}
+	cartatest.AssertFixture(t, "testdata/blogs.yaml", fixtureBlogs)
+}
+
+// recorder records failures of assertions, instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.fatal = append(r.fatal, fmt.Sprintf(format, args...))
+}
+
+func TestAssertFails(t *testing.T) {
+	rows := cartatest.NewRows("blog_id", "title").AddRow(1, "first")
+	r := &recorder{TB: t}
+	cartatest.Assert(r, rows, []summary{{BlogId: 1, Title: "other"}})
+	if len(r.errors) != 1 || len(r.fatal) != 0 {
+		t.Fatalf("expected a single failure for rows mapping onto other blogs, got %v and %v", r.errors, r.fatal)
+	}
+
+	r = &recorder{TB: t}
+	cartatest.Assert(r, cartatest.NewRows("blog_id", "title").AddRow(1), []summary{})
+	if len(r.fatal) != 1 {
+		t.Fatalf("expected a fatal failure for a row missing a value, got %v", r.fatal)
+	}
+}
+
+func TestAssertError(t *testing.T) {
+	rows := cartatest.NewRows("blog_id", "title").AddRow("one", "first")
+	if err := cartatest.AssertError(t, rows, &[]summary{}); err == nil {
+		t.Fatal("expected the error of mapping text onto an integer")
+	}
+
+	r := &recorder{TB: t}
+	cartatest.AssertError(r, cartatest.NewRows("blog_id").AddRow(1), &[]summary{})
+	if len(r.errors) != 1 {
+		t.Fatalf("expected a failure for rows which map without an error, got %v", r.errors)
+	}
+}
+
+func TestWithTypes(t *testing.T) {
+	rows := cartatest.NewRows("blog_id", "title").WithTypes("INT8", "TEXT").AddRow(1, "first")
+	mockRows, err := rows.SQLMock()
+	if err != nil || mockRows == nil {
+		t.Fatalf("expected rows of sqlmock, got %v", err)
+	}
+	if _, err := cartatest.NewRows("blog_id", "title").WithTypes("INT8").SQLMock(); err == nil {
+		t.Fatal("expected an error for fewer types than columns")
+	}
+	seen := []string{}
+	transform := carta.WithValueTransformer(func(column string, v interface{}) (interface{}, error) {
+		seen = append(seen, column)
+		return v, nil
+	})
+	cartatest.Assert(t, rows, []summary{{BlogId: 1, Title: "first"}}, transform)
+	if len(seen) != 2 {
+		t.Fatalf("expected options to be passed to carta, got values of %v", seen)
+	}
+}
@@ -0,0 +1,347 @@
+// Package cartavet is an analyzer of golang.org/x/tools/go/analysis, which reports mistakes in destinations passed to carta,
+// as go vet reports mistakes in calls of fmt.Printf, example:
+//
+//	blogs := []Blog{}
+//	err := carta.Map(rows, blogs) // destination of carta.Map is []Blog, not a pointer, ie, &blogs
+//
+// structs passed to carta, and the structs nested in them, are checked for fields matching the same column,
+// unexported fields with tags, fields of types which cannot be loaded from columns, and unknown options of tags.
+// The analyzer can be run with cmd/cartavet, or with other analyzers, ie, by golangci-lint or multichecker.
+// It is a module of its own, so that carta does not depend on golang.org/x/tools.
+package cartavet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+
+	"github.com/jackskj/carta/internal/gen"
+	"github.com/jackskj/carta/internal/tags"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const cartaPath = "github.com/jackskj/carta"
+
+// Analyzer reports mistakes in destinations of carta
+var Analyzer = &analysis.Analyzer{
+	Name:     "carta",
+	Doc:      "reports mistakes in destinations of carta, such as destinations which are not pointers, or fields matching the same column",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// tagKey is the key of the tags naming columns, set with the -tag flag, as with carta.SetTagKey
+var tagKey = "db"
+
+func init() {
+	Analyzer.Flags.StringVar(&tagKey, "tag", tagKey, "key of the tags naming columns")
+}
+
+// positions of the destination arguments of functions of carta
+var destinations = map[string]int{
+	"Map":              1,
+	"MapContext":       2,
+	"MapSingle":        1,
+	"MapEach":          1,
+	"MapCSV":           1,
+	"MapCSVReader":     1,
+	"MapJSON":          1,
+	"MapToJSON":        2,
+	"MapToJSONContext": 3,
+	"MapToStream":      2,
+	"MapValues":        2,
+	"Query":            4,
+	"QueryNamed":       4,
+	"NewMapper":        0,
+	"DescribeMapping":  0,
+	"CheckTable":       2,
+	"CheckQuery":       2,
+}
+
+// positions of slices of destinations passed to functions of carta, whose elements are checked if the slices are literals
+var destinationSlices = map[string]int{
+	"MapMulti":  1,
+	"MapJoined": 1,
+}
+
+// positions of the destination arguments of methods of carta.Mapper
+var methodDestinations = map[string]int{
+	"Map":        1,
+	"MapContext": 2,
+}
+
+// generic functions of carta, whose type parameter is the type of the mapped entities
+var generics = map[string]bool{
+	"MapAll":  true,
+	"MapOne":  true,
+	"MapSeq":  true,
+	"MapChan": true,
+}
+
+type checker struct {
+	pass    *analysis.Pass
+	checked map[types.Type]bool // structs which were already checked
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	c := &checker{pass: pass, checked: map[types.Type]bool{}}
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != cartaPath {
+			return
+		}
+		name, positions := "carta."+fn.Name(), destinations
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			if named, ok := deref(recv.Type()).(*types.Named); !ok || named.Obj().Name() != "Mapper" {
+				return
+			}
+			name, positions = "carta.Mapper."+fn.Name(), methodDestinations
+		} else if generics[fn.Name()] {
+			if inst, ok := pass.TypesInfo.Instances[calleeIdent(call.Fun)]; ok && inst.TypeArgs.Len() != 0 {
+				c.element(call, name, inst.TypeArgs.At(0))
+			}
+			return
+		} else if i, ok := destinationSlices[fn.Name()]; ok {
+			if i < len(call.Args) {
+				if lit, ok := ast.Unparen(call.Args[i]).(*ast.CompositeLit); ok {
+					for _, arg := range lit.Elts {
+						c.destination(c.unwrapPrefixed(arg), name)
+					}
+				}
+			}
+			return
+		}
+		i, ok := positions[fn.Name()]
+		if !ok {
+			return
+		}
+		if i < len(call.Args) {
+			c.destination(call.Args[i], name)
+		}
+	})
+	return nil, nil
+}
+
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	case *ast.IndexExpr:
+		return calleeIdent(fun.X)
+	case *ast.IndexListExpr:
+		return calleeIdent(fun.X)
+	}
+	return nil
+}
+
+// destination wrapped with carta.Prefixed, which is passed to MapJoined, or the argument itself
+func (c *checker) unwrapPrefixed(arg ast.Expr) ast.Expr {
+	call, ok := ast.Unparen(arg).(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return arg
+	}
+	if fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == cartaPath && fn.Name() == "Prefixed" {
+		return call.Args[1]
+	}
+	return arg
+}
+
+// checks a destination, which must be a pointer to a slice, a map, a struct or a basic type
+func (c *checker) destination(arg ast.Expr, fn string) {
+	tv, ok := c.pass.TypesInfo.Types[arg]
+	if !ok {
+		return
+	}
+	if tv.IsNil() {
+		c.pass.Reportf(arg.Pos(), "destination of %s is nil", fn)
+		return
+	}
+	t := tv.Type
+	if types.IsInterface(t) {
+		// known only at runtime
+		return
+	}
+	ptr, ok := t.Underlying().(*types.Pointer)
+	if !ok {
+		switch t.Underlying().(type) {
+		case *types.Slice, *types.Struct, *types.Map:
+			c.pass.Reportf(arg.Pos(), "destination of %s is %s, not a pointer, ie, &%s", fn, typeString(c.pass, t), types.ExprString(arg))
+		default:
+			c.pass.Reportf(arg.Pos(), "destination of %s is %s, not a pointer", fn, typeString(c.pass, t))
+		}
+		return
+	}
+	elem := ptr.Elem()
+	switch u := elem.Underlying().(type) {
+	case *types.Slice:
+		if !isBasic(elem) {
+			c.element(arg, fn, u.Elem())
+		}
+	case *types.Map:
+		e := deref(u.Elem())
+		if _, ok := e.Underlying().(*types.Struct); !ok || isBasic(e) {
+			c.pass.Reportf(arg.Pos(), "destination of %s is %s, elements of maps must be structs", fn, typeString(c.pass, t))
+			return
+		}
+		c.structure(arg.Pos(), e)
+	default:
+		c.element(arg, fn, elem)
+	}
+}
+
+// checks the type of mapped entities, which must be structs, pointers to structs, or basic types
+func (c *checker) element(at ast.Node, fn string, t types.Type) {
+	e := deref(t)
+	if isBasic(e) {
+		return
+	}
+	switch e.Underlying().(type) {
+	case *types.Struct:
+		c.structure(at.Pos(), e)
+	case *types.Interface, *types.TypeParam:
+		// known only at runtime
+	default:
+		c.pass.Reportf(at.Pos(), "%s cannot map rows onto %s, entities must be structs or basic types", fn, typeString(c.pass, t))
+	}
+}
+
+// checks the fields of a struct, and of the structs nested in it, at is reported for structs declared in other packages
+func (c *checker) structure(at token.Pos, t types.Type) {
+	if c.checked[t] {
+		return
+	}
+	c.checked[t] = true
+	st := t.Underlying().(*types.Struct)
+	name := typeString(c.pass, t)
+	columns := map[string]string{} // fields by the columns they match
+	for i := 0; i < st.NumFields(); i++ {
+		field, tag := st.Field(i), reflect.StructTag(st.Tag(i))
+		pos := at
+		if field.Pkg() == c.pass.Pkg {
+			pos = field.Pos()
+		}
+		column, opts := tags.Parse(tag.Get(tagKey))
+		if column == "-" {
+			continue
+		}
+		if !field.Exported() {
+			if tag.Get(tagKey) != "" {
+				c.pass.Reportf(pos, "field %s of %s is tagged %s:%q, but is unexported, carta does not map unexported fields", field.Name(), name, tagKey, tag.Get(tagKey))
+			}
+			continue
+		}
+		for opt, v := range opts {
+			if !tags.Options[opt] {
+				c.pass.Reportf(pos, "field %s of %s is tagged with the unknown option %q", field.Name(), name, opt)
+			} else if _, ok := tags.Units[v]; opt == "unit" && !ok {
+				c.pass.Reportf(pos, "field %s of %s is tagged with the unknown unit %q, expected one of ns, us, ms, s, m, h", field.Name(), name, v)
+			}
+		}
+		ft := deref(field.Type())
+		if isUnsupported(ft) {
+			c.pass.Reportf(pos, "field %s of %s is of type %s, which cannot be loaded from a column", field.Name(), name, typeString(c.pass, field.Type()))
+			continue
+		}
+		if nested := nestedStruct(ft); nested != nil {
+			c.structure(at, nested)
+			continue
+		}
+		if column == "" {
+			column = field.Name()
+		}
+		for _, candidate := range gen.Candidates(column, nil) {
+			if other, ok := columns[candidate]; ok && other != field.Name() {
+				c.pass.Reportf(pos, "fields %s and %s of %s both match column %s", other, field.Name(), name, candidate)
+				break
+			}
+			columns[candidate] = field.Name()
+		}
+	}
+}
+
+// struct nested in a field of type t, either as an association or a collection, nil if the field is basic
+func nestedStruct(t types.Type) types.Type {
+	if s, ok := t.Underlying().(*types.Slice); ok {
+		t = deref(s.Elem())
+	}
+	if _, ok := t.Underlying().(*types.Struct); ok && !isBasic(t) {
+		return t
+	}
+	return nil
+}
+
+// packages of structs which carta loads from a single column, ie, time.Time or sql.NullString
+var basicPackages = map[string]bool{
+	"time":               true,
+	"database/sql":       true,
+	"net":                true,
+	cartaPath + "/value": true,
+	"github.com/golang/protobuf/ptypes/timestamp":        true,
+	"github.com/golang/protobuf/ptypes/duration":         true,
+	"github.com/golang/protobuf/ptypes/wrappers":         true,
+	"github.com/golang/protobuf/ptypes/struct":           true,
+	"google.golang.org/protobuf/types/known/timestamppb": true,
+	"google.golang.org/protobuf/types/known/durationpb":  true,
+	"google.golang.org/protobuf/types/known/wrapperspb":  true,
+	"google.golang.org/protobuf/types/known/structpb":    true,
+}
+
+// methods of types which carta loads from a single column
+var basicMethods = []string{"Scan", "UnmarshalText", "UnmarshalBinary"}
+
+// types which are loaded from a single column, types with converters registered at runtime are not known
+func isBasic(t types.Type) bool {
+	t = deref(t)
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return true
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return true
+		}
+	}
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && basicPackages[named.Obj().Pkg().Path()] {
+		return true
+	}
+	mset := types.NewMethodSet(types.NewPointer(t))
+	for _, m := range basicMethods {
+		if mset.Lookup(nil, m) != nil {
+			return true
+		}
+		// unexported methods are looked up in the package of the type
+		if named, ok := t.(*types.Named); ok && mset.Lookup(named.Obj().Pkg(), m) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// types which cannot be loaded from columns, unless a converter is registered, which is unlikely
+func isUnsupported(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Chan, *types.Signature:
+		return true
+	case *types.Basic:
+		return u.Info()&types.IsComplex != 0 || u.Kind() == types.UnsafePointer || u.Kind() == types.Uintptr
+	}
+	return false
+}
+
+func deref(t types.Type) types.Type {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func typeString(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pass.Pkg))
+}
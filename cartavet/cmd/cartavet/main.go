@@ -0,0 +1,15 @@
+// Command cartavet reports mistakes in destinations of carta, run it on packages as go vet is run, ie,
+//
+//	cartavet ./...
+//
+// or with go vet, ie, go vet -vettool=$(which cartavet) ./...
+package main
+
+import (
+	"github.com/jackskj/carta/cartavet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(cartavet.Analyzer)
+}
@@ -0,0 +1,15 @@
+module github.com/jackskj/carta/cartavet
+
+go 1.24.0
+
+require (
+	github.com/jackskj/carta v0.5.0
+	golang.org/x/tools v0.38.0
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	golang.org/x/mod v0.29.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	google.golang.org/protobuf v1.23.0 // indirect
+)
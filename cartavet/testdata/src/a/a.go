@@ -0,0 +1,54 @@
+package a
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/jackskj/carta"
+)
+
+type Blog struct {
+	Id    int `db:"blog_id,pk"`
+	Title string
+	Posts []Post
+}
+
+type Post struct {
+	Id    int    `db:"post_id"`
+	Title string `db:"post_title"`
+}
+
+type Duplicate struct {
+	Title string
+	Name  string `db:"title"` // want `fields Title and Name of Duplicate both match column title`
+}
+
+type Tagged struct {
+	Id   int           `db:"id,primary"` // want `field Id of Tagged is tagged with the unknown option "primary"`
+	TTL  time.Duration `db:"ttl,unit=d"` // want `field TTL of Tagged is tagged with the unknown unit "d", expected one of ns, us, ms, s, m, h`
+	name string        `db:"name"`       // want `field name of Tagged is tagged db:"name", but is unexported, carta does not map unexported fields`
+	Done chan bool     // want `field Done of Tagged is of type chan bool, which cannot be loaded from a column`
+}
+
+func destinations(ctx context.Context, rows carta.RowsSource, w io.Writer, stream carta.MessageStream, mapper *carta.Mapper) {
+	blogs := []Blog{}
+	carta.Map(rows, &blogs)
+	carta.Map(rows, blogs)                             // want `destination of carta.Map is \[\]Blog, not a pointer, ie, &blogs`
+	carta.Map(rows, nil)                               // want `destination of carta.Map is nil`
+	carta.MapContext(ctx, rows, 1)                     // want `destination of carta.MapContext is int, not a pointer`
+	carta.MapMulti(rows, []interface{}{&blogs, blogs}) // want `destination of carta.MapMulti is \[\]Blog, not a pointer, ie, &blogs`
+	carta.MapToJSON(rows, w, (*[]Blog)(nil))
+	carta.MapToJSON(rows, w, []Blog{})                                                                       // want `destination of carta.MapToJSON is \[\]Blog, not a pointer, ie, &\[\]Blog{}`
+	carta.MapToStream(rows, stream, Blog{})                                                                  // want `destination of carta.MapToStream is Blog, not a pointer, ie, &Blog{}`
+	carta.MapJoined(rows, []interface{}{carta.Prefixed("blog_", &blogs), carta.Prefixed("post_", []Post{})}) // want `destination of carta.MapJoined is \[\]Post, not a pointer, ie, &\[\]Post{}`
+	carta.MapValues(nil, nil, &[]Duplicate{})
+	carta.NewMapper(&[]Tagged{}, nil)
+	carta.NewMapper(&map[int]int{}, nil)      // want `destination of carta.NewMapper is \*map\[int\]int, elements of maps must be structs`
+	carta.MapAll[chan int](rows)              // want `carta.MapAll cannot map rows onto chan int, entities must be structs or basic types`
+	carta.CheckTable(ctx, nil, blogs, "blog") // want `destination of carta.CheckTable is \[\]Blog, not a pointer, ie, &blogs`
+	mapper.Map(rows, &blogs)
+	mapper.Map(rows, blogs)             // want `destination of carta.Mapper.Map is \[\]Blog, not a pointer, ie, &blogs`
+	mapper.MapContext(ctx, rows, blogs) // want `destination of carta.Mapper.MapContext is \[\]Blog, not a pointer, ie, &blogs`
+	mapper.Validate(nil)
+}
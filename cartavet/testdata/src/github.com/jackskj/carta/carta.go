@@ -0,0 +1,49 @@
+// Package carta is a stub of the functions of carta the analyzer checks
+package carta
+
+import (
+	"context"
+	"io"
+)
+
+type RowsSource interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+type Option func()
+
+type Querier interface{}
+
+type MessageStream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+}
+
+type Mapper struct{}
+
+func Map(rows RowsSource, dst interface{}, opts ...Option) error { return nil }
+func MapContext(ctx context.Context, rows RowsSource, dst interface{}, opts ...Option) error {
+	return nil
+}
+func MapMulti(rows RowsSource, dst []interface{}, opts ...Option) error               { return nil }
+func MapJoined(rows RowsSource, dst []interface{}, opts ...Option) error              { return nil }
+func Prefixed(prefix string, dst interface{}) interface{}                             { return nil }
+func MapToJSON(rows RowsSource, w io.Writer, shape interface{}, opts ...Option) error { return nil }
+func MapToStream(rows RowsSource, stream MessageStream, msg interface{}, opts ...Option) error {
+	return nil
+}
+func MapValues(columns []string, rows [][]interface{}, dst interface{}, opts ...Option) error {
+	return nil
+}
+func NewMapper(dst interface{}, columns []string, opts ...Option) (*Mapper, error)       { return nil, nil }
+func MapAll[T any](rows RowsSource, opts ...Option) ([]T, error)                         { return nil, nil }
+func (m *Mapper) Map(rows RowsSource, dst interface{}) error                             { return nil }
+func (m *Mapper) MapContext(ctx context.Context, rows RowsSource, dst interface{}) error { return nil }
+func (m *Mapper) Validate(columns []string) error                                        { return nil }
+func CheckTable(ctx context.Context, db Querier, dst interface{}, table string, opts ...Option) error {
+	return nil
+}
@@ -0,0 +1,157 @@
+package carta
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaReport lists fields and columns which do not match between a destination and a table, view or query of a database,
+// ie, to detect drift of the schema in CI
+type SchemaReport struct {
+	Source           string       // table, view or query which was checked
+	Type             reflect.Type // type of the destination
+	Columns          []string     // columns of the source, in order
+	UnmappedFields   []string     // paths of fields which do not match any column
+	UnclaimedColumns []string     // columns which do not match any field
+}
+
+// OK reports whether every field and column matched
+func (r *SchemaReport) OK() bool {
+	return len(r.UnmappedFields) == 0 && len(r.UnclaimedColumns) == 0
+}
+
+func (r *SchemaReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%s ← %s: ok", r.Type, r.Source)
+	}
+	problems := []string{}
+	if len(r.UnmappedFields) != 0 {
+		problems = append(problems, "unmapped fields "+strings.Join(r.UnmappedFields, ", "))
+	}
+	if len(r.UnclaimedColumns) != 0 {
+		problems = append(problems, "unclaimed columns "+strings.Join(r.UnclaimedColumns, ", "))
+	}
+	return fmt.Sprintf("%s ← %s: %s", r.Type, r.Source, strings.Join(problems, "; "))
+}
+
+// CheckTable checks dst, a pointer to a slice or to a struct as passed to Map, against the columns of a table or view,
+// which are read from information_schema.columns, the table can be qualified with its schema, ie, "public.blog",
+// otherwise it is looked up in the current schema, which is current_schema() with DollarPlaceholder, ie, of PostgreSQL,
+// DATABASE() with QuestionPlaceholder, ie, of MySQL, and SCHEMA_NAME() with AtPlaceholder, ie, of SQL Server, example:
+//
+//	report, err := carta.CheckTable(ctx, db, (*[]Blog)(nil), "blog", carta.WithPlaceholder(carta.DollarPlaceholder))
+//
+// parameters of the query of information_schema are of the style set with WithPlaceholder, columns match fields as they
+// would when mapping rows of the table with the same options, with WithOracle columns are read from all_tab_columns
+func CheckTable(ctx context.Context, db Querier, dst interface{}, table string, opts ...Option) (*SchemaReport, error) {
+	o := newOptions(opts)
+	query, args := tableColumnsQuery(table, o)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := []string{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("carta: table %s has no columns, or does not exist", table)
+	}
+	return checkColumns(dst, table, columns, o)
+}
+
+// query of information_schema returning the columns of the table, tables which are not qualified with their schema
+// are looked up in the current schema, since tables of the same name may exist in other schemas
+func tableColumnsQuery(table string, o *options) (string, []interface{}) {
+	if o.oracle {
+		return oracleTableColumnsQuery(table)
+	}
+	p := o.placeholder
+	query := "select column_name from information_schema.columns where table_name = " + p.format(1)
+	args := []interface{}{table}
+	if i := strings.LastIndex(table, "."); i != -1 {
+		query += " and table_schema = " + p.format(2)
+		args = []interface{}{table[i+1:], table[:i]}
+	} else if current := p.currentSchema(); current != "" {
+		query += " and table_schema = " + current
+	}
+	return query + " order by ordinal_position", args
+}
+
+// query of all_tab_columns of Oracle, which does not have information_schema, returning the columns of the table
+func oracleTableColumnsQuery(table string) (string, []interface{}) {
+	query := "select column_name from all_tab_columns where table_name = :1"
+	args := []interface{}{oracleIdentifier(table)}
+	if i := strings.LastIndex(table, "."); i != -1 {
+		query += " and owner = :2"
+		args = []interface{}{oracleIdentifier(table[i+1:]), oracleIdentifier(table[:i])}
+	} else {
+		query += " and owner = sys_context('USERENV', 'CURRENT_SCHEMA')"
+	}
+	return query + " order by column_id", args
+}
+
+// name of an identifier as Oracle stores it, which is upper case, unless the identifier is quoted, ie, "blog" is stored as is
+func oracleIdentifier(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return name[1 : len(name)-1]
+	}
+	return strings.ToUpper(name)
+}
+
+// function returning the current schema in databases using the style of parameters, empty if it is not known
+func (p Placeholder) currentSchema() string {
+	switch p {
+	case DollarPlaceholder:
+		return "current_schema()"
+	case QuestionPlaceholder:
+		return "DATABASE()"
+	case AtPlaceholder:
+		return "SCHEMA_NAME()"
+	}
+	return ""
+}
+
+// CheckQuery checks dst, a pointer to a slice or to a struct as passed to Map, against the columns returned by a query,
+// which is run with args in a subquery returning no rows, ie, "select * from (query) where 1 = 0", so that only its columns are read
+func CheckQuery(ctx context.Context, db Querier, dst interface{}, query string, args []interface{}, opts ...Option) (*SchemaReport, error) {
+	o := newOptions(opts)
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	rows, err := db.QueryContext(ctx, "select * from ("+query+") carta_check where 1 = 0", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return checkColumns(dst, query, columns, o)
+}
+
+func checkColumns(dst interface{}, source string, columns []string, o *options) (*SchemaReport, error) {
+	m, err := planMapper(reflect.TypeOf(dst), columns, nil, o)
+	if err != nil {
+		return nil, err
+	}
+	r := &SchemaReport{
+		Source:         source,
+		Type:           m.dstTyp,
+		Columns:        columns,
+		UnmappedFields: m.unmatchedFields,
+	}
+	for _, i := range m.unmatchedColumns {
+		r.UnclaimedColumns = append(r.UnclaimedColumns, columns[i])
+	}
+	return r, nil
+}
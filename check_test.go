@@ -0,0 +1,48 @@
+package carta_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+var errRecorded = errors.New("recorded")
+
+// records the query, and fails it
+type recordingQuerier struct {
+	query string
+	args  []interface{}
+}
+
+func (q *recordingQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.query, q.args = query, args
+	return nil, errRecorded
+}
+
+func TestCheckTableSchema(t *testing.T) {
+	tests := []struct {
+		table       string
+		placeholder carta.Placeholder
+		query       string
+		args        []interface{}
+	}{
+		{"blog", carta.DollarPlaceholder, "select column_name from information_schema.columns where table_name = $1 and table_schema = current_schema() order by ordinal_position", []interface{}{"blog"}},
+		{"blog", carta.QuestionPlaceholder, "select column_name from information_schema.columns where table_name = ? and table_schema = DATABASE() order by ordinal_position", []interface{}{"blog"}},
+		{"blog", carta.AtPlaceholder, "select column_name from information_schema.columns where table_name = @p1 and table_schema = SCHEMA_NAME() order by ordinal_position", []interface{}{"blog"}},
+		{"blog", carta.ColonPlaceholder, "select column_name from information_schema.columns where table_name = :1 order by ordinal_position", []interface{}{"blog"}},
+		{"public.blog", carta.DollarPlaceholder, "select column_name from information_schema.columns where table_name = $1 and table_schema = $2 order by ordinal_position", []interface{}{"blog", "public"}},
+	}
+	for _, test := range tests {
+		q := &recordingQuerier{}
+		if _, err := carta.CheckTable(context.Background(), q, (*[]normalized)(nil), test.table, carta.WithPlaceholder(test.placeholder)); err != errRecorded {
+			t.Fatalf("expected the error of the querier, got %v", err)
+		}
+		if q.query != test.query || !reflect.DeepEqual(q.args, test.args) {
+			t.Errorf("columns of %s are queried with %q %v, expected %q %v", test.table, q.query, q.args, test.query, test.args)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Command carta-check checks structs of a package against tables, views or queries of a live database, and reports fields
+// which would not be mapped, and columns which would not be claimed by any field, ie, to detect drift of the schema in CI, example:
+//
+//	CARTA_DSN=postgres://localhost/blog carta-check -driver postgres -pkg ./models Blog=blog Author=public.author 'Post=select * from post'
+//
+// each argument names a struct of the package and a table or view, whose columns are read from information_schema,
+// or a query, which is an argument with spaces, or a file of a query prefixed with @, ie, Post=@queries/posts.sql.
+// Columns match fields as they do with carta.Map. The command builds and runs a program importing the package and the driver,
+// so it must be run within the module of the package, and the module must require the driver. It exits with 1 if any field
+// or column does not match, and with 2 on other errors.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// import paths of drivers, by the names they are registered with
+var driverImports = map[string]string{
+	"postgres":  "github.com/lib/pq",
+	"pgx":       "github.com/jackc/pgx/v5/stdlib",
+	"mysql":     "github.com/go-sql-driver/mysql",
+	"sqlserver": "github.com/microsoft/go-mssqldb",
+}
+
+// placeholders of the query of information_schema, by the names drivers are registered with, carta.QuestionPlaceholder otherwise
+var driverPlaceholders = map[string]string{
+	"postgres":  "DollarPlaceholder",
+	"pgx":       "DollarPlaceholder",
+	"sqlserver": "AtPlaceholder",
+}
+
+var placeholders = map[string]string{
+	"question": "QuestionPlaceholder",
+	"dollar":   "DollarPlaceholder",
+	"at":       "AtPlaceholder",
+	"colon":    "ColonPlaceholder",
+}
+
+var (
+	driver       = flag.String("driver", "", "name of the database/sql driver, ie, postgres, pgx, mysql or sqlserver")
+	driverImport = flag.String("driver-import", "", "import path of the driver, known for the drivers listed in -driver")
+	dsn          = flag.String("dsn", "", "data source name of the database, $CARTA_DSN if empty")
+	pkgDir       = flag.String("pkg", ".", "directory of the package of the structs")
+	tagKey       = flag.String("tag", "db", "key of the tags naming the columns")
+	placeholder  = flag.String("placeholder", "", "style of the parameters of the driver, question, dollar, at or colon, known for the drivers listed in -driver")
+)
+
+// check of a struct against a table, view or query
+type check struct {
+	typ    string
+	source string
+	query  bool
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: carta-check -driver name [flags] Type=table|'Type=query'|Type=@file ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	code, err := run(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "carta-check: %s\n", err)
+	}
+	os.Exit(code)
+}
+
+func run(args []string) (int, error) {
+	if *driver == "" {
+		return 2, errors.New("-driver is required")
+	}
+	if len(args) == 0 {
+		return 2, errors.New("no structs to check, ie, Blog=blog")
+	}
+	importPath := *driverImport
+	if importPath == "" {
+		if importPath = driverImports[*driver]; importPath == "" {
+			return 2, fmt.Errorf("import path of the driver %s is not known, set -driver-import", *driver)
+		}
+	}
+	ph := driverPlaceholders[*driver]
+	if ph == "" {
+		ph = "QuestionPlaceholder"
+	}
+	if *placeholder != "" {
+		if ph = placeholders[*placeholder]; ph == "" {
+			return 2, fmt.Errorf("unknown placeholder %s, expected question, dollar, at or colon", *placeholder)
+		}
+	}
+	source := *dsn
+	if source == "" {
+		source = os.Getenv("CARTA_DSN")
+	}
+	checks, err := parseChecks(args)
+	if err != nil {
+		return 2, err
+	}
+	pkgPath, pkgName, err := goList(*pkgDir)
+	if err != nil {
+		return 2, err
+	}
+	if pkgName == "main" {
+		return 2, fmt.Errorf("%s is a main package, which cannot be imported", *pkgDir)
+	}
+
+	dir, err := os.MkdirTemp("", "carta_check_")
+	if err != nil {
+		return 2, err
+	}
+	defer os.RemoveAll(dir)
+	program := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(program, generate(pkgPath, importPath, ph, checks), 0644); err != nil {
+		return 2, err
+	}
+	// the program is built from the directory of the package, so that it is built with the module of the package
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, "carta_check"), program)
+	build.Dir = *pkgDir
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		return 2, fmt.Errorf("building the program of the checks: %s", err)
+	}
+	cmd := exec.Command(filepath.Join(dir, "carta_check"))
+	cmd.Env = append(os.Environ(), "CARTA_CHECK_DSN="+source)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			// the program exits with 1 on drift, and with 2 on errors
+			return exit.ExitCode(), nil
+		}
+		return 2, err
+	}
+	return 0, nil
+}
+
+// parses arguments of the form Type=table, Type=query or Type=@file
+func parseChecks(args []string) ([]check, error) {
+	checks := make([]check, 0, len(args))
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i <= 0 || i == len(arg)-1 {
+			return nil, fmt.Errorf("invalid argument %q, expected Type=table, Type=query or Type=@file", arg)
+		}
+		c := check{typ: arg[:i], source: strings.TrimSpace(arg[i+1:])}
+		if strings.HasPrefix(c.source, "@") {
+			query, err := os.ReadFile(c.source[1:])
+			if err != nil {
+				return nil, err
+			}
+			c.source, c.query = string(query), true
+		} else {
+			c.query = strings.ContainsAny(c.source, " \t\n")
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// import path and name of the package in dir
+func goList(dir string) (string, string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}} {{.Name}}", ".")
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("go list %s: %s", dir, strings.TrimSpace(stderr.String()))
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("go list %s: unexpected output %q", dir, out)
+	}
+	return fields[0], fields[1], nil
+}
+
+// generates the program running the checks, which prints a report per check
+func generate(pkgPath, driverPath, placeholder string, checks []check) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `// Code generated by carta-check. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/jackskj/carta"
+	pkg %s
+	_ %s
+)
+
+func main() {
+	db, err := sql.Open(%s, os.Getenv("CARTA_CHECK_DSN"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	opts := []carta.Option{carta.WithPlaceholder(carta.%s), carta.WithTagKey(%s)}
+	drift := false
+	report := func(r *carta.SchemaReport, err error) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Println(r)
+		drift = drift || !r.OK()
+	}
+`, strconv.Quote(pkgPath), strconv.Quote(driverPath), strconv.Quote(*driver), placeholder, strconv.Quote(*tagKey))
+	for _, c := range checks {
+		dst := fmt.Sprintf("(*[]pkg.%s)(nil)", c.typ)
+		if c.query {
+			fmt.Fprintf(&buf, "\treport(carta.CheckQuery(ctx, db, %s, %s, nil, opts...))\n", dst, strconv.Quote(c.source))
+		} else {
+			fmt.Fprintf(&buf, "\treport(carta.CheckTable(ctx, db, %s, %s, opts...))\n", dst, strconv.Quote(c.source))
+		}
+	}
+	buf.WriteString("\tif drift {\n\t\tos.Exit(1)\n\t}\n}\n")
+	return buf.Bytes()
+}
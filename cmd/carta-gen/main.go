@@ -0,0 +1,445 @@
+// Command carta-gen generates functions mapping rows onto structs without reflection, for structs annotated with
+// the carta:generate directive, example:
+//
+//	//go:generate carta-gen
+//
+//	//carta:generate
+//	type Blog struct {
+//		Id    int `db:"blog_id,pk"`
+//		Title string
+//		Posts []Post
+//	}
+//
+// generates carta_gen.go, with the function
+//
+//	func MapBlog(rows carta.RowsSource, opts ...carta.Option) ([]Blog, error)
+//
+// the name of the function can be set after the directive, ie, //carta:generate LoadBlogs. Columns match fields as they do
+// with carta.Map, and rows are mapped with carta.Map when options are passed. Structs with fields of types carta-gen does not load,
+// such as types of other packages except time.Time, types with Scan or Unmarshal methods, or tags with the prefix,
+// null, unit or lenient options, are mapped with carta.Map. Tags with unknown options, and fields of a struct matching
+// the same column, fail the generation.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackskj/carta/internal/gen"
+	"github.com/jackskj/carta/internal/tags"
+)
+
+const directive = "//carta:generate"
+
+var (
+	dir      = flag.String("dir", ".", "directory of the package")
+	output   = flag.String("output", "carta_gen.go", "name of the generated file, in the directory of the package")
+	tagKey   = flag.String("tag", "db", "key of the tags naming the columns")
+	pointers = flag.Bool("pointers", false, "generated functions return slices of pointers, ie, []*Blog")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "carta-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	file := filepath.Join(*dir, *output)
+	src, err := generatePackage(*dir)
+	if src == nil {
+		return err
+	}
+	// the unformatted source is written with the error of formatting it, so that the generated code can be debugged
+	if werr := os.WriteFile(file, src, 0644); werr != nil {
+		return werr
+	}
+	if err != nil {
+		return fmt.Errorf("formatting %s: %s", file, err)
+	}
+	return nil
+}
+
+// generates the file of the annotated structs of the package in dir, the source is nil on errors other than formatting it
+func generatePackage(dir string) ([]byte, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	var funcs []*gen.Func
+	for _, name := range pkg.names {
+		spec := pkg.types[name]
+		fnName, ok := pkg.directives[name]
+		if !ok {
+			continue
+		}
+		if _, ok := spec.typ.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("%s: %s is not a struct", pkg.fset.Position(spec.typ.Pos()), name)
+		}
+		if fnName == "" {
+			fnName = "Map" + name
+		}
+		fn := &gen.Func{Name: fnName, Type: gen.Ident{Name: name}, Ptr: *pointers}
+		entity, err := pkg.entity(name, map[string]bool{})
+		var unsupported *unsupportedError
+		switch {
+		case errors.As(err, &unsupported):
+			fn.Fallback = true
+			fn.Doc = fmt.Sprintf("%s maps rows onto %s with carta.Map, since %s", fnName, name, unsupported.reason)
+		case err != nil:
+			return nil, err
+		default:
+			fn.Entity = entity
+			fn.Doc = fmt.Sprintf("%s maps rows onto %s without reflection, columns match fields as they do with carta.Map,\n"+
+				"rows are mapped with carta.Map when options are passed", fnName, name)
+		}
+		funcs = append(funcs, fn)
+	}
+	if len(funcs) == 0 {
+		return nil, fmt.Errorf("no structs in %s are annotated with %s", dir, directive)
+	}
+	return generate(pkg.name, funcs)
+}
+
+// generates the file of the functions, with the imports they reference,
+// the unformatted source is returned with the error if it cannot be formatted, which is a bug of the generator
+func generate(pkgName string, funcs []*gen.Func) ([]byte, error) {
+	imports := map[string]string{}
+	qualify := func(id gen.Ident) string {
+		if id.ImportPath == "" {
+			return id.Name
+		}
+		name := path.Base(id.ImportPath)
+		imports[id.ImportPath] = name
+		return name + "." + id.Name
+	}
+	var body bytes.Buffer
+	for _, fn := range funcs {
+		body.Write(gen.Generate(fn, qualify))
+		body.WriteString("\n")
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by carta-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	// imports of the standard library are grouped first
+	var std, paths []string
+	for p := range imports {
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			paths = append(paths, p)
+		} else {
+			std = append(std, p)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(paths)
+	for _, p := range std {
+		fmt.Fprintf(&buf, "%q\n", p)
+	}
+	if len(std) != 0 && len(paths) != 0 {
+		buf.WriteString("\n")
+	}
+	for _, p := range paths {
+		fmt.Fprintf(&buf, "%q\n", p)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(body.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.Bytes(), err
+	}
+	return src, nil
+}
+
+// returned for structs which generated code does not load, which are then mapped with carta.Map
+type unsupportedError struct {
+	reason string
+}
+
+func (e *unsupportedError) Error() string {
+	return e.reason
+}
+
+func unsupported(format string, a ...interface{}) error {
+	return &unsupportedError{fmt.Sprintf(format, a...)}
+}
+
+// declaration of a type, with the imports of its file
+type typeSpec struct {
+	typ     *ast.TypeSpec
+	imports map[string]string // import paths by name
+}
+
+type pkg struct {
+	fset       *token.FileSet
+	name       string
+	names      []string             // names of the types, in order of declaration
+	types      map[string]*typeSpec // types declared in the package
+	methods    map[string][]string  // names of methods of types
+	directives map[string]string    // names of functions of annotated types, empty for the default name
+}
+
+// parses the go files of the directory, except tests and the generated file
+func loadPackage(dir string) (*pkg, error) {
+	p := &pkg{
+		fset:       token.NewFileSet(),
+		types:      map[string]*typeSpec{},
+		methods:    map[string][]string{},
+		directives: map[string]string{},
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == *output {
+			continue
+		}
+		f, err := parser.ParseFile(p.fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if p.name == "" {
+			p.name = f.Name.Name
+		} else if p.name != f.Name.Name {
+			continue
+		}
+		imports := map[string]string{}
+		for _, imp := range f.Imports {
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+			name := path.Base(importPath)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			imports[name] = importPath
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					p.names = append(p.names, ts.Name.Name)
+					p.types[ts.Name.Name] = &typeSpec{typ: ts, imports: imports}
+					doc := ts.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					if name, ok := findDirective(doc); ok {
+						p.directives[ts.Name.Name] = name
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) == 1 {
+					recv := decl.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						p.methods[id.Name] = append(p.methods[id.Name], decl.Name.Name)
+					}
+				}
+			}
+		}
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("no go files in %s", dir)
+	}
+	return p, nil
+}
+
+// finds the directive in the doc comment, and the name of the function following it
+func findDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if c.Text == directive || strings.HasPrefix(c.Text, directive+" ") {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, directive)), true
+		}
+	}
+	return "", false
+}
+
+// methods which carta.Map calls, or uses to load fields, which generated code does not
+var runtimeMethods = []string{"Init", "AfterMap", "Scan", "UnmarshalText", "UnmarshalBinary"}
+
+func (p *pkg) runtimeMethod(name string) string {
+	for _, m := range p.methods[name] {
+		for _, rm := range runtimeMethods {
+			if m == rm {
+				return m
+			}
+		}
+	}
+	return ""
+}
+
+// describes the struct as an entity, parents are the structs enclosing it
+func (p *pkg) entity(name string, parents map[string]bool) (*gen.Entity, error) {
+	if parents[name] {
+		return nil, unsupported("%s is recursive", name)
+	}
+	parents[name] = true
+	defer delete(parents, name)
+	if m := p.runtimeMethod(name); m != "" {
+		return nil, unsupported("%s has the method %s", name, m)
+	}
+	spec := p.types[name]
+	e := &gen.Entity{Type: gen.Ident{Name: name}}
+	columns := map[string]string{} // fields by the columns they match
+	for _, field := range spec.typ.Type.(*ast.StructType).Fields.List {
+		if len(field.Names) == 0 {
+			return nil, unsupported("%s embeds %s", name, exprString(field.Type))
+		}
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			s, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+		column, opts, err := p.parseTag(tag, field)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range field.Names {
+			if !id.IsExported() || column == "-" {
+				continue
+			}
+			fieldColumn := column
+			if fieldColumn == "" {
+				fieldColumn = id.Name
+			}
+			for opt := range opts {
+				if opt != "pk" {
+					return nil, unsupported("field %s of %s is tagged with the option %s", id.Name, name, opt)
+				}
+			}
+			f, nested, err := p.field(spec, id.Name, fieldColumn, field.Type, parents)
+			if err != nil {
+				return nil, err
+			}
+			if nested != nil {
+				e.Nested = append(e.Nested, nested)
+				continue
+			}
+			_, f.Key = opts["pk"]
+			for _, c := range gen.Candidates(fieldColumn, nil) {
+				if other, ok := columns[c]; ok && other != id.Name {
+					return nil, fmt.Errorf("%s: fields %s and %s of %s both match column %s", p.fset.Position(id.Pos()), other, id.Name, name, c)
+				}
+				columns[c] = id.Name
+			}
+			e.Fields = append(e.Fields, f)
+		}
+	}
+	return e, nil
+}
+
+func (p *pkg) parseTag(tag reflect.StructTag, field *ast.Field) (string, map[string]string, error) {
+	column, opts := tags.Parse(tag.Get(*tagKey))
+	for name := range opts {
+		if !tags.Options[name] {
+			return "", nil, fmt.Errorf("%s: unknown option %q of tag %s", p.fset.Position(field.Pos()), name, *tagKey)
+		}
+	}
+	return column, opts, nil
+}
+
+// describes the field of the type expression as either a basic field, or a nested struct
+func (p *pkg) field(spec *typeSpec, name, column string, expr ast.Expr, parents map[string]bool) (*gen.Field, *gen.Nested, error) {
+	f := &gen.Field{Name: name, Column: column}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		f.Ptr = true
+		expr = star.X
+	}
+	if arr, ok := expr.(*ast.ArrayType); ok && arr.Len == nil && !f.Ptr {
+		if id, ok := arr.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			f.Kind = gen.Bytes
+			return f, nil, nil
+		}
+		elem, ptr := arr.Elt, false
+		if star, ok := elem.(*ast.StarExpr); ok {
+			elem, ptr = star.X, true
+		}
+		if id, ok := elem.(*ast.Ident); ok && p.isStruct(id.Name) {
+			entity, err := p.entity(id.Name, parents)
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, &gen.Nested{Name: name, Column: column, Collection: true, Ptr: ptr, Entity: entity}, nil
+		}
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if kind, ok := basicKinds[expr.Name]; ok {
+			f.Kind = kind
+			return f, nil, nil
+		}
+		if p.isStruct(expr.Name) {
+			entity, err := p.entity(expr.Name, parents)
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, &gen.Nested{Name: name, Column: column, Ptr: f.Ptr, Entity: entity}, nil
+		}
+		// named basic types, ie, type Status int32
+		if named, ok := p.types[expr.Name]; ok && p.runtimeMethod(expr.Name) == "" {
+			if underlying, ok := named.typ.Type.(*ast.Ident); ok && named.typ.Assign == 0 {
+				if kind, ok := basicKinds[underlying.Name]; ok {
+					f.Kind = kind
+					f.Type = gen.Ident{Name: expr.Name}
+					return f, nil, nil
+				}
+			}
+		}
+	case *ast.SelectorExpr:
+		if pkgName, ok := expr.X.(*ast.Ident); ok && spec.imports[pkgName.Name] == "time" && expr.Sel.Name == "Time" {
+			f.Kind = gen.Time
+			return f, nil, nil
+		}
+	}
+	return nil, nil, unsupported("field %s of %s is of type %s", name, spec.typ.Name.Name, exprString(expr))
+}
+
+func (p *pkg) isStruct(name string) bool {
+	spec, ok := p.types[name]
+	if !ok {
+		return false
+	}
+	_, ok = spec.typ.Type.(*ast.StructType)
+	return ok
+}
+
+var basicKinds = map[string]gen.Kind{
+	"bool":    gen.Bool,
+	"int":     gen.Int,
+	"int32":   gen.Int32,
+	"int64":   gen.Int64,
+	"uint32":  gen.Uint32,
+	"uint64":  gen.Uint64,
+	"float32": gen.Float32,
+	"float64": gen.Float64,
+	"string":  gen.String,
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackskj/carta/internal/gen"
+)
+
+// the mappers generated for internal/gen/gentest are compared there with carta.Map, and are the golden file of the generator
+func TestGolden(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gen", "gentest")
+	src, err := generatePackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join(dir, *output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, golden) {
+		t.Fatalf("generated code differs from %s, regenerate it with go generate ./internal/gen/gentest", filepath.Join(dir, *output))
+	}
+}
+
+func TestGenerateUnformatted(t *testing.T) {
+	fn := &gen.Func{Name: "Map Blog", Doc: "invalid name", Type: gen.Ident{Name: "Blog"}, Fallback: true}
+	src, err := generate("models", []*gen.Func{fn})
+	if err == nil {
+		t.Fatal("expected an error formatting the invalid name")
+	}
+	if !bytes.Contains(src, []byte("func Map Blog(")) {
+		t.Fatalf("expected the unformatted source, got %s", src)
+	}
+}
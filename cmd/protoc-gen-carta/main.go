@@ -0,0 +1,170 @@
+// Command protoc-gen-carta is a plugin of protoc, which generates functions mapping rows onto messages without reflection,
+// next to the messages generated by protoc-gen-go, example:
+//
+//	protoc --go_out=. --carta_out=. blog.proto
+//
+// generates blog.carta.go, with a function for each message, ie,
+//
+//	func MapBlog(rows carta.RowsSource, opts ...carta.Option) ([]*Blog, error)
+//
+// columns match fields as they do with carta.Map, fields of nested messages are associations, and repeated messages are collections,
+// and rows are mapped with carta.Map when options are passed. Messages with oneofs, maps, repeated scalars,
+// well known types other than google.protobuf.Timestamp, or recursive fields are mapped with carta.Map.
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/jackskj/carta/internal/gen"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// error of messages which carta.Map maps in place of generated code
+var errUnsupported = errors.New("unsupported field")
+
+func main() {
+	var flags flag.FlagSet
+	prefix := flags.String("prefix", "Map", "prefix of the names of generated functions")
+	protogen.Options{ParamFunc: flags.Set}.Run(func(plugin *protogen.Plugin) error {
+		for _, f := range plugin.Files {
+			if f.Generate {
+				generateFile(plugin, f, *prefix)
+			}
+		}
+		return nil
+	})
+}
+
+func generateFile(plugin *protogen.Plugin, f *protogen.File, prefix string) {
+	messages := allMessages(f.Messages)
+	if len(messages) == 0 {
+		return
+	}
+	g := plugin.NewGeneratedFile(f.GeneratedFilenamePrefix+".carta.go", f.GoImportPath)
+	g.P("// Code generated by protoc-gen-carta. DO NOT EDIT.")
+	g.P("// source: ", f.Desc.Path())
+	g.P()
+	g.P("package ", f.GoPackageName)
+	g.P()
+	qualify := func(id gen.Ident) string {
+		if id.ImportPath == "" {
+			return id.Name
+		}
+		return g.QualifiedGoIdent(protogen.GoIdent{GoName: id.Name, GoImportPath: protogen.GoImportPath(id.ImportPath)})
+	}
+	for _, m := range messages {
+		name := prefix + m.GoIdent.GoName
+		fn := &gen.Func{
+			Name: name,
+			Type: ident(m.GoIdent),
+			Ptr:  true,
+		}
+		entity, err := newEntity(m, map[protoreflect.FullName]bool{})
+		if err != nil {
+			fn.Fallback = true
+			fn.Doc = name + " maps rows onto messages of " + m.GoIdent.GoName + " with carta.Map, since the message has fields\n" +
+				"which generated mappers do not load, ie, oneofs, maps or repeated scalars"
+		} else {
+			fn.Entity = entity
+			fn.Doc = name + " maps rows onto messages of " + m.GoIdent.GoName + " without reflection, columns match fields as they do with carta.Map,\n" +
+				"rows are mapped with carta.Map when options are passed"
+		}
+		g.Write(gen.Generate(fn, qualify))
+		g.P()
+	}
+}
+
+// messages of the file, including nested messages, except for map entries
+func allMessages(messages []*protogen.Message) []*protogen.Message {
+	var all []*protogen.Message
+	for _, m := range messages {
+		if m.Desc.IsMapEntry() {
+			continue
+		}
+		all = append(all, m)
+		all = append(all, allMessages(m.Messages)...)
+	}
+	return all
+}
+
+func ident(id protogen.GoIdent) gen.Ident {
+	return gen.Ident{ImportPath: string(id.GoImportPath), Name: id.GoName}
+}
+
+// describes the message as an entity, parents are the messages enclosing it, returns errUnsupported for fields
+// which are not loaded by generated code
+func newEntity(m *protogen.Message, parents map[protoreflect.FullName]bool) (*gen.Entity, error) {
+	if parents[m.Desc.FullName()] {
+		return nil, errUnsupported
+	}
+	parents[m.Desc.FullName()] = true
+	defer delete(parents, m.Desc.FullName())
+	e := &gen.Entity{Type: ident(m.GoIdent)}
+	for _, field := range m.Fields {
+		fd := field.Desc
+		if fd.IsMap() || fd.ContainingOneof() != nil && !fd.ContainingOneof().IsSynthetic() {
+			return nil, errUnsupported
+		}
+		if fd.Kind() == protoreflect.MessageKind {
+			if fd.Message().FullName() == "google.protobuf.Timestamp" && !fd.IsList() {
+				e.Fields = append(e.Fields, &gen.Field{Name: field.GoName, Column: field.GoName, Kind: gen.Timestamp, Type: ident(field.Message.GoIdent)})
+				continue
+			}
+			if fd.Message().ParentFile().Package() == "google.protobuf" {
+				return nil, errUnsupported
+			}
+			nested, err := newEntity(field.Message, parents)
+			if err != nil {
+				return nil, err
+			}
+			e.Nested = append(e.Nested, &gen.Nested{
+				Name:       field.GoName,
+				Column:     field.GoName,
+				Collection: fd.IsList(),
+				Ptr:        true,
+				Entity:     nested,
+			})
+			continue
+		}
+		if fd.IsList() {
+			return nil, errUnsupported
+		}
+		f := &gen.Field{
+			Name:   field.GoName,
+			Column: field.GoName,
+			// proto3 optional, and proto2 scalars, are pointers
+			Ptr: fd.HasPresence(),
+		}
+		switch fd.Kind() {
+		case protoreflect.BoolKind:
+			f.Kind = gen.Bool
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+			f.Kind = gen.Int32
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			f.Kind = gen.Int64
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+			f.Kind = gen.Uint32
+		case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			f.Kind = gen.Uint64
+		case protoreflect.FloatKind:
+			f.Kind = gen.Float32
+		case protoreflect.DoubleKind:
+			f.Kind = gen.Float64
+		case protoreflect.StringKind:
+			f.Kind = gen.String
+		case protoreflect.BytesKind:
+			// bytes fields are not pointers, even with presence
+			f.Kind, f.Ptr = gen.Bytes, false
+		case protoreflect.EnumKind:
+			f.Kind = gen.Enum
+			f.Type = ident(field.Enum.GoIdent)
+			f.Values = gen.Ident{ImportPath: string(field.Enum.GoIdent.GoImportPath), Name: field.Enum.GoIdent.GoName + "_value"}
+		default:
+			return nil, errUnsupported
+		}
+		e.Fields = append(e.Fields, f)
+	}
+	return e, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update the golden file")
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func repeated(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+func oneof(f *descriptorpb.FieldDescriptorProto, index int32) *descriptorpb.FieldDescriptorProto {
+	f.OneofIndex = proto.Int32(index)
+	return f
+}
+
+// request of blog.proto, with a message of every kind of field generated mappers load, and a message with a oneof,
+// which is mapped with carta.Map
+func request() *pluginpb.CodeGeneratorRequest {
+	blog := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("blog.proto"),
+		Package:    proto.String("blog"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/blog;blog")},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("DRAFT"), Number: proto.Int32(0)},
+				{Name: proto.String("PUBLISHED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Blog"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("title", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("views", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					field("rating", 4, descriptorpb.FieldDescriptorProto_TYPE_FLOAT, ""),
+					field("status", 5, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".blog.Status"),
+					field("created", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+					field("author", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".blog.Author"),
+					repeated(field("posts", 8, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".blog.Post")),
+				},
+			},
+			{
+				Name: proto.String("Author"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("author_id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+			},
+			{
+				Name: proto.String("Post"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("post_id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("body", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				},
+			},
+			{
+				Name: proto.String("Event"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					oneof(field("blog_id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""), 0),
+					oneof(field("post_id", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""), 0),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("target")}},
+			},
+		},
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"blog.proto"},
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto), blog},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	plugin, err := protogen.Options{}.New(request())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range plugin.Files {
+		if f.Generate {
+			generateFile(plugin, f, "Map")
+		}
+	}
+	resp := plugin.Response()
+	if resp.Error != nil {
+		t.Fatal(resp.GetError())
+	}
+	if len(resp.File) != 1 || resp.File[0].GetName() != "example.com/blog/blog.carta.go" {
+		t.Fatalf("expected blog.carta.go, got %v", resp.File)
+	}
+	content := []byte(resp.File[0].GetContent())
+	goldenFile := filepath.Join("testdata", "blog.carta.go.golden")
+	if *update {
+		if err := os.WriteFile(goldenFile, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, golden) {
+		t.Fatalf("generated code differs from %s, update it with go test -update", goldenFile)
+	}
+}
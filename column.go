@@ -1,47 +1,73 @@
 package carta
 
 import (
-	"database/sql"
+	"fmt"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/jackskj/carta/internal/names"
 )
 
 // column represents the ith struct field of this mapper where the column is to be mapped
 type column struct {
-	typ         *sql.ColumnType
+	typ         string // database type name, empty if not known
 	name        string
 	columnIndex int
 	i           fieldIndex
+	conv        converter // user registered conversion, nil for types natively supported by carta
+
+	unmarshalText   bool // destination implements encoding.TextUnmarshaler
+	unmarshalBinary bool // destination implements encoding.BinaryUnmarshaler
 }
 
-func allocateColumns(m *Mapper, columns map[string]column) error {
+func (c column) databaseTypeName() string {
+	return c.typ
+}
+
+// conversion used to load the column, binary unmarshalers also use UnmarshalText for text columns if implemented
+func (c column) conversion() Conversion {
+	switch {
+	case c.conv != nil:
+		return Converted
+	case c.unmarshalBinary:
+		return BinaryUnmarshaled
+	case c.unmarshalText:
+		return TextUnmarshaled
+	}
+	return Native
+}
+
+func allocateColumns(m *Mapper, columns map[string]column, o *options) error {
 	var (
 		candidates map[string]bool
 	)
+	if m.IsBasic && m.dstTyp != nil {
+		return allocateBasicColumn(m, columns, o)
+	}
 	presentColumns := map[string]column{}
 	for cName, c := range columns {
 		if m.IsBasic {
-			candidates = getColumnNameCandidates("", m.AncestorNames)
-			if _, ok := candidates[cName]; ok {
-				presentColumns[cName] = column{
-					typ:         c.typ,
-					name:        cName,
-					columnIndex: c.columnIndex,
-				}
+			candidates = getColumnNameCandidates("", m.AncestorNames, o)
+			if _, ok := candidates[o.normalizeName(cName)]; o.matchAlias(c.name, m.path, ok) {
+				presentColumns[cName] = claimColumn(c, 0, m.Typ)
 				delete(columns, cName) // dealocate claimed column
 			}
 		} else {
 			for i, field := range m.Fields {
-				candidates = getColumnNameCandidates(field.Name, m.AncestorNames)
+				if o.tagsOnly && !field.tagged && o.aliases[c.name] == "" {
+					continue
+				}
+				if o.resolveNames != nil {
+					candidates = resolveColumnNameCandidates(m.Typ.Field(int(i)), m.AncestorNames, o)
+				} else {
+					candidates = getColumnNameCandidates(field.Name, m.AncestorNames, o)
+				}
 				// can only allocate columns to basic fields
 				if isBasicType(field.Typ) {
-					if _, ok := candidates[cName]; ok {
-						presentColumns[cName] = column{
-							typ:         c.typ,
-							name:        cName,
-							columnIndex: c.columnIndex,
-							i:           i,
-						}
+					if _, ok := candidates[o.normalizeName(cName)]; o.matchAlias(c.name, joinPath(m.path, m.Typ.Field(int(i)).Name), ok) {
+						presentColumns[cName] = claimColumn(c, i, field.Typ)
 						delete(columns, cName) // dealocate claimed column
 					}
 				}
@@ -49,52 +75,175 @@ func allocateColumns(m *Mapper, columns map[string]column) error {
 		}
 	}
 	m.PresentColumns = presentColumns
+	sortColumns(m)
 
-	columnIds := []int{}
+	ancestorNames := []string{}
+	if len(m.AncestorNames) != 0 {
+		ancestorNames = m.AncestorNames
+	}
+
+	for i, subMap := range m.SubMaps {
+		subMap.path = joinPath(m.path, m.Typ.Field(int(i)).Name)
+		if prefix, ok := m.Fields[i].opts["prefix"]; ok {
+			if err := allocatePrefixedColumns(subMap, prefix, columns, o); err != nil {
+				return err
+			}
+			continue
+		}
+		subMap.AncestorNames = append(ancestorNames, m.Fields[i].Name)
+		if err := allocateColumns(subMap, columns, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destinations of basic types, ie, *[]int64, claim the only column, or the column set with WithColumn
+func allocateBasicColumn(m *Mapper, columns map[string]column, o *options) error {
+	name := o.column
+	if name == "" {
+		if len(columns) != 1 {
+			return fmt.Errorf("carta: cannot map %d columns onto %s, set the column with WithColumn", len(columns), m.dstTyp)
+		}
+		for cName := range columns {
+			name = cName
+		}
+	}
+	c, ok := columns[name]
+	if !ok {
+		return errorf(ErrNoColumnsMatched, "carta: cannot map rows onto %s, column %s is missing", m.dstTyp, name)
+	}
+	m.PresentColumns = map[string]column{name: claimColumn(c, 0, m.Typ)}
+	delete(columns, name) // dealocate claimed column
+	sortColumns(m)
+	return nil
+}
+
+// with the ordinal option, columns map onto basic fields by position, in the order the fields are declared,
+// fields of nested structs are visited in place of the nested struct, ie, columns "id, name, id, title" map onto
+//
+//	type Blog struct {
+//		Id    int
+//		Name  string
+//		Posts []Post // Post{Id, Title}
+//	}
+//
+// columns are allocated starting from the next column, returns the next column which was not allocated
+func allocateOrdinalColumns(m *Mapper, columns []column, next int) int {
+	m.PresentColumns = map[string]column{}
+	if m.IsBasic {
+		if next < len(columns) {
+			m.PresentColumns[strconv.Itoa(next)] = claimColumn(columns[next], 0, m.Typ)
+			next++
+		}
+	} else {
+		for i := 0; i < m.Typ.NumField(); i++ {
+			field, ok := m.Fields[fieldIndex(i)]
+			if !ok {
+				// unexported or ignored
+				continue
+			}
+			if subMap, ok := m.SubMaps[fieldIndex(i)]; ok {
+				subMap.path = joinPath(m.path, m.Typ.Field(i).Name)
+				next = allocateOrdinalColumns(subMap, columns, next)
+			} else if isBasicType(field.Typ) && next < len(columns) {
+				// keyed by position, since names may be empty or repeated
+				m.PresentColumns[strconv.Itoa(next)] = claimColumn(columns[next], fieldIndex(i), field.Typ)
+				next++
+			}
+		}
+	}
+	sortColumns(m)
+	return next
+}
+
+// claims a column for the ith field of type t, or for a basic mapper of type t
+func claimColumn(c column, i fieldIndex, t reflect.Type) column {
+	return column{
+		typ:         c.typ,
+		name:        c.name,
+		columnIndex: c.columnIndex,
+		i:           i,
+		conv:        findConverter(t, c.databaseTypeName()),
+
+		unmarshalText:   isTextUnmarshaler(t),
+		unmarshalBinary: isBinaryUnmarshaler(t),
+	}
+}
+
+// sorts present columns, which determine uniqueness of elements,
+// if any of the fields is tagged with the pk option, ie, `db:"id,pk"`, uniqueness is determined only by the key columns
+func sortColumns(m *Mapper) {
+	columnIds, keyIds := []int{}, []int{}
 	for _, column := range m.PresentColumns {
 		if _, ok := m.SubMaps[column.i]; ok {
 			continue
 		}
 		columnIds = append(columnIds, column.columnIndex)
+		if !m.IsBasic && m.Fields[column.i].opts.has("pk") {
+			keyIds = append(keyIds, column.columnIndex)
+		}
+	}
+	if len(keyIds) != 0 {
+		columnIds = keyIds
 	}
 	sort.Ints(columnIds)
 	m.SortedColumnIndexes = columnIds
+}
 
-	ancestorNames := []string{}
-	if len(m.AncestorNames) != 0 {
-		ancestorNames = m.AncestorNames
+// sub maps of fields with the prefix option, ie, `db:"address,prefix=addr_"`, only claim columns starting with the prefix,
+// the prefix is stripped before matching their fields, which are then matched as if the sub map had no ancestors
+func allocatePrefixedColumns(m *Mapper, prefix string, columns map[string]column, o *options) error {
+	prefixed := map[string]column{}
+	for cName, c := range columns {
+		if strings.HasPrefix(cName, prefix) {
+			prefixed[strings.TrimPrefix(cName, prefix)] = c
+		}
 	}
-
-	for i, subMap := range m.SubMaps {
-		subMap.AncestorNames = append(ancestorNames, m.Fields[i].Name)
-		if err := allocateColumns(subMap, columns); err != nil {
-			return err
+	m.AncestorNames = nil
+	if err := allocateColumns(m, prefixed, o); err != nil {
+		return err
+	}
+	for cName := range columns {
+		if _, ok := prefixed[strings.TrimPrefix(cName, prefix)]; strings.HasPrefix(cName, prefix) && !ok {
+			delete(columns, cName) // dealocate column claimed by the sub map
 		}
 	}
 	return nil
 }
 
-func getColumnNameCandidates(fieldName string, ancestorNames []string) map[string]bool {
+// with the auto prefix option, fields of nested structs only match columns prefixed with the names of their ancestors,
+// separated by either "_" or ".", ie, "address_city" or "address.city"
+// names are matched as they are, in lower case, or as derived by the naming strategy, unless the exact case option is set,
+// candidates are normalized with the normalizer set with WithNameNormalizer
+func getColumnNameCandidates(fieldName string, ancestorNames []string, o *options) map[string]bool {
 	// empty field name means that the mapper is basic, since there is no struct assiciated with this slice, there is no field name
+	rules := names.Rules{
+		Naming:     o.naming.ColumnName,
+		Normalize:  o.normalize,
+		ExactCase:  o.exactCase,
+		AutoPrefix: o.autoPrefix,
+	}
 	candidates := map[string]bool{}
-	if fieldName != "" {
-		candidates[fieldName] = true
-		candidates[toSnakeCase(fieldName)] = true
-		candidates[strings.ToLower(fieldName)] = true
-	}
-	if len(ancestorNames) == 0 {
-		return candidates
-	}
-	nameConcat := fieldName
-	for i := len(ancestorNames) - 1; i >= 0; i-- {
-		if nameConcat == "" {
-			nameConcat = ancestorNames[i]
-		} else {
-			nameConcat = ancestorNames[i] + "_" + nameConcat
-		}
-		candidates[nameConcat] = true
-		candidates[strings.ToLower(nameConcat)] = true
-		candidates[toSnakeCase(nameConcat)] = true
+	for _, name := range rules.Candidates(fieldName, ancestorNames) {
+		candidates[name] = true
+	}
+	return candidates
+}
+
+// joins paths of fields in go, ie, "Author" and "Name" to "Author.Name"
+func joinPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// candidates returned by the resolver set with WithFieldNameResolver, which replace the default candidates
+func resolveColumnNameCandidates(field reflect.StructField, ancestorNames []string, o *options) map[string]bool {
+	candidates := map[string]bool{}
+	for _, name := range o.resolveNames(field, strings.Join(ancestorNames, ".")) {
+		candidates[o.normalizeName(name)] = true
 	}
 	return candidates
 }
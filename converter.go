@@ -0,0 +1,245 @@
+package carta
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackskj/carta/value"
+)
+
+// converter loads a non null cell onto a user registered type
+// returned value must either be of the registered type or a pointer to it
+type converter func(c *value.Cell) (interface{}, error)
+
+// converters are keyed by the underlying type of the field, if a pointer type is registered, its element is used,
+// and by the upper case database type name of the column, converters for any column are stored with an empty name
+var converters = struct {
+	sync.RWMutex
+	m map[reflect.Type]map[string]converter
+}{m: map[reflect.Type]map[string]converter{}}
+
+// ConverterFunc converts a non null value, as it arrived from the driver, onto the registered type.
+// src is one of int64, float64, bool, []byte, string or time.Time,
+// returned value must either be of the registered type or a pointer to it
+type ConverterFunc func(src interface{}) (interface{}, error)
+
+// Raw fields receive the value of a column as it arrived from the driver, one of nil, int64, float64, bool, []byte, string or time.Time,
+// which allows decoding to be deferred, ie, of large json documents. Fields of type sql.RawBytes receive the value as bytes
+type Raw = value.RawValue
+
+// RegisterConverter registers a conversion onto fields of the type of dst, pointers to that type are also converted
+// if columnType is not empty, the conversion only applies to columns of that database type, ie, "NUMERIC" or "JSONB",
+// otherwise it applies to any column, conversions registered for a specific column type take precedence
+// example:
+//
+//	carta.RegisterConverter("NUMERIC", decimal.Decimal{}, func(src interface{}) (interface{}, error) {
+//		return decimal.NewFromString(string(src.([]byte)))
+//	})
+//
+// registering a converter resets cached mappers
+func RegisterConverter(columnType string, dst interface{}, fn ConverterFunc) {
+	registerConverter(reflect.TypeOf(dst), columnType, func(c *value.Cell) (interface{}, error) {
+		return fn(c.Value())
+	})
+}
+
+// SetTimeLayouts sets the layouts, as used by time.Parse, which are tried in order when time data arrives as text,
+// which is the case with SQLite or MySQL without parseTime=true,
+// defaults are time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00" and "2006-01-02"
+// layouts may be set while mapping, rows which are being loaded may then be parsed with either layouts
+func SetTimeLayouts(layouts ...string) {
+	value.SetTimeLayouts(layouts...)
+}
+
+// SetTimeLocation converts all loaded time data to the location, ie, time.UTC, regardless of the location returned by the driver,
+// time data arriving as text without a time zone is parsed in this location, nil restores the default behavior
+// location may be set while mapping, rows which are being loaded may then be in either location
+func SetTimeLocation(loc *time.Location) {
+	value.SetTimeLocation(loc)
+}
+
+// GeometryDecoder decodes ISO WKB geometry onto a user type, srid is 0 if the column does not carry one
+type GeometryDecoder func(wkb []byte, srid uint32) (interface{}, error)
+
+// RegisterGeometry registers a type which will be loaded from geometry/geography columns.
+// PostGIS EWKB (binary or hex encoded), MySQL spatial values and plain WKB are converted to ISO WKB before decode is called
+// example, using github.com/paulmach/orb:
+//
+//	carta.RegisterGeometry(orb.Point{}, func(b []byte, srid uint32) (interface{}, error) {
+//	        return wkb.Unmarshal(b)
+//	})
+//
+// registering a type resets cached mappers
+func RegisterGeometry(dst interface{}, decode GeometryDecoder) {
+	registerConverter(reflect.TypeOf(dst), "", func(c *value.Cell) (interface{}, error) {
+		wkb, srid, err := c.Geometry()
+		if err != nil {
+			return nil, err
+		}
+		return decode(wkb, srid)
+	})
+}
+
+// RegisterJSON registers a conversion onto fields of the type of dst, ie, map[string]int, decoding JSON of text columns,
+// such as of json columns, or of collections of drivers which are loaded as JSON, ie, maps of Cassandra or ClickHouse.
+// Registered slices, ie, []string, are loaded from a single column, instead of from a column of many rows
+//
+//	carta.RegisterJSON(map[string]int{})
+//
+// registering a type resets cached mappers
+func RegisterJSON(dst interface{}) {
+	t := reflect.TypeOf(dst)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	registerConverter(t, "", func(c *value.Cell) (interface{}, error) {
+		text, err := c.String()
+		if err != nil {
+			return nil, err
+		}
+		v := reflect.New(t)
+		if err = json.Unmarshal([]byte(text), v.Interface()); err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	})
+}
+
+func registerConverter(t reflect.Type, columnType string, conv converter) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	converters.Lock()
+	if _, ok := converters.m[t]; !ok {
+		converters.m[t] = map[string]converter{}
+	}
+	converters.m[t][strings.ToUpper(columnType)] = conv
+	converters.Unlock()
+
+	// cached mappers were planned without this converter
+	invalidateCaches()
+}
+
+// finds the converter of a type for a column of the given database type,
+// if columnType is empty, returns any converter of the type, which is used to determine whether the type is basic
+// unregistered proto enums are converted by name using their descriptors
+func findConverter(t reflect.Type, columnType string) converter {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	converters.RLock()
+	defer converters.RUnlock()
+	byColumn := converters.m[t]
+	if conv, ok := byColumn[strings.ToUpper(columnType)]; ok {
+		return conv
+	}
+	if conv, ok := byColumn[""]; ok {
+		return conv
+	}
+	if columnType == "" {
+		for _, conv := range byColumn {
+			return conv
+		}
+	}
+	return protoEnumConverter(t)
+}
+
+// sets the destination with the converted value of a cell, typ is the type of the destination
+func setConverted(dst reflect.Value, typ reflect.Type, conv converter, cell *value.Cell) error {
+	d, err := conv(cell)
+	if e, ok := err.(*EnumValueError); ok {
+		return e
+	} else if err != nil {
+		return value.ConvertsionError(err, typ)
+	}
+	v := reflect.ValueOf(d)
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == typ {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.Type().AssignableTo(typ) {
+		return errorf(ErrTypeMismatch, "carta: converter returned %s, which cannot be assigned to %s", v.Type(), typ)
+	}
+	dst.Set(v)
+	return nil
+}
+
+var (
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// types implementing encoding.TextUnmarshaler, ie, ulid.ULID or semver.Version, are loaded from text columns with UnmarshalText,
+// types natively supported by carta, such as time.Time or net.IP, are not considered
+func isTextUnmarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := value.BasicTypes[t]; ok {
+		return false
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// types implementing encoding.BinaryUnmarshaler are loaded from binary columns, ie, bytea or blob, with UnmarshalBinary
+func isBinaryUnmarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := value.BasicTypes[t]; ok {
+		return false
+	}
+	return reflect.PtrTo(t).Implements(binaryUnmarshalerType)
+}
+
+// UnmarshalBinary is used for columns of binary database types, if the type cannot unmarshal text, ie, only
+// implements encoding.BinaryUnmarshaler, it is used for any column which arrives as text or bytes
+func useUnmarshalBinary(col column, cell *value.Cell) bool {
+	if !col.unmarshalBinary || cell.Kind() != reflect.String {
+		return false
+	}
+	return cell.IsBinary() || !col.unmarshalText
+}
+
+// text cells are always loaded with UnmarshalText, however, if the type is also a primative, ie, an enum,
+// other cells are loaded natively
+func useUnmarshalText(col column, kind reflect.Kind, cell *value.Cell) bool {
+	if !col.unmarshalText {
+		return false
+	}
+	if _, ok := value.BasicKinds[kind]; ok {
+		return cell.Kind() == reflect.String
+	}
+	return true
+}
+
+func setUnmarshaledText(dst reflect.Value, typ reflect.Type, cell *value.Cell) error {
+	b, err := cell.Bytes()
+	if err != nil {
+		return value.ConvertsionError(err, typ)
+	}
+	if err = dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(b); err != nil {
+		return value.ConvertsionError(err, typ)
+	}
+	return nil
+}
+
+func setUnmarshaledBinary(dst reflect.Value, typ reflect.Type, cell *value.Cell) error {
+	b, err := cell.Bytes()
+	if err != nil {
+		return value.ConvertsionError(err, typ)
+	}
+	if err = dst.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b); err != nil {
+		return value.ConvertsionError(err, typ)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package carta
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MapCSV maps csv records onto dst as Map does, the header record names the columns, example:
+//
+//	blogs := []Blog{}
+//	err := carta.MapCSV(file, &blogs)
+//
+// fields are loaded as text, which is converted to the types of the destination, ie, "12" onto int64, "true" onto bool
+// or "2020-01-02" onto time.Time,
+// fields are never null, unless set with WithCSVNull
+func MapCSV(r io.Reader, dst interface{}, opts ...Option) error {
+	return MapCSVReader(csv.NewReader(r), dst, opts...)
+}
+
+// MapCSVReader maps records of a csv reader onto dst as MapCSV does, ie, of a reader with another delimiter
+func MapCSVReader(r *csv.Reader, dst interface{}, opts ...Option) error {
+	header, err := r.Read()
+	if err == io.EOF {
+		return errors.New("carta: csv has no header")
+	} else if err != nil {
+		return err
+	}
+	return Map(&csvSource{r: r, columns: header, null: newOptions(opts).csvNull}, dst, opts...)
+}
+
+// WithCSVNull loads fields of csv records equal to null as null values, ie, "" or "NULL", onto pointers or nullable types
+func WithCSVNull(null string) Option {
+	return func(o *options) {
+		o.csvNull = &null
+	}
+}
+
+// source of rows of csv records
+type csvSource struct {
+	r       *csv.Reader
+	columns []string
+	null    *string
+	record  []string
+	n       int // number of records read, excluding the header
+	err     error
+}
+
+func (s *csvSource) Columns() ([]string, error) {
+	return s.columns, nil
+}
+
+func (s *csvSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	s.record, s.err = s.r.Read()
+	if s.err == io.EOF {
+		s.err = nil
+		return false
+	}
+	s.n++
+	return s.err == nil
+}
+
+func (s *csvSource) Scan(dest ...interface{}) error {
+	if len(s.record) != len(dest) {
+		return fmt.Errorf("carta: csv record %d has %d fields, expected a field for each of %d columns", s.n, len(s.record), len(dest))
+	}
+	for i, field := range s.record {
+		var v interface{} = field
+		if s.null != nil && field == *s.null {
+			v = nil
+		}
+		if err := dest[i].(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *csvSource) Err() error {
+	return s.err
+}
+
+func (s *csvSource) Close() error {
+	return nil
+}
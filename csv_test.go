@@ -0,0 +1,51 @@
+package carta_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+)
+
+type imported struct {
+	Id       int
+	Active   bool
+	Created  time.Time
+	Rating   *float64
+	Nickname *string
+}
+
+func TestMapCSV(t *testing.T) {
+	csv := "id,active,created,rating,nickname\n" +
+		"1,true,2020-01-02,4.5,first\n" +
+		"2,false,2020-01-03 10:00:00,,\n"
+	entities := []imported{}
+	if err := carta.MapCSV(strings.NewReader(csv), &entities, carta.WithCSVNull("")); err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("expected 2 entities, got %+v", entities)
+	}
+	first, second := entities[0], entities[1]
+	if first.Id != 1 || !first.Active || !first.Created.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)) ||
+		first.Rating == nil || *first.Rating != 4.5 || first.Nickname == nil || *first.Nickname != "first" {
+		t.Fatalf("csv fields were not converted, got %+v", first)
+	}
+	if second.Active || !second.Created.Equal(time.Date(2020, 1, 3, 10, 0, 0, 0, time.UTC)) || second.Rating != nil || second.Nickname != nil {
+		t.Fatalf("expected empty fields to load as nil, got %+v", second)
+	}
+}
+
+func TestMapCSVErrors(t *testing.T) {
+	entities := []imported{}
+	if err := carta.MapCSV(strings.NewReader(""), &entities); err == nil {
+		t.Fatal("expected an error for csv without a header")
+	}
+	if err := carta.MapCSV(strings.NewReader("id,active\n1,maybe\n"), &entities); err == nil {
+		t.Fatal(`expected an error converting "maybe" to bool`)
+	}
+	if err := carta.MapCSV(strings.NewReader("id,active\n1,true,extra\n"), &entities); err == nil {
+		t.Fatal("expected an error for a record with more fields than columns")
+	}
+}
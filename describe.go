@@ -0,0 +1,167 @@
+package carta
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// Conversion describes how a column is loaded onto its field
+type Conversion string
+
+const (
+	// Native conversions are carta's own, ie, onto primitives, time.Time or protobuf types
+	Native Conversion = "native"
+	// Converted columns are loaded with a registered converter, enum or geometry decoder
+	Converted Conversion = "converter"
+	// TextUnmarshaled columns are loaded with encoding.TextUnmarshaler
+	TextUnmarshaled Conversion = "text unmarshaler"
+	// BinaryUnmarshaled columns are loaded with encoding.BinaryUnmarshaler, or with encoding.TextUnmarshaler for text columns
+	BinaryUnmarshaled Conversion = "binary unmarshaler"
+)
+
+// Mapping describes how columns map onto a destination, or onto one of its nested structs or slices
+type Mapping struct {
+	Path        string       // path of the field in go, ie, "Posts.Author", empty for the destination
+	Type        reflect.Type // type of the elements, ie, Post for []*Post
+	Cardinality Cardinality
+	Columns     []ColumnMapping // columns claimed by the fields, in the order of the result
+	Nested      []*Mapping      // nested structs and slices, in the order of the fields
+
+	// set for the destination only
+	UnmatchedColumns []string // columns which do not match any field
+	UnmatchedFields  []string // paths of fields which do not match any column
+}
+
+// ColumnMapping describes a column claimed by a field
+type ColumnMapping struct {
+	Column     string
+	Field      string       // path of the field in go, ie, "Posts.Title", or the path of the slice for slices of basic types
+	Type       reflect.Type // type of the field
+	Key        bool         // the column determines uniqueness of the elements
+	Conversion Conversion
+}
+
+func (c Cardinality) String() string {
+	switch c {
+	case Association:
+		return "association"
+	case Collection:
+		return "collection"
+	}
+	return "unknown"
+}
+
+// DescribeMapping describes how columns would map onto dst, which is a pointer to a slice or to a struct, as passed to Map,
+// ie, to inspect or assert on the mapping in tests, only the type of dst is used
+func DescribeMapping(dst interface{}, columns []string, opts ...Option) (*Mapping, error) {
+	m, err := planMapper(reflect.TypeOf(dst), columns, nil, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return m.Describe(), nil
+}
+
+// Describe describes how the columns the mapper was built with map onto the destination
+func (m *Mapper) Describe() *Mapping {
+	d := m.describe()
+	for _, i := range m.unmatchedColumns {
+		d.UnmatchedColumns = append(d.UnmatchedColumns, m.columns[i])
+	}
+	d.UnmatchedFields = m.unmatchedFields
+	return d
+}
+
+func (m *Mapper) describe() *Mapping {
+	d := &Mapping{
+		Path:        m.path,
+		Type:        m.Typ,
+		Cardinality: m.Crd,
+	}
+	keys := map[int]bool{}
+	for _, i := range m.SortedColumnIndexes {
+		keys[i] = true
+	}
+	cols := make([]column, 0, len(m.PresentColumns))
+	for _, col := range m.PresentColumns {
+		cols = append(cols, col)
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].columnIndex < cols[j].columnIndex })
+	for _, col := range cols {
+		c := ColumnMapping{
+			Column:     col.name,
+			Key:        keys[col.columnIndex],
+			Conversion: col.conversion(),
+		}
+		if m.IsBasic {
+			c.Field = m.path
+			c.Type = m.Typ
+			if m.IsTypePtr {
+				c.Type = reflect.PtrTo(m.Typ)
+			}
+		} else {
+			c.Field = joinPath(m.path, m.Typ.Field(int(col.i)).Name)
+			c.Type = m.Fields[col.i].Typ
+		}
+		d.Columns = append(d.Columns, c)
+	}
+
+	fieldIndexes := []int{}
+	for i := range m.SubMaps {
+		fieldIndexes = append(fieldIndexes, int(i))
+	}
+	sort.Ints(fieldIndexes)
+	for _, i := range fieldIndexes {
+		d.Nested = append(d.Nested, m.SubMaps[fieldIndex(i)].describe())
+	}
+	return d
+}
+
+// PlanJSON serializes the description of the mapper as indented JSON, ie, for debugging or to attach to bug reports,
+// types are serialized by their names
+func (m *Mapper) PlanJSON() ([]byte, error) {
+	return json.MarshalIndent(m.Describe().plan(), "", "  ")
+}
+
+// serialized mapping, since types do not serialize to JSON
+type mappingPlan struct {
+	Path             string        `json:"path"`
+	Type             string        `json:"type"`
+	Cardinality      string        `json:"cardinality"`
+	Columns          []columnPlan  `json:"columns"`
+	Nested           []mappingPlan `json:"nested,omitempty"`
+	UnmatchedColumns []string      `json:"unmatched_columns,omitempty"`
+	UnmatchedFields  []string      `json:"unmatched_fields,omitempty"`
+}
+
+type columnPlan struct {
+	Column     string     `json:"column"`
+	Field      string     `json:"field"`
+	Type       string     `json:"type"`
+	Key        bool       `json:"key,omitempty"`
+	Conversion Conversion `json:"conversion"`
+}
+
+func (d *Mapping) plan() mappingPlan {
+	p := mappingPlan{
+		Path:             d.Path,
+		Type:             d.Type.String(),
+		Cardinality:      d.Cardinality.String(),
+		Columns:          make([]columnPlan, 0, len(d.Columns)),
+		UnmatchedColumns: d.UnmatchedColumns,
+		UnmatchedFields:  d.UnmatchedFields,
+	}
+	for _, c := range d.Columns {
+		p.Columns = append(p.Columns, columnPlan{
+			Column:     c.Column,
+			Field:      c.Field,
+			Type:       c.Type.String(),
+			Key:        c.Key,
+			Conversion: c.Conversion,
+		})
+	}
+	for _, n := range d.Nested {
+		p.Nested = append(p.Nested, n.plan())
+	}
+	return p
+}
@@ -0,0 +1,35 @@
+package carta_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestPlanJSON(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]jsonBlog)(nil), []string{"blog_id", "title", "tags", "meta", "post_id", "extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := mapper.PlanJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan = append(plan, '\n')
+	goldenFile := "testdata/plan.golden"
+	if update {
+		if err = os.WriteFile(goldenFile, plan, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plan, expected) {
+		t.Fatalf("expected plan:\n%s\ngot:\n%s\nrun go test with --update if this is the expected plan", expected, plan)
+	}
+}
@@ -0,0 +1,76 @@
+package carta
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNotPointer is wrapped by a DestinationError when the destination is not a pointer, ie, []Blog instead of *[]Blog
+	ErrNotPointer = errors.New("carta: destination is not a pointer")
+	// ErrNilPointer is wrapped by a DestinationError when the destination is nil, or a nil pointer
+	ErrNilPointer = errors.New("carta: destination is a nil pointer")
+	// ErrUnsupportedDestination is wrapped by a DestinationError when the destination points to a type rows cannot be mapped onto
+	ErrUnsupportedDestination = errors.New("carta: destination points to an unsupported type")
+)
+
+// DestinationError is returned when rows cannot be mapped onto a destination,
+// it wraps ErrNotPointer, ErrNilPointer or ErrUnsupportedDestination, and matches ErrInvalidDestination with errors.Is
+type DestinationError struct {
+	Type reflect.Type // type of the destination, nil if the destination is nil
+	Err  error
+
+	expected string // destinations which are supported, ie, "pointer to a struct"
+}
+
+func (e *DestinationError) Error() string {
+	msg := fmt.Sprintf("carta: cannot map rows onto %v, %s", e.Type, strings.TrimPrefix(e.Err.Error(), "carta: "))
+	if e.expected != "" {
+		msg += ", destination must be " + e.expected
+	}
+	return msg
+}
+
+func (e *DestinationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DestinationError) Is(target error) bool {
+	return target == ErrInvalidDestination
+}
+
+// supported destinations of Map
+const mapDestinations = "pointer to a slice(*[]), pointer to a map, pointer to a struct or pointer to a basic type"
+
+// error of a destination of type t, which is not one of the expected destinations
+func destinationError(t reflect.Type, expected string) error {
+	e := &DestinationError{Type: t, Err: ErrUnsupportedDestination, expected: expected}
+	if t == nil {
+		e.Err = ErrNilPointer
+	} else if t.Kind() != reflect.Ptr {
+		e.Err = ErrNotPointer
+	}
+	return e
+}
+
+// validates the type of the destination passed to Map
+func validateDestinationType(t reflect.Type) error {
+	if t == nil || !(isSlicePtr(t) || isStructPtr(t) || isMapPtr(t) || isScalarPtr(t)) {
+		return destinationError(t, mapDestinations)
+	}
+	return nil
+}
+
+// validates the destination passed to Map, which must be a non nil pointer to a supported type
+func validateDestination(dst interface{}) error {
+	t := reflect.TypeOf(dst)
+	if err := validateDestinationType(t); err != nil {
+		return err
+	}
+	if reflect.ValueOf(dst).IsNil() {
+		return &DestinationError{Type: t, Err: ErrNilPointer}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package carta
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dot draws the mapping as a Graphviz graph, ie, for debugging which of several similarly named columns a nested struct claims,
+// each node is the destination or one of its nested structs or slices, listing the columns it claims and the fields they are loaded onto,
+// edges are labeled with the fields and their cardinality, and columns and fields which do not match are drawn in a dashed node, example:
+//
+//	dot := mapper.Dot()
+//	os.WriteFile("mapping.dot", []byte(dot), 0644) // dot -Tsvg mapping.dot > mapping.svg
+func (m *Mapper) Dot() string {
+	d := m.Describe()
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(m.dstTyp.String()))
+	b.WriteString("\tnode [shape=box];\n")
+	n := 0
+	var draw func(d *Mapping) int
+	draw = func(d *Mapping) int {
+		id := n
+		n++
+		label := dotEscape(d.title()) + "\\n\\n"
+		for _, c := range d.Columns {
+			label += dotEscape(d.claim(c)) + "\\l"
+		}
+		fmt.Fprintf(&b, "\tn%d [label=\"%s\"];\n", id, label)
+		for _, nested := range d.Nested {
+			child := draw(nested)
+			fmt.Fprintf(&b, "\tn%d -> n%d [label=%s];\n", id, child, dotQuote(nested.edge()))
+		}
+		return id
+	}
+	draw(d)
+	if unmatched := d.unmatched(); len(unmatched) != 0 {
+		label := ""
+		for _, line := range unmatched {
+			label += dotEscape(line) + "\\l"
+		}
+		fmt.Fprintf(&b, "\tunmatched [label=\"%s\", style=dashed];\n", label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid draws the mapping as a Mermaid flowchart, ie, to embed in markdown, with the nodes and edges of Dot
+func (m *Mapper) Mermaid() string {
+	d := m.Describe()
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	n := 0
+	var draw func(d *Mapping) int
+	draw = func(d *Mapping) int {
+		id := n
+		n++
+		lines := []string{mermaidEscape(d.title())}
+		for _, c := range d.Columns {
+			lines = append(lines, mermaidEscape(d.claim(c)))
+		}
+		fmt.Fprintf(&b, "\tn%d[\"%s\"]\n", id, strings.Join(lines, "<br/>"))
+		for _, nested := range d.Nested {
+			child := draw(nested)
+			fmt.Fprintf(&b, "\tn%d -- \"%s\" --> n%d\n", id, mermaidEscape(nested.edge()), child)
+		}
+		return id
+	}
+	draw(d)
+	if unmatched := d.unmatched(); len(unmatched) != 0 {
+		lines := make([]string, len(unmatched))
+		for i, line := range unmatched {
+			lines[i] = mermaidEscape(line)
+		}
+		fmt.Fprintf(&b, "\tunmatched[\"%s\"]\n", strings.Join(lines, "<br/>"))
+		b.WriteString("\tstyle unmatched stroke-dasharray: 5 5\n")
+	}
+	return b.String()
+}
+
+// title of the node of the mapping, ie, "Posts: carta.Post"
+func (d *Mapping) title() string {
+	if d.Path == "" {
+		return d.Type.String()
+	}
+	return d.Path + ": " + d.Type.String()
+}
+
+// label of the edge to the mapping, ie, "Posts (collection)"
+func (d *Mapping) edge() string {
+	name := d.Path[strings.LastIndex(d.Path, ".")+1:]
+	return name + " (" + d.Cardinality.String() + ")"
+}
+
+// line of a claimed column, ie, "posts_id → Id (key)", fields are relative to the mapping
+func (d *Mapping) claim(c ColumnMapping) string {
+	field := strings.TrimPrefix(strings.TrimPrefix(c.Field, d.Path), ".")
+	if field == "" {
+		field = "(element)"
+	}
+	line := c.Column + " → " + field
+	if c.Key {
+		line += " (key)"
+	}
+	return line
+}
+
+// lines of columns and fields which do not match
+func (d *Mapping) unmatched() []string {
+	var lines []string
+	if len(d.UnmatchedColumns) != 0 {
+		lines = append(lines, "unclaimed columns: "+strings.Join(d.UnmatchedColumns, ", "))
+	}
+	if len(d.UnmatchedFields) != 0 {
+		lines = append(lines, "unmapped fields: "+strings.Join(d.UnmatchedFields, ", "))
+	}
+	return lines
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
+
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+// quotes and angle brackets are written as entities, since labels are quoted and may hold html
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+func mermaidEscape(s string) string {
+	return mermaidEscaper.Replace(s)
+}
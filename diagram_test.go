@@ -0,0 +1,56 @@
+package carta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type chartedUser struct {
+	Id        int
+	Name      string
+	Addresses []chartedAddress
+	Nickname  string
+}
+
+type chartedAddress struct {
+	AddressId int `db:"address_id,pk"`
+	City      string
+}
+
+var chartedColumns = []string{"id", "name", "address_id", "city", `"quoted"<b>`}
+
+func TestDot(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]chartedUser)(nil), chartedColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph "*[]carta_test.chartedUser" {
+	node [shape=box];
+	n0 [label="carta_test.chartedUser\n\nid → Id (key)\lname → Name (key)\l"];
+	n1 [label="Addresses: carta_test.chartedAddress\n\naddress_id → AddressId (key)\lcity → City\l"];
+	n0 -> n1 [label="Addresses (collection)"];
+	unmatched [label="unclaimed columns: \"quoted\"<b>\lunmapped fields: Nickname\l", style=dashed];
+}
+`
+	if dot := mapper.Dot(); dot != expected {
+		t.Fatalf("expected graph:\n%s\ngot:\n%s", expected, dot)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]chartedUser)(nil), chartedColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `flowchart TD
+	n0["carta_test.chartedUser<br/>id → Id (key)<br/>name → Name (key)"]
+	n1["Addresses: carta_test.chartedAddress<br/>address_id → AddressId (key)<br/>city → City"]
+	n0 -- "Addresses (collection)" --> n1
+	unmatched["unclaimed columns: #quot;quoted#quot;#lt;b#gt;<br/>unmapped fields: Nickname"]
+	style unmatched stroke-dasharray: 5 5
+`
+	if mermaid := mapper.Mermaid(); mermaid != expected {
+		t.Fatalf("expected flowchart:\n%s\ngot:\n%s", expected, mermaid)
+	}
+}
@@ -0,0 +1,246 @@
+package carta
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/jackskj/carta/value"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// MapDynamic maps rows onto messages of the descriptor, created with dynamicpb, ie, for descriptors loaded at runtime
+// from a schema registry, without generated go types, example:
+//
+//	desc, err := files.FindDescriptorByName("blog.Blog")
+//	...
+//	blogs, err := carta.MapDynamic(rows, desc.(protoreflect.MessageDescriptor))
+//
+// fields match columns as fields of the generated messages would, ie, blog_id matches field BlogId,
+// messages nested in the message are associations, and repeated messages are collections. Enums are loaded from names or numbers,
+// well known types, ie, google.protobuf.Timestamp, are loaded as they are onto generated messages, and map fields are not loaded
+func MapDynamic(rows RowsSource, desc protoreflect.MessageDescriptor, opts ...Option) ([]*dynamicpb.Message, error) {
+	dt := newDynamicType(desc, map[protoreflect.FullName]bool{})
+	dst := reflect.New(reflect.SliceOf(reflect.PtrTo(dt.typ)))
+	if err := Map(rows, dst.Interface(), opts...); err != nil {
+		return nil, err
+	}
+	dst = dst.Elem()
+	msgs := make([]*dynamicpb.Message, dst.Len())
+	for i := range msgs {
+		msg := dynamicpb.NewMessage(desc)
+		if err := dt.set(msg, dst.Index(i).Elem()); err != nil {
+			return nil, err
+		}
+		msgs[i] = msg
+	}
+	return msgs, nil
+}
+
+// unnamed struct which rows are mapped onto, in place of messages of a descriptor
+type dynamicType struct {
+	desc   protoreflect.MessageDescriptor
+	typ    reflect.Type
+	fields []dynamicField // fields of the struct
+}
+
+type dynamicField struct {
+	fd     protoreflect.FieldDescriptor
+	nested *dynamicType // type of nested messages, nil unless fd is a message which is not a well known type
+}
+
+// parents are the messages enclosing the descriptor, fields of recursive messages are not loaded
+func newDynamicType(desc protoreflect.MessageDescriptor, parents map[protoreflect.FullName]bool) *dynamicType {
+	parents[desc.FullName()] = true
+	defer delete(parents, desc.FullName())
+	dt := &dynamicType{desc: desc}
+	var structFields []reflect.StructField
+	names := map[string]bool{}
+	fds := desc.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if fd.IsMap() {
+			continue
+		}
+		f := dynamicField{fd: fd}
+		var typ reflect.Type // go type of the field, or of the elements of repeated fields
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			if typ = wellKnownType(fd.Message()); typ != nil {
+				break
+			}
+			if parents[fd.Message().FullName()] {
+				continue
+			}
+			f.nested = newDynamicType(fd.Message(), parents)
+			typ = reflect.PtrTo(f.nested.typ)
+		case protoreflect.EnumKind:
+			// names and numbers are resolved once the rows are mapped, from the values of columns as they arrived
+			typ = reflect.TypeOf(Raw{})
+		default:
+			typ = scalarTypes[fd.Kind()]
+		}
+		if typ.Kind() != reflect.Ptr {
+			// null columns leave fields unset
+			typ = reflect.PtrTo(typ)
+		}
+		if fd.IsList() {
+			typ = reflect.SliceOf(typ)
+		}
+		name := goCamelCase(string(fd.Name()))
+		for names[name] {
+			name += "_"
+		}
+		names[name] = true
+		structFields = append(structFields, reflect.StructField{Name: name, Type: typ})
+		dt.fields = append(dt.fields, f)
+	}
+	dt.typ = reflect.StructOf(structFields)
+	return dt
+}
+
+// go types of scalar fields, as generated by protoc-gen-go
+var scalarTypes = map[protoreflect.Kind]reflect.Type{
+	protoreflect.BoolKind:     reflect.TypeOf(false),
+	protoreflect.Int32Kind:    reflect.TypeOf(int32(0)),
+	protoreflect.Sint32Kind:   reflect.TypeOf(int32(0)),
+	protoreflect.Sfixed32Kind: reflect.TypeOf(int32(0)),
+	protoreflect.Int64Kind:    reflect.TypeOf(int64(0)),
+	protoreflect.Sint64Kind:   reflect.TypeOf(int64(0)),
+	protoreflect.Sfixed64Kind: reflect.TypeOf(int64(0)),
+	protoreflect.Uint32Kind:   reflect.TypeOf(uint32(0)),
+	protoreflect.Fixed32Kind:  reflect.TypeOf(uint32(0)),
+	protoreflect.Uint64Kind:   reflect.TypeOf(uint64(0)),
+	protoreflect.Fixed64Kind:  reflect.TypeOf(uint64(0)),
+	protoreflect.FloatKind:    reflect.TypeOf(float32(0)),
+	protoreflect.DoubleKind:   reflect.TypeOf(float64(0)),
+	protoreflect.StringKind:   reflect.TypeOf(""),
+	protoreflect.BytesKind:    reflect.TypeOf([]byte(nil)),
+}
+
+// pointer to the generated go type of messages which carta loads from a single column, ie, google.protobuf.Timestamp,
+// nil for other messages
+func wellKnownType(desc protoreflect.MessageDescriptor) reflect.Type {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName())
+	if err != nil {
+		return nil
+	}
+	typ := reflect.TypeOf(mt.Zero().Interface())
+	if typ.Kind() != reflect.Ptr {
+		return nil
+	}
+	if _, ok := value.BasicTypes[typ.Elem()]; !ok {
+		return nil
+	}
+	return typ
+}
+
+// sets fields of msg to the fields of v, a struct of the dynamic type, which are not nil
+func (dt *dynamicType) set(msg protoreflect.Message, v reflect.Value) error {
+	for i, f := range dt.fields {
+		fv := v.Field(i)
+		if f.fd.IsList() {
+			list := msg.Mutable(f.fd).List()
+			for j := 0; j < fv.Len(); j++ {
+				if fv.Index(j).IsNil() {
+					continue
+				}
+				elem, err := f.value(fv.Index(j))
+				if err != nil {
+					return err
+				}
+				list.Append(elem)
+			}
+			continue
+		}
+		if fv.IsNil() {
+			continue
+		}
+		pv, err := f.value(fv)
+		if err != nil {
+			return err
+		}
+		msg.Set(f.fd, pv)
+	}
+	return nil
+}
+
+// value of the field, or of an element of a repeated field, from a value of its go type
+func (f dynamicField) value(v reflect.Value) (protoreflect.Value, error) {
+	switch {
+	case f.nested != nil:
+		nested := dynamicpb.NewMessage(f.nested.desc)
+		if err := f.nested.set(nested, v.Elem()); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(nested), nil
+	case f.fd.Message() != nil:
+		return protoreflect.ValueOfMessage(v.Interface().(protoreflect.ProtoMessage).ProtoReflect()), nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if f.fd.Kind() == protoreflect.EnumKind {
+		return dynamicEnumValue(f.fd.Enum(), v.Interface().(Raw).Value)
+	}
+	return protoreflect.ValueOf(v.Interface()), nil
+}
+
+// value of the enum of the name or number, src is the value of the column as it arrived from the driver
+func dynamicEnumValue(desc protoreflect.EnumDescriptor, src interface{}) (protoreflect.Value, error) {
+	var s string
+	switch src := src.(type) {
+	case int64:
+		if src < math.MinInt32 || src > math.MaxInt32 {
+			return protoreflect.Value{}, fmt.Errorf("carta: value %d of enum %s overflows int32", src, desc.FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(src)), nil
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return protoreflect.Value{}, fmt.Errorf("carta: cannot load %T onto enum %s", src, desc.FullName())
+	}
+	if v := desc.Values().ByName(protoreflect.Name(s)); v != nil {
+		return protoreflect.ValueOfEnum(v.Number()), nil
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf("carta: unknown value %q of enum %s", s, desc.FullName())
+	}
+	return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+}
+
+// name of the go field generated for a proto field, ie, BlogId for blog_id, as protoc-gen-go names it,
+// underscores are only dropped before lower case letters, and digits start words, ie, A_1B for a_1b
+func goCamelCase(name string) string {
+	var b []byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(name) && isLower(name[i+1]):
+			// the next letter is upper cased instead
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(name) && isLower(name[i+1]); i++ {
+				b = append(b, name[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isLower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
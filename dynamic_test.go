@@ -0,0 +1,153 @@
+package carta_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jackskj/carta"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// descriptor of dynamic.Blog, built at runtime as if it was loaded from a schema registry
+func dynamicBlog(t *testing.T) protoreflect.MessageDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(), Label: label.Enum()}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	const (
+		int64Type   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		stringType  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		messageType = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		enumType    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	)
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("dynamic.proto"),
+		Package:    proto.String("dynamic"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("DRAFT"), Number: proto.Int32(0)},
+				{Name: proto.String("PUBLISHED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Blog"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("blog_id", 1, int64Type, "", false),
+					field("title", 2, stringType, "", false),
+					field("status", 3, enumType, ".dynamic.Status", false),
+					field("created", 4, messageType, ".google.protobuf.Timestamp", false),
+					field("a_1b", 5, stringType, "", false),
+					field("author", 6, messageType, ".dynamic.Author", false),
+					field("posts", 7, messageType, ".dynamic.Post", true),
+					field("parent", 8, messageType, ".dynamic.Blog", false),
+				},
+			},
+			{
+				Name: proto.String("Author"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("author_id", 1, int64Type, "", false),
+					field("author_name", 2, stringType, "", false),
+				},
+			},
+			{
+				Name: proto.String("Post"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("post_id", 1, int64Type, "", false),
+					field("post_status", 2, enumType, ".dynamic.Status", false),
+				},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Blog")
+}
+
+func TestMapDynamic(t *testing.T) {
+	desc := dynamicBlog(t)
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"blog_id", "title", "status", "created", "a_1b", "author_id", "author_name", "post_id", "post_status"}
+	rows := &oracleRows{
+		columns: columns,
+		types:   make([]string, len(columns)),
+		rows: [][]interface{}{
+			{int64(1), "first", "PUBLISHED", created, "digits", int64(7), "ann", int64(10), int64(1)},
+			{int64(1), "first", "PUBLISHED", created, "digits", int64(7), "ann", int64(11), "DRAFT"},
+			{int64(2), "second", int64(0), nil, nil, nil, nil, nil, nil},
+		},
+	}
+	blogs, err := carta.MapDynamic(rows, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %d", len(blogs))
+	}
+	fields := desc.Fields()
+	get := func(m protoreflect.Message, name string) protoreflect.Value {
+		return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name)))
+	}
+
+	first := blogs[0]
+	if get(first, "blog_id").Int() != 1 || get(first, "title").String() != "first" || get(first, "a_1b").String() != "digits" {
+		t.Errorf("scalar fields were not loaded, got %v", first)
+	}
+	if get(first, "status").Enum() != 1 {
+		t.Errorf("expected status PUBLISHED loaded by name, got %v", get(first, "status").Enum())
+	}
+	ts, ok := get(first, "created").Message().Interface().(*timestamppb.Timestamp)
+	if !ok || ts.Seconds != created.Unix() {
+		t.Errorf("expected the created timestamp, got %v", get(first, "created"))
+	}
+	author := get(first, "author").Message()
+	if get(author, "author_id").Int() != 7 || get(author, "author_name").String() != "ann" {
+		t.Errorf("nested message was not loaded, got %v", author)
+	}
+	posts := get(first, "posts").List()
+	if posts.Len() != 2 {
+		t.Fatalf("expected 2 posts, got %d", posts.Len())
+	}
+	if post := posts.Get(0).Message(); get(post, "post_id").Int() != 10 || get(post, "post_status").Enum() != 1 {
+		t.Errorf("expected post 10 with status loaded by number, got %v", post)
+	}
+	if post := posts.Get(1).Message(); get(post, "post_id").Int() != 11 || get(post, "post_status").Enum() != 0 {
+		t.Errorf("expected post 11 with status loaded by name, got %v", post)
+	}
+	if first.Has(fields.ByName("parent")) {
+		t.Error("expected the recursive field not to be loaded")
+	}
+
+	second := blogs[1]
+	if second.Has(fields.ByName("created")) || second.Has(fields.ByName("a_1b")) || get(second, "status").Enum() != 0 {
+		t.Errorf("expected null columns to leave fields unset, got %v", second)
+	}
+}
+
+func TestMapDynamicUnknownEnum(t *testing.T) {
+	rows := &oracleRows{
+		columns: []string{"blog_id", "status"},
+		types:   []string{"", ""},
+		rows:    [][]interface{}{{int64(1), "ARCHIVED"}},
+	}
+	if _, err := carta.MapDynamic(rows, dynamicBlog(t)); err == nil {
+		t.Fatal("expected an error for an unknown name of the enum")
+	}
+}
@@ -0,0 +1,129 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackskj/carta/value"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EnumPolicy determines how names which are not values of a registered enum are loaded
+type EnumPolicy int
+
+const (
+	// EnumError fails the mapping
+	EnumError EnumPolicy = iota
+	// EnumZero loads the zero value of the enum, which for proto enums is the UNSPECIFIED value
+	EnumZero
+	// EnumStrict fails the mapping with an EnumValueError when a column holds either a name or a number
+	// which is not a value of the enum, numbers are otherwise loaded as they are
+	EnumStrict
+)
+
+// EnumValueError is returned by enums registered with EnumStrict, when a column holds a value which is not a value of the enum
+type EnumValueError struct {
+	Column string
+	Row    int // 1 based number of the row
+	Value  interface{}
+	Enum   reflect.Type
+}
+
+func (e *EnumValueError) Error() string {
+	return fmt.Sprintf("carta: %v in column %s, row %d, is not a value of %v", e.Value, e.Column, e.Row, e.Enum)
+}
+
+// RegisterEnum registers an enum type so that it can be loaded from columns holding names of its values
+// generated proto enums are resolved through their descriptors without registration, registering them changes the policy for unknown names
+// values maps the names to values, as generated by protoc-gen-go, example:
+//
+//	carta.RegisterEnum(pb.Status(0), pb.Status_value, carta.EnumError)
+//
+// names are matched exactly, or in upper case, numeric columns are loaded as they are
+// registering an enum resets cached mappers
+func RegisterEnum(dst interface{}, values map[string]int32, unknown EnumPolicy) {
+	t := reflect.TypeOf(dst)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		panic(fmt.Sprintf("carta: cannot register %s as enum, enums must be integers", t))
+	}
+	registerConverter(t, "", enumConverter(t, values, unknown))
+}
+
+// SetEnumPolicy sets the policy of generated proto enums which were not registered with RegisterEnum, which is EnumError by default,
+// ie, carta.SetEnumPolicy(carta.EnumStrict) fails the mapping on numbers which are not values of proto enums, as registered enums do.
+// Setting the policy resets cached mappers
+func SetEnumPolicy(unknown EnumPolicy) {
+	converters.Lock()
+	protoEnumPolicy = unknown
+	converters.Unlock()
+
+	// cached mappers were planned with the previous policy
+	invalidateCaches()
+}
+
+var (
+	protoEnumType   = reflect.TypeOf((*protoreflect.Enum)(nil)).Elem()
+	protoEnumPolicy = EnumError // guarded by converters
+)
+
+// converter of generated proto enums which were not registered, names are resolved with the enum descriptor,
+// unknown values are handled with the policy set with SetEnumPolicy, converters must be locked
+// returns nil if t is not a proto enum
+func protoEnumConverter(t reflect.Type) converter {
+	if !t.Implements(protoEnumType) {
+		return nil
+	}
+	desc := reflect.Zero(t).Interface().(protoreflect.Enum).Descriptor().Values()
+	values := make(map[string]int32, desc.Len())
+	for i := 0; i < desc.Len(); i++ {
+		values[string(desc.Get(i).Name())] = int32(desc.Get(i).Number())
+	}
+	return enumConverter(t, values, protoEnumPolicy)
+}
+
+func enumConverter(t reflect.Type, values map[string]int32, unknown EnumPolicy) converter {
+	numbers := make(map[int32]bool, len(values))
+	for _, v := range values {
+		numbers[v] = true
+	}
+	return func(c *value.Cell) (interface{}, error) {
+		v, err := enumValue(c, values, unknown)
+		if e, ok := err.(*EnumValueError); ok {
+			e.Enum = t
+			return nil, e
+		} else if err != nil {
+			return nil, err
+		}
+		if unknown == EnumStrict && !numbers[v] {
+			return nil, &EnumValueError{Value: v, Enum: t}
+		}
+		return reflect.ValueOf(v).Convert(t).Interface(), nil
+	}
+}
+
+func enumValue(c *value.Cell, values map[string]int32, unknown EnumPolicy) (int32, error) {
+	if c.Kind() != reflect.String {
+		return c.Int32()
+	}
+	text, _ := c.String()
+	name := strings.TrimSpace(text)
+	if v, ok := values[name]; ok {
+		return v, nil
+	}
+	if v, ok := values[strings.ToUpper(name)]; ok {
+		return v, nil
+	}
+	if v, err := c.Int32(); err == nil {
+		return v, nil
+	}
+	switch unknown {
+	case EnumZero:
+		return 0, nil
+	case EnumStrict:
+		return 0, &EnumValueError{Value: text}
+	}
+	return 0, fmt.Errorf("%q is not a value of the enum", text)
+}
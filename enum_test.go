@@ -0,0 +1,116 @@
+package carta_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type ticketStatus int32
+
+type ticketPriority int
+
+type ticketSeverity int8
+
+var ticketValues = map[string]int32{"OPEN": 1, "CLOSED": 2}
+
+type ticket struct {
+	Id       int
+	Status   ticketStatus
+	Priority *ticketPriority
+	Severity ticketSeverity
+}
+
+func init() {
+	carta.RegisterEnum(ticketStatus(0), ticketValues, carta.EnumError)
+	carta.RegisterEnum(ticketPriority(0), ticketValues, carta.EnumZero)
+	carta.RegisterEnum(ticketSeverity(0), ticketValues, carta.EnumStrict)
+}
+
+func TestRegisterEnum(t *testing.T) {
+	columns := []string{"id", "status", "priority", "severity"}
+	load := func(status, priority, severity interface{}) (ticket, error) {
+		dst := []ticket{}
+		if err := carta.MapValues(columns, [][]interface{}{{1, status, priority, severity}}, &dst); err != nil {
+			return ticket{}, err
+		}
+		return dst[0], nil
+	}
+	// names are matched exactly or in upper case, and numbers are loaded as they are
+	v, err := load("OPEN", []byte(" closed "), int64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Status != 1 || v.Priority == nil || *v.Priority != 2 || v.Severity != 2 {
+		t.Fatalf("expected OPEN, CLOSED and CLOSED, got %+v", v)
+	}
+	if v, err = load(int64(7), nil, "1"); err != nil || v.Status != 7 || v.Priority != nil || v.Severity != 1 {
+		t.Fatalf("expected numbers to be loaded as they are, got %+v, %v", v, err)
+	}
+
+	if _, err = load("PENDING", "OPEN", "OPEN"); err == nil || !strings.Contains(err.Error(), `"PENDING" is not a value of the enum`) {
+		t.Fatalf("expected an error of the unknown name with EnumError, got %v", err)
+	}
+	if v, err = load("OPEN", "PENDING", "OPEN"); err != nil || v.Priority == nil || *v.Priority != 0 {
+		t.Fatalf("expected the zero value of the unknown name with EnumZero, got %+v, %v", v, err)
+	}
+	for _, severity := range []interface{}{"PENDING", int64(7)} {
+		_, err = load("OPEN", "OPEN", severity)
+		var e *carta.EnumValueError
+		if !errors.As(err, &e) || e.Column != "severity" || e.Row != 1 || e.Enum != reflect.TypeOf(ticketSeverity(0)) {
+			t.Fatalf("expected an EnumValueError of %v with EnumStrict, got %v", severity, err)
+		}
+	}
+}
+
+func TestRegisterEnumNotInteger(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a string type as enum to panic")
+		}
+	}()
+	carta.RegisterEnum("", ticketValues, carta.EnumError)
+}
+
+type typed struct {
+	Id   int
+	Type descriptorpb.FieldDescriptorProto_Type
+}
+
+func TestSetEnumPolicy(t *testing.T) {
+	defer carta.SetEnumPolicy(carta.EnumError)
+	columns := []string{"id", "type"}
+	load := func(v interface{}) (descriptorpb.FieldDescriptorProto_Type, error) {
+		dst := []typed{}
+		err := carta.MapValues(columns, [][]interface{}{{1, v}}, &dst)
+		if err != nil {
+			return 0, err
+		}
+		return dst[0].Type, nil
+	}
+	if v, err := load("TYPE_STRING"); err != nil || v != descriptorpb.FieldDescriptorProto_TYPE_STRING {
+		t.Fatalf("expected TYPE_STRING, got %v, %v", v, err)
+	}
+	if _, err := load("TYPE_UNKNOWN"); err == nil {
+		t.Fatal("expected an error loading a name which is not a value of the enum")
+	}
+	if v, err := load(99); err != nil || v != 99 {
+		t.Fatalf("expected numbers to be loaded as they are, got %v, %v", v, err)
+	}
+
+	carta.SetEnumPolicy(carta.EnumStrict)
+	if _, err := load(99); err == nil {
+		t.Fatal("expected an EnumValueError loading a number which is not a value of the enum")
+	} else if e, ok := err.(*carta.EnumValueError); !ok || e.Column != "type" {
+		t.Fatalf("expected an EnumValueError of the column type, got %v", err)
+	}
+
+	carta.SetEnumPolicy(carta.EnumZero)
+	if v, err := load("TYPE_UNKNOWN"); err != nil || v != 0 {
+		t.Fatalf("expected the zero value, got %v, %v", v, err)
+	}
+}
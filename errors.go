@@ -0,0 +1,85 @@
+package carta
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrNoRows is returned when exactly one entity is expected but there are no rows, same as sql.ErrNoRows
+	ErrNoRows = sql.ErrNoRows
+	// ErrTooManyRows is returned when exactly one entity is expected but rows map onto more than one
+	ErrTooManyRows = errors.New("carta: rows map onto more than one entity")
+	// ErrInvalidDestination is wrapped by errors returned when rows cannot be mapped onto the type of the destination,
+	// ie, a destination which is not a pointer, or a map whose elements are not structs
+	ErrInvalidDestination = errors.New("carta: invalid destination")
+	// ErrNoColumnsMatched is wrapped by errors returned when none of the columns match the destination
+	ErrNoColumnsMatched = errors.New("carta: no columns matched")
+	// ErrTypeMismatch is wrapped by errors returned when a value is not of the expected type, ie, a destination
+	// which is not of the type a mapper was built for, or a converter returning a value of another type
+	ErrTypeMismatch = errors.New("carta: type mismatch")
+	// ErrUnmatchedColumn is wrapped by errors returned with WithStrictColumns when columns do not match any field
+	ErrUnmatchedColumn = errors.New("carta: unmatched column")
+	// ErrUnmatchedField is wrapped by errors returned with WithStrictFields when fields do not match any column
+	ErrUnmatchedField = errors.New("carta: unmatched field")
+)
+
+// error wrapping a sentinel error, so that errors.Is holds, while keeping its own message
+type wrappedError struct {
+	msg      string
+	sentinel error
+}
+
+// errorf formats the message of an error wrapping the sentinel
+func errorf(sentinel error, format string, a ...interface{}) error {
+	return &wrappedError{fmt.Sprintf(format, a...), sentinel}
+}
+
+func (e *wrappedError) Error() string {
+	return e.msg
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.sentinel
+}
+
+// OverflowError is returned when a column holds a number which does not fit the destination, ie,
+// an int64 column holding 1 << 40 loaded onto an int32 field
+type OverflowError struct {
+	Column string
+	Field  string // path of the destination field, ie, "Posts.Id", or the path of the slice for slices of basic types
+	Value  interface{}
+	Type   reflect.Type // type of the destination
+}
+
+func (e *OverflowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("carta: value %v of column %s overflows %v", e.Value, e.Column, e.Type)
+	}
+	return fmt.Sprintf("carta: value %v of column %s overflows field %s of type %v", e.Value, e.Column, e.Field, e.Type)
+}
+
+// MappingError is returned when a column cannot be loaded onto a field, ie, a null column loaded onto an int,
+// or text which is not a number, the underlying error is returned by Unwrap
+type MappingError struct {
+	Column     string
+	FieldPath  string       // path of the field in go, ie, "Posts.Title", or the path of the slice for slices of basic types
+	SourceType reflect.Type // type of the value as it arrived from the driver, nil for null values
+	DestType   reflect.Type // type of the destination, the element type for pointers
+	Row        int          // number of the row, starting from 1
+	Err        error
+}
+
+func (e *MappingError) Error() string {
+	dst := fmt.Sprintf("%v", e.DestType)
+	if e.FieldPath != "" {
+		dst = fmt.Sprintf("field %s of type %v", e.FieldPath, e.DestType)
+	}
+	return fmt.Sprintf("carta: cannot load column %s of row %d onto %s: %s", e.Column, e.Row, dst, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,57 @@
+package carta
+
+import "strings"
+
+// Explain reports the mapping as indented text, a line per nested struct or slice, listing the columns it claims,
+// as well as the columns and fields which do not match, example:
+//
+//	User ← id, name
+//	  User.Addresses (collection, key: address_id) ← address_id, city
+//	UNCLAIMED: created_by
+//
+// keys are listed only when they are a subset of the claimed columns, ie, with the pk tag option
+func (m *Mapper) Explain() string {
+	d := m.Describe()
+	root := d.Type.Name()
+	if root == "" {
+		root = d.Type.String()
+	}
+	var b strings.Builder
+	var explain func(d *Mapping, depth int)
+	explain = func(d *Mapping, depth int) {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString(root)
+		var notes, columns, keys []string
+		if d.Path != "" {
+			b.WriteString("." + d.Path)
+			notes = append(notes, d.Cardinality.String())
+		}
+		for _, c := range d.Columns {
+			columns = append(columns, c.Column)
+			if c.Key {
+				keys = append(keys, c.Column)
+			}
+		}
+		if len(keys) != 0 && len(keys) != len(columns) {
+			notes = append(notes, "key: "+strings.Join(keys, ", "))
+		}
+		if len(notes) != 0 {
+			b.WriteString(" (" + strings.Join(notes, ", ") + ")")
+		}
+		if len(columns) != 0 {
+			b.WriteString(" ← " + strings.Join(columns, ", "))
+		}
+		b.WriteString("\n")
+		for _, nested := range d.Nested {
+			explain(nested, depth+1)
+		}
+	}
+	explain(d, 0)
+	if len(d.UnmatchedColumns) != 0 {
+		b.WriteString("UNCLAIMED: " + strings.Join(d.UnmatchedColumns, ", ") + "\n")
+	}
+	if len(d.UnmatchedFields) != 0 {
+		b.WriteString("UNMAPPED: " + strings.Join(d.UnmatchedFields, ", ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package carta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestExplain(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]chartedUser)(nil), []string{"id", "name", "address_id", "city", "created_by"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `chartedUser ← id, name
+  chartedUser.Addresses (collection, key: address_id) ← address_id, city
+UNCLAIMED: created_by
+UNMAPPED: Nickname
+`
+	if explained := mapper.Explain(); explained != expected {
+		t.Fatalf("expected report:\n%s\ngot:\n%s", expected, explained)
+	}
+
+	mapper, err = carta.NewMapper((*[]streamed)(nil), []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explained := mapper.Explain(); explained != "streamed ← id\n" {
+		t.Fatalf("expected a single line without unmatched columns, got:\n%s", explained)
+	}
+}
@@ -0,0 +1,100 @@
+package carta
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/jackskj/carta/value"
+)
+
+// ScanCells reads the rows onto cells, and calls fn with the cells of every row and its number, starting from 1,
+// it is used by mappers generated by protoc-gen-carta and carta-gen, which load fields from cells without reflection.
+// Cells are reused by the next row, columns are the columns of the rows
+func ScanCells(rows RowsSource, columns []string, fn func(row int, cells []value.Cell) error) error {
+	types, err := columnTypeNames(rows, columns)
+	if err != nil {
+		return err
+	}
+	blank := make([]value.Cell, len(columns))
+	for i := range blank {
+		blank[i] = *value.NewCell(types[i])
+	}
+	cells := make([]value.Cell, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range cells {
+		dest[i] = &cells[i]
+	}
+	for n := 1; rows.Next(); n++ {
+		copy(cells, blank)
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err = fn(n, cells); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CellKey returns the key of the element the cells of the columns map onto, as carta determines uniqueness of elements,
+// columns which are -1 are missing, and ignored, it is used by generated mappers
+func CellKey(cells []value.Cell, columns []int) string {
+	var b strings.Builder
+	for _, i := range columns {
+		if i != -1 {
+			b.WriteString(cells[i].Uid())
+		}
+	}
+	return b.String()
+}
+
+// EnumValue returns the value of an enum from a cell holding either the name of a value, or a number,
+// values maps the names to values, as generated by protoc-gen-go, names are matched exactly, or in upper case,
+// it is used by generated mappers
+func EnumValue(cell *value.Cell, values map[string]int32) (int32, error) {
+	return enumValue(cell, values, EnumError)
+}
+
+// LoadError returns the error of loading the cell of the column onto a field, as returned by carta.Map,
+// dst is a pointer to the field, and fieldPath its path, ie, "Posts.Title", values which overflow the field return an OverflowError,
+// it is used by generated mappers
+func LoadError(column string, fieldPath string, dst interface{}, row int, cell *value.Cell, err error) error {
+	if e, ok := err.(*value.ConversionError); ok {
+		err = e.Err
+	}
+	if e, ok := err.(*value.OverflowError); ok {
+		path := strings.Split(fieldPath, ".")
+		return Overflow(column, path[len(path)-1], dst, e.Value)
+	}
+	typ := reflect.TypeOf(dst).Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	e := &MappingError{
+		Column:    column,
+		FieldPath: fieldPath,
+		DestType:  typ,
+		Row:       row,
+		Err:       err,
+	}
+	if v := cell.Value(); v != nil {
+		e.SourceType = reflect.TypeOf(v)
+	}
+	return e
+}
+
+// NullError returns the error of loading a null cell onto a field which is not a pointer, as LoadError does
+func NullError(column string, fieldPath string, dst interface{}, row int, cell *value.Cell) error {
+	return LoadError(column, fieldPath, dst, row, cell, errors.New("cannot load null value"))
+}
+
+// Overflow returns the error of loading a value which overflows the field dst points to, as returned by carta.Map,
+// field is the path of the field, ie, "Posts.Id", and v the value as it was read from the cell, ie, an int64, it is used by generated mappers
+func Overflow(column string, field string, dst interface{}, v interface{}) error {
+	typ := reflect.TypeOf(dst).Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return &OverflowError{Column: column, Field: field, Value: v, Type: typ}
+}
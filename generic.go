@@ -0,0 +1,91 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MapAll maps rows onto a new slice of T, which is a struct, a pointer to a struct, or a basic type, example:
+//
+//	blogs, err := carta.MapAll[Blog](rows)
+func MapAll[T any](rows RowsSource, opts ...Option) ([]T, error) {
+	dst := []T{}
+	if err := Map(rows, &dst, opts...); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// MapOne maps rows onto exactly one T, which is a struct, a pointer to a struct, or a basic type, example:
+//
+//	blog, err := carta.MapOne[Blog](rows)
+//
+// returns ErrNoRows if there are no rows, and ErrTooManyRows if rows map onto more than one T after removing duplicates
+func MapOne[T any](rows RowsSource, opts ...Option) (T, error) {
+	var one T
+	all, err := MapAll[T](rows, opts...)
+	if err != nil {
+		return one, err
+	}
+	switch len(all) {
+	case 0:
+		return one, ErrNoRows
+	case 1:
+		return all[0], nil
+	}
+	return one, ErrTooManyRows
+}
+
+// MapColumn maps a column onto a new slice of T, which is a basic type, ie, int64 or string, example:
+//
+//	ids, err := carta.MapColumn[int64](rows)
+//
+// the result must have a single column, unless the column is set with WithColumn, rows are not deduplicated
+func MapColumn[T any](rows RowsSource, opts ...Option) ([]T, error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); !isBasicType(t) {
+		rows.Close()
+		return nil, errorf(ErrInvalidDestination, "carta: cannot map a column onto %s, which is not a basic type", t)
+	}
+	return MapAll[T](rows, opts...)
+}
+
+// pair of columns loaded with MapPairs
+type pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// MapPairs maps a result of two columns, a key and a value, onto a new map, ie, of lookup tables, example:
+//
+//	names, err := carta.MapPairs[int64, string](rows) // select id, name from country
+//
+// K and V are basic types, the key is the first column and the value is the second, keys must be unique
+func MapPairs[K comparable, V any](rows RowsSource, opts ...Option) (map[K]V, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	if len(columns) != 2 {
+		rows.Close()
+		return nil, fmt.Errorf("carta: cannot map %d columns onto pairs, the result must have a key and a value column", len(columns))
+	}
+	for _, t := range []reflect.Type{reflect.TypeOf((*K)(nil)).Elem(), reflect.TypeOf((*V)(nil)).Elem()} {
+		if !isBasicType(t) {
+			rows.Close()
+			return nil, errorf(ErrInvalidDestination, "carta: cannot map a column onto %s, which is not a basic type", t)
+		}
+	}
+	pairs, err := MapAll[pair[K, V]](rows, append(append([]Option{}, opts...), WithOrdinal(), WithoutDedup())...)
+	if err != nil {
+		return nil, err
+	}
+	dst := make(map[K]V, len(pairs))
+	for _, p := range pairs {
+		if _, ok := dst[p.Key]; ok {
+			return nil, fmt.Errorf("carta: duplicate key %v in column %s, keys must be unique", p.Key, columns[0])
+		}
+		dst[p.Key] = p.Value
+	}
+	return dst, nil
+}
@@ -0,0 +1,124 @@
+package carta_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestMapAll(t *testing.T) {
+	entities, err := carta.MapAll[streamed](newIdRows(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 3 || entities[2].Id != 2 {
+		t.Fatalf("expected 3 entities, got %+v", entities)
+	}
+	pointers, err := carta.MapAll[*streamed](newIdRows(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers) != 2 || pointers[1].Id != 1 {
+		t.Fatalf("expected 2 pointers, got %+v", pointers)
+	}
+	ids, err := carta.MapAll[int64](newIdRows(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[1] != 1 {
+		t.Fatalf("expected 2 ids, got %v", ids)
+	}
+	if empty, err := carta.MapAll[streamed](newIdRows(0)); err != nil || empty == nil || len(empty) != 0 {
+		t.Fatalf("expected an empty slice without rows, got %#v, %v", empty, err)
+	}
+}
+
+func TestMapOne(t *testing.T) {
+	one, err := carta.MapOne[streamed](&idRows{ids: []int64{7, 7}, closed: make(chan struct{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if one.Id != 7 {
+		t.Fatalf("expected entity 7, got %+v", one)
+	}
+	if _, err := carta.MapOne[streamed](newIdRows(0)); !errors.Is(err, carta.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+	if _, err := carta.MapOne[streamed](newIdRows(2)); !errors.Is(err, carta.ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows, got %v", err)
+	}
+}
+
+func TestMapSingle(t *testing.T) {
+	one := streamed{}
+	if err := carta.MapSingle(&idRows{ids: []int64{7, 7}, closed: make(chan struct{})}, &one); err != nil {
+		t.Fatal(err)
+	}
+	if one.Id != 7 {
+		t.Fatalf("expected entity 7, got %+v", one)
+	}
+	// dst is only set on success
+	one = streamed{Id: -1}
+	if err := carta.MapSingle(newIdRows(0), &one); !errors.Is(err, carta.ErrNoRows) || one.Id != -1 {
+		t.Fatalf("expected ErrNoRows, got %v, %+v", err, one)
+	}
+	if err := carta.MapSingle(newIdRows(2), &one); !errors.Is(err, carta.ErrTooManyRows) || one.Id != -1 {
+		t.Fatalf("expected ErrTooManyRows, got %v, %+v", err, one)
+	}
+	rows := newIdRows(1)
+	if err := carta.MapSingle(rows, &[]streamed{}); !errors.Is(err, carta.ErrInvalidDestination) {
+		t.Fatalf("expected ErrInvalidDestination for a slice, got %v", err)
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected the rows to be closed")
+	}
+}
+
+// replayed rows of countries, of an integer and a text column
+func countryRows(t *testing.T, columns, rows string) carta.RowsSource {
+	t.Helper()
+	src, err := carta.ReplayRows(strings.NewReader(`{"columns": ` + columns + `, "types": ["INT", "TEXT"], "rows": ` + rows + `}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestMapPairs(t *testing.T) {
+	rows := countryRows(t, `["id", "name"]`, `[[{"int": 1}, {"string": "France"}], [{"int": 2}, {"string": "Peru"}], [{"int": 3}, null]]`)
+	// options of the caller have room to grow, which must not be written to
+	opts := make([]carta.Option, 1, 3)
+	opts[0] = carta.WithCache(carta.NewCache())
+	names, err := carta.MapPairs[int64, *string](rows, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || *names[1] != "France" || *names[2] != "Peru" || names[3] != nil {
+		t.Fatalf("expected 3 countries, got %v", names)
+	}
+	if opts[:3][1] != nil || opts[:3][2] != nil {
+		t.Fatal("expected the options of the caller to be left unchanged")
+	}
+}
+
+func TestMapPairsDuplicateKey(t *testing.T) {
+	rows := countryRows(t, `["id", "name"]`, `[[{"int": 1}, {"string": "France"}], [{"int": 1}, {"string": "Peru"}]]`)
+	_, err := carta.MapPairs[int64, string](rows)
+	if err == nil || !strings.Contains(err.Error(), "duplicate key 1 in column id") {
+		t.Fatalf("expected an error of the duplicate key, got %v", err)
+	}
+}
+
+func TestMapPairsInvalid(t *testing.T) {
+	if _, err := carta.MapPairs[int64, string](newIdRows(1)); err == nil {
+		t.Error("expected an error mapping a single column onto pairs")
+	}
+	rows := countryRows(t, `["id", "name"]`, `[]`)
+	if _, err := carta.MapPairs[int64, streamed](rows); !errors.Is(err, carta.ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination for values which are structs, got %v", err)
+	}
+}
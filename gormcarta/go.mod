@@ -0,0 +1,15 @@
+module github.com/jackskj/carta/gormcarta
+
+go 1.18
+
+require (
+	github.com/jackskj/carta v0.5.0
+	gorm.io/gorm v1.25.10
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	google.golang.org/protobuf v1.23.0 // indirect
+)
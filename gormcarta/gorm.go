@@ -0,0 +1,94 @@
+// Package gormcarta maps raw queries of GORM onto nested structs and slices with carta, for the joins which GORM's preloading
+// does not express well, while keeping GORM for the rest, example:
+//
+//	blogs := []Blog{}
+//	err := gormcarta.Raw(ctx, db, &blogs, "select * from blogs left join posts on posts.blog_id = blogs.id where blogs.author_id = ?", authorId)
+//
+// Fields match columns as they do in GORM, by the column of their "gorm" tags, ie, `gorm:"column:title"`,
+// or by the column name the naming strategy of the *gorm.DB derives from the name of the field, fields tagged with `gorm:"-"` are not loaded.
+// Fields of nested structs also match columns prefixed with the names of their ancestors, as in carta, ie, "posts_title",
+// prefixes of embedded structs, ie, `gorm:"embedded;embeddedPrefix:author_"`, are not applied.
+// It is a module of its own, so that carta does not depend on GORM.
+package gormcarta
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackskj/carta"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Raw runs the query with values on db, as db.Raw(query, values...).Rows() does, and maps the rows onto dst with carta,
+// dst is any destination of carta.Map, ie, a pointer to a slice of structs with nested slices
+func Raw(ctx context.Context, db *gorm.DB, dst interface{}, query string, values ...interface{}) error {
+	rows, err := db.WithContext(ctx).Raw(query, values...).Rows()
+	if err != nil {
+		return err
+	}
+	return carta.MapContext(ctx, rows, dst, Options(db)...)
+}
+
+// Map maps rows onto dst as carta.Map does, matching fields with columns as db does, ie, rows of db.Raw(...).Rows(), rows are closed
+func Map(db *gorm.DB, rows *sql.Rows, dst interface{}, opts ...carta.Option) error {
+	return carta.Map(rows, dst, append(Options(db), opts...)...)
+}
+
+// Options are the options of carta which match fields with columns as db does, to be passed to other functions of carta,
+// ie, carta.MapAll[Blog](rows, gormcarta.Options(db)...).
+// Mappers are cached by the naming strategy of db, strategies which are not comparable, ie, structs holding maps or functions,
+// cannot identify the mappers planned with them, so that a new mapper is planned on every call
+func Options(db *gorm.DB) []carta.Option {
+	var namer schema.Namer = schema.NamingStrategy{}
+	if db.Config != nil && db.NamingStrategy != nil {
+		namer = db.NamingStrategy
+	}
+	opts := []carta.Option{carta.WithFieldNameResolver(FieldNameResolver(namer))}
+	if key, ok := namerKey(namer); ok {
+		opts = append(opts, carta.WithCacheKey(key))
+	}
+	return opts
+}
+
+// key identifying the columns which the resolver of the naming strategy matches, if the strategy is comparable
+func namerKey(namer schema.Namer) (string, bool) {
+	t := reflect.TypeOf(namer)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !t.Comparable() {
+		return "", false
+	}
+	return fmt.Sprintf("gormcarta:%T%#v", namer, namer), true
+}
+
+// FieldNameResolver returns a resolver of carta which matches fields with columns as GORM does with the naming strategy,
+// by the column of their "gorm" tags, or by the column the strategy derives from the name of the field.
+// Fields of nested structs also match columns prefixed with the names of their ancestors, as they do in carta, ie, "posts_title"
+func FieldNameResolver(namer schema.Namer) carta.FieldNameResolver {
+	return func(field reflect.StructField, parent string) []string {
+		settings := schema.ParseTagSetting(field.Tag.Get("gorm"), ";")
+		if _, ok := settings["-"]; ok {
+			return nil
+		}
+		name := field.Name
+		names := []string{namer.ColumnName("", name), strings.ToLower(name)}
+		if column, ok := settings["COLUMN"]; ok && column != "" {
+			name = column
+			names = []string{column}
+		}
+		if parent == "" {
+			return names
+		}
+		ancestors := strings.Split(parent, ".")
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			name = ancestors[i] + "_" + name
+			names = append(names, name, strings.ToLower(name), namer.ColumnName("", name))
+		}
+		return names
+	}
+}
@@ -0,0 +1,75 @@
+package gormcarta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/gormcarta"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type post struct {
+	PostId    int
+	PostTitle string `gorm:"column:title"`
+}
+
+type blog struct {
+	BlogId    int
+	BlogTitle string
+	Posts     []post
+}
+
+func TestNestedColumns(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+	columns := []string{"blog_id", "blog_title", "posts_post_id", "posts_title"}
+	rows := [][]interface{}{{1, "first", 10, "a"}, {1, "first", 11, "b"}}
+	blogs := []blog{}
+	if err := carta.MapValues(columns, rows, &blogs, gormcarta.Options(db)...); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || len(blogs[0].Posts) != 2 || blogs[0].Posts[1].PostId != 11 || blogs[0].Posts[1].PostTitle != "b" {
+		t.Fatalf("prefixed columns did not match fields of nested structs, got %+v", blogs)
+	}
+}
+
+func TestNamingStrategies(t *testing.T) {
+	snake := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+	exact := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{NoLowerCase: true}}}
+	columns := []string{"blog_id", "BlogTitle"}
+	rows := [][]interface{}{{1, "first"}}
+	for _, test := range []struct {
+		name   string
+		db     *gorm.DB
+		expect blog
+	}{
+		{"snake case", snake, blog{BlogId: 1}},
+		{"no lower case", exact, blog{BlogTitle: "first"}},
+		{"snake case again", snake, blog{BlogId: 1}},
+	} {
+		blogs := []blog{}
+		if err := carta.MapValues(columns, rows, &blogs, gormcarta.Options(test.db)...); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if len(blogs) != 1 || blogs[0].BlogId != test.expect.BlogId || blogs[0].BlogTitle != test.expect.BlogTitle {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expect, blogs)
+		}
+	}
+}
+
+func TestOptionsCached(t *testing.T) {
+	cache := carta.NewCache()
+	columns := []string{"blog_id", "BlogTitle"}
+	rows := [][]interface{}{{1, "first"}}
+	for _, strategy := range []schema.NamingStrategy{{}, {}, {NoLowerCase: true}} {
+		db := &gorm.DB{Config: &gorm.Config{NamingStrategy: strategy}}
+		blogs := []blog{}
+		if err := carta.MapValues(columns, rows, &blogs, append(gormcarta.Options(db), carta.WithCache(cache))...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 || len(stats.Entries) != 2 {
+		t.Fatalf("expected a mapper cached for each naming strategy, got %d hits, %d misses and %d entries",
+			stats.Hits, stats.Misses, len(stats.Entries))
+	}
+}
@@ -0,0 +1,41 @@
+package carta
+
+import "reflect"
+
+// AfterMapper is implemented by destination types, ie, with a pointer receiver, AfterMap is called on every entity
+// of the destination once it is fully mapped, including its nested structs and slices, ie, to compute derived fields
+// or to validate invariants, mapping fails with the returned error
+type AfterMapper interface {
+	AfterMap() error
+}
+
+var afterMapperType = reflect.TypeOf((*AfterMapper)(nil)).Elem()
+
+// WithAfterMap calls fn with a pointer to every entity of the destination once it is fully mapped,
+// after its AfterMap method, if any, mapping fails with the returned error
+func WithAfterMap(fn func(entity interface{}) error) Option {
+	return func(o *options) {
+		o.afterMap = fn
+	}
+}
+
+// calls the hooks with a pointer to a mapped entity
+func afterMap(entity reflect.Value, isAfterMapper bool, o *options) error {
+	if isAfterMapper {
+		if err := entity.Interface().(AfterMapper).AfterMap(); err != nil {
+			return err
+		}
+	}
+	if o.afterMap != nil {
+		return o.afterMap(entity.Interface())
+	}
+	return nil
+}
+
+// Initializer is implemented by types of destinations and of their nested structs, ie, with a pointer receiver,
+// Init is called on every newly allocated struct before any of its fields are loaded, ie, to set defaults
+type Initializer interface {
+	Init()
+}
+
+var initializerType = reflect.TypeOf((*Initializer)(nil)).Elem()
@@ -0,0 +1,149 @@
+package carta_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+var errInvalidBlog = errors.New("invalid blog")
+
+type hookedPost struct {
+	PostId int
+}
+
+type hookedBlog struct {
+	BlogId    int
+	Posts     []hookedPost
+	PostCount int      // derived from posts, once they are mapped
+	calls     []string // hooks called on the blog, in order
+}
+
+func (b *hookedBlog) AfterMap() error {
+	if b.BlogId < 0 {
+		return errInvalidBlog
+	}
+	b.PostCount = len(b.Posts)
+	b.calls = append(b.calls, "AfterMap")
+	return nil
+}
+
+func TestAfterMap(t *testing.T) {
+	columns := []string{"blog_id", "post_id"}
+	rows := [][]interface{}{{int64(1), int64(1)}, {int64(1), int64(2)}, {int64(2), int64(3)}}
+	var seen []int
+	hook := carta.WithAfterMap(func(entity interface{}) error {
+		b := entity.(*hookedBlog)
+		b.calls = append(b.calls, "WithAfterMap")
+		seen = append(seen, b.BlogId)
+		return nil
+	})
+	blogs := []hookedBlog{}
+	if err := carta.MapValues(columns, rows, &blogs, hook); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 || blogs[0].PostCount != 2 || blogs[1].PostCount != 1 {
+		t.Fatalf("expected derived fields computed from fully mapped blogs, got %+v", blogs)
+	}
+	for _, b := range blogs {
+		if len(b.calls) != 2 || b.calls[0] != "AfterMap" || b.calls[1] != "WithAfterMap" {
+			t.Errorf("expected AfterMap to be called before the hook of WithAfterMap, got %v", b.calls)
+		}
+	}
+	if len(seen) != 2 || seen[0] != 1 || seen[1] != 2 {
+		t.Errorf("expected the hook to be called with every blog in order, got %v", seen)
+	}
+
+	// hooks are called on pointers to the entities of pointer destinations
+	pointers := []*hookedBlog{}
+	if err := carta.MapValues(columns, rows, &pointers); err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers) != 2 || pointers[0].PostCount != 2 || len(pointers[0].calls) != 1 {
+		t.Fatalf("expected AfterMap to be called on the pointers, got %+v", pointers)
+	}
+}
+
+func TestAfterMapError(t *testing.T) {
+	columns := []string{"blog_id", "post_id"}
+	blogs := []hookedBlog{}
+	err := carta.MapValues(columns, [][]interface{}{{int64(1), int64(1)}, {int64(-1), int64(2)}}, &blogs)
+	if !errors.Is(err, errInvalidBlog) {
+		t.Fatalf("expected the error of AfterMap, got %v", err)
+	}
+
+	errHook := errors.New("rejected")
+	calls := 0
+	hook := carta.WithAfterMap(func(entity interface{}) error {
+		calls++
+		return errHook
+	})
+	blogs = []hookedBlog{}
+	err = carta.MapValues(columns, [][]interface{}{{int64(1), int64(1)}, {int64(2), int64(2)}}, &blogs, hook)
+	if !errors.Is(err, errHook) {
+		t.Fatalf("expected the error of the hook, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected mapping to stop at the first error, the hook was called %d times", calls)
+	}
+}
+
+type initializedComment struct {
+	CommentId int
+	Status    *string
+}
+
+func (c *initializedComment) Init() {
+	status := "visible"
+	c.Status = &status
+}
+
+type initializedAuthor struct {
+	AuthorId int
+	Name     *string
+}
+
+func (a *initializedAuthor) Init() {
+	name := "anonymous"
+	a.Name = &name
+}
+
+type initializedPost struct {
+	PostId   int
+	Author   *initializedAuthor
+	Comments []initializedComment
+	inits    int
+}
+
+func (p *initializedPost) Init() {
+	p.inits++
+}
+
+func TestInitializer(t *testing.T) {
+	columns := []string{"post_id", "author_id", "name", "comment_id", "status"}
+	rows := [][]interface{}{
+		{int64(1), int64(1), nil, int64(1), nil},
+		{int64(1), int64(1), nil, int64(2), "hidden"},
+		{int64(2), int64(2), "ann", int64(3), nil},
+	}
+	posts := []initializedPost{}
+	if err := carta.MapValues(columns, rows, &posts); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || posts[0].inits != 1 || posts[1].inits != 1 {
+		t.Fatalf("expected Init to be called once on every post, got %+v", posts)
+	}
+	first := posts[0]
+	if first.Author == nil || first.Author.Name == nil || *first.Author.Name != "anonymous" {
+		t.Errorf("expected the default name of the author, got %+v", first.Author)
+	}
+	if len(first.Comments) != 2 || first.Comments[0].Status == nil || *first.Comments[0].Status != "visible" ||
+		first.Comments[1].Status == nil || *first.Comments[1].Status != "hidden" {
+		t.Errorf("expected defaults of comments to be set before columns are loaded, got %+v", first.Comments)
+	}
+	second := posts[1]
+	if second.Author == nil || second.Author.Name == nil || *second.Author.Name != "ann" {
+		t.Errorf("expected the loaded name of the author, got %+v", second.Author)
+	}
+}
@@ -0,0 +1,421 @@
+// Package gen emits mappers which load rows onto structs without reflection, for protoc-gen-carta and carta-gen,
+// which describe the structs as entities. Columns match fields as they match them with the default options of carta.Map,
+// and generated mappers call carta.Map when options are passed, or when the struct cannot be described as an entity.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/internal/names"
+)
+
+const (
+	cartaPackage = "github.com/jackskj/carta"
+	valuePackage = "github.com/jackskj/carta/value"
+)
+
+// Ident is a go identifier, ImportPath is empty for identifiers of the package code is generated for
+type Ident struct {
+	ImportPath string
+	Name       string
+}
+
+// Qualifier returns the identifier as it is referenced in generated code, importing its package
+type Qualifier func(id Ident) string
+
+// Kind is the kind of a basic field, which determines how it is loaded from a cell
+type Kind int
+
+const (
+	Bool Kind = iota
+	Int
+	Int32
+	Int64
+	Uint32
+	Uint64
+	Float32
+	Float64
+	String
+	Bytes
+	Time
+	Timestamp // *timestamppb.Timestamp
+	Enum      // loaded with the map of names to values of the enum
+)
+
+// Entity describes a struct which rows are mapped onto
+type Entity struct {
+	Type   Ident
+	Fields []*Field  // basic fields, which are loaded from columns
+	Nested []*Nested // nested structs, which are associations, or collections
+}
+
+// Field is a basic field of an entity
+type Field struct {
+	Name   string // name of the field in go
+	Column string // name the columns are matched with, ie, the name set with the tag, or Name
+	Kind   Kind
+	Type   Ident // type of the field, or of the element of pointers, the loaded value is converted to it unless empty
+	Ptr    bool  // pointer fields are left nil when columns are null
+	Key    bool  // uniqueness of elements is determined only by key fields, if there are any
+	Values Ident // map of names to values of enums
+}
+
+// Nested is a field of an entity which is a struct, a pointer to a struct, or a slice of either
+type Nested struct {
+	Name       string
+	Column     string // name of the field as an ancestor of fields of the entity
+	Collection bool
+	Ptr        bool // the field, or elements of collections, are pointers
+	Entity     *Entity
+}
+
+// Func describes a generated function mapping rows onto a slice of an entity, ie, MapBlog(rows) ([]*Blog, error)
+type Func struct {
+	Name     string
+	Doc      string // doc comment, without the slashes
+	Type     Ident  // type of the elements of the slice
+	Ptr      bool   // elements are pointers
+	Entity   *Entity
+	Fallback bool // rows are always mapped with carta.Map, ie, for structs which cannot be described as entities
+}
+
+// Generate returns the code of the function
+func Generate(fn *Func, q Qualifier) []byte {
+	g := &generator{q: q, fn: fn}
+	g.function(fn)
+	return g.buf.Bytes()
+}
+
+// node of the tree of entities of a function, in the order entities claim columns
+type node struct {
+	n        int
+	entity   *Entity
+	parent   *node
+	nested   *Nested // field of the parent, nil for the root
+	path     string  // path of the entity in go, ie, "Posts"
+	fields   []int   // indexes of the columns of the fields in cols
+	keys     []int   // indexes of the key fields
+	children []*node // nodes of the nested entities
+}
+
+type generator struct {
+	q     Qualifier
+	fn    *Func
+	buf   bytes.Buffer
+	nodes []*node
+	names [][]string // names of columns matching every field, indexed as cols
+	cases []string   // quoted names claimed by every field, columns claimed by earlier fields are not claimed again
+	paths []string   // path of every field
+}
+
+func (g *generator) p(format string, a ...interface{}) {
+	fmt.Fprintf(&g.buf, format, a...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) carta(name string) string {
+	return g.q(Ident{cartaPackage, name})
+}
+
+func (g *generator) elemType(fn *Func) string {
+	if fn.Ptr {
+		return "*" + g.q(fn.Type)
+	}
+	return g.q(fn.Type)
+}
+
+func (g *generator) function(fn *Func) {
+	for _, line := range strings.Split(fn.Doc, "\n") {
+		g.p("// %s", line)
+	}
+	g.p("func %s(rows %s, opts ...%s) ([]%s, error) {", fn.Name, g.carta("RowsSource"), g.carta("Option"), g.elemType(fn))
+	// no rows map onto an empty slice, as they do with carta.Map
+	g.p("dst := []%s{}", g.elemType(fn))
+	if fn.Fallback {
+		g.p("err := %s(rows, &dst, opts...)", g.carta("Map"))
+		g.p("return dst, err")
+		g.p("}")
+		return
+	}
+	g.p("if len(opts) != 0 {")
+	g.p("err := %s(rows, &dst, opts...)", g.carta("Map"))
+	g.p("return dst, err")
+	g.p("}")
+	g.p("columns, err := rows.Columns()")
+	g.p("if err != nil {")
+	g.p("return nil, err")
+	g.p("}")
+
+	g.visit(&node{entity: fn.Entity}, nil)
+	claimed := map[string]bool{}
+	for _, names := range g.names {
+		var unclaimed []string
+		for _, name := range names {
+			if !claimed[name] {
+				claimed[name] = true
+				unclaimed = append(unclaimed, strconv.Quote(name))
+			}
+		}
+		g.cases = append(g.cases, strings.Join(unclaimed, ", "))
+	}
+
+	g.p("var cols [%d]int // columns of the fields, -1 if missing", len(g.cases))
+	g.p("for i := range cols {")
+	g.p("cols[i] = -1")
+	g.p("}")
+	if len(claimed) != 0 {
+		g.p("for i, column := range columns {")
+		g.p("f := -1")
+		g.p("switch column {")
+		for i, c := range g.cases {
+			if c != "" {
+				g.p("case %s:", c)
+				g.p("f = %d", i)
+			}
+		}
+		g.p("}")
+		g.p("if f != -1 && cols[f] == -1 {")
+		g.p("cols[f] = i")
+		g.p("}")
+		g.p("}")
+	}
+	for _, nd := range g.nodes {
+		g.p("keys%d := []int{%s} // columns determining uniqueness of %s", nd.n, g.colsOf(nd.keys), g.q(nd.entity.Type))
+		if nd.nested != nil && !nd.nested.Collection {
+			g.p("seen%d := map[string]bool{}", nd.n)
+			g.p("current%d := map[string]string{} // keys of the last element of the association, by the keys of the parents", nd.n)
+		} else {
+			g.p("seen%d := map[string]int{}", nd.n)
+		}
+	}
+	g.p("err = %s(rows, columns, func(row int, cells []%s) error {", g.carta("ScanCells"), g.q(Ident{valuePackage, "Cell"}))
+	g.element(g.nodes[0])
+	g.p("return nil")
+	g.p("})")
+	g.p("if err != nil {")
+	g.p("return nil, err")
+	g.p("}")
+	g.p("return dst, nil")
+	g.p("}")
+}
+
+// numbers the nodes of the tree, and the fields of their entities, depth first, as carta allocates columns
+func (g *generator) visit(nd *node, ancestors []string) {
+	nd.n = len(g.nodes)
+	g.nodes = append(g.nodes, nd)
+	for _, f := range nd.entity.Fields {
+		i := len(g.names)
+		g.names = append(g.names, Candidates(f.Column, ancestors))
+		g.paths = append(g.paths, joinPath(nd.path, f.Name))
+		nd.fields = append(nd.fields, i)
+		if f.Key {
+			nd.keys = append(nd.keys, i)
+		}
+	}
+	if len(nd.keys) == 0 {
+		nd.keys = nd.fields
+	}
+	for _, nested := range nd.entity.Nested {
+		child := &node{entity: nested.Entity, parent: nd, nested: nested, path: joinPath(nd.path, nested.Name)}
+		nd.children = append(nd.children, child)
+		g.visit(child, append(append([]string{}, ancestors...), nested.Column))
+	}
+}
+
+func (g *generator) colsOf(fields []int) string {
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = fmt.Sprintf("cols[%d]", f)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// loads the element of the node from the row, and the elements of its nested entities, elements of collections are created
+// unless the row maps onto an existing element, associations are replaced when the row maps onto another element,
+// rows of replaced elements are then skipped, since carta.Map sets associations to the last element their rows map onto
+func (g *generator) element(nd *node) {
+	n := nd.n
+	switch {
+	case nd.parent == nil:
+		g.p("key%d := %s(cells, keys%d)", n, g.carta("CellKey"), n)
+		g.p("i%d, found := seen%d[key%d]", n, n, n)
+		g.p("new%d := !found", n)
+		g.p("if new%d {", n)
+		g.p("i%d = len(dst)", n)
+		g.p("dst = append(dst, %s)", g.newElem(nd.entity, g.fn.Ptr))
+		g.p("seen%d[key%d] = i%d", n, n, n)
+		g.p("}")
+		if g.usesElem(nd) {
+			g.p("e%d := %sdst[i%d]", n, g.addr(g.fn.Ptr), n)
+		}
+	case nd.nested.Collection:
+		p, field := nd.parent.n, nd.nested.Name
+		g.p("key%d := key%d + \"\\x00\" + %s(cells, keys%d)", n, p, g.carta("CellKey"), n)
+		g.p("i%d, found := seen%d[key%d]", n, n, n)
+		g.p("new%d := !found", n)
+		g.p("if new%d {", n)
+		g.p("i%d = len(e%d.%s)", n, p, field)
+		g.p("e%d.%s = append(e%d.%s, %s)", p, field, p, field, g.newElem(nd.entity, nd.nested.Ptr))
+		g.p("seen%d[key%d] = i%d", n, n, n)
+		g.p("}")
+		if g.usesElem(nd) {
+			g.p("e%d := %se%d.%s[i%d]", n, g.addr(nd.nested.Ptr), p, field, n)
+		}
+	default:
+		p, field := nd.parent.n, nd.nested.Name
+		g.p("key%d := key%d + \"\\x00\" + %s(cells, keys%d)", n, p, g.carta("CellKey"), n)
+		g.p("new%d := !seen%d[key%d]", n, n, n)
+		g.p("if new%d {", n)
+		g.p("seen%d[key%d] = true", n, n)
+		g.p("current%d[key%d] = key%d", n, p, n)
+		g.p("e%d.%s = %s", p, field, g.newElem(nd.entity, nd.nested.Ptr))
+		g.p("}")
+		g.p("if current%d[key%d] == key%d {", n, p, n)
+		if g.usesElem(nd) {
+			g.p("e%d := %se%d.%s", n, g.addr(nd.nested.Ptr), p, field)
+		}
+	}
+	if len(nd.fields) != 0 {
+		g.p("if new%d {", n)
+		for i, f := range nd.entity.Fields {
+			g.field(nd, f, nd.fields[i])
+		}
+		g.p("}")
+	}
+	for _, child := range nd.children {
+		g.element(child)
+	}
+	if nd.parent != nil && !nd.nested.Collection {
+		g.p("}")
+	}
+}
+
+// elements are referenced by their fields, and by their nested entities
+func (g *generator) usesElem(nd *node) bool {
+	return len(nd.fields) != 0 || len(nd.entity.Nested) != 0
+}
+
+func (g *generator) addr(ptr bool) string {
+	if ptr {
+		return ""
+	}
+	return "&"
+}
+
+func (g *generator) newElem(e *Entity, ptr bool) string {
+	if ptr {
+		return "&" + g.q(e.Type) + "{}"
+	}
+	return g.q(e.Type) + "{}"
+}
+
+// loads the field of the element of the node from the cell of its column, unless the column is missing
+func (g *generator) field(nd *node, f *Field, i int) {
+	dst := fmt.Sprintf("e%d.%s", nd.n, f.Name)
+	errArgs := fmt.Sprintf("columns[c], %q, &%s, row, &cells[c]", g.paths[i], dst)
+	g.p("if c := cols[%d]; c != -1 {", i)
+	// pointers are left nil, and booleans false, as they are by carta.Map, other fields fail the mapping
+	nullable := f.Ptr || f.Kind == Timestamp || f.Kind == Bool
+	if nullable {
+		g.p("if !cells[c].IsNull() {")
+	} else {
+		g.p("if cells[c].IsNull() {")
+		g.p("return %s(%s)", g.carta("NullError"), errArgs)
+		g.p("}")
+	}
+	g.p("v, err := %s", g.getter(f))
+	g.p("if err != nil {")
+	g.p("return %s(%s, err)", g.carta("LoadError"), errArgs)
+	g.p("}")
+	conv := g.conversion(f)
+	// narrower numbers are loaded from 64 bit values, and fail the mapping if they overflow the field, as they do with carta.Map
+	switch f.Kind {
+	case Int, Int32:
+		g.p("if int64(%s(v)) != v {", conv)
+	case Uint32:
+		g.p("if uint64(%s(v)) != v {", conv)
+	case Float32:
+		g.p("if a := %s(v); a > %s && a <= %s {", g.q(Ident{"math", "Abs"}), g.q(Ident{"math", "MaxFloat32"}), g.q(Ident{"math", "MaxFloat64"}))
+	}
+	switch f.Kind {
+	case Int, Int32, Uint32, Float32:
+		g.p("return %s(columns[c], %q, &%s, v)", g.carta("Overflow"), g.paths[i], dst)
+		g.p("}")
+	}
+	v := "v"
+	switch {
+	case f.Kind == Timestamp:
+		v = fmt.Sprintf("&%s{Seconds: v.Unix(), Nanos: int32(v.Nanosecond())}", g.q(f.Type))
+	case conv != "":
+		v = fmt.Sprintf("%s(v)", conv)
+	}
+	if f.Ptr && f.Kind != Timestamp && v != "v" {
+		g.p("x := %s", v)
+		v = "&x"
+	} else if f.Ptr && f.Kind != Timestamp {
+		v = "&v"
+	}
+	g.p("%s = %s", dst, v)
+	if nullable {
+		g.p("}")
+	}
+	g.p("}")
+}
+
+// type the loaded value is converted to, empty if it is not converted
+func (g *generator) conversion(f *Field) string {
+	switch {
+	case f.Kind == Timestamp:
+		return ""
+	case f.Type.Name != "":
+		return g.q(f.Type)
+	}
+	switch f.Kind {
+	case Int:
+		return "int"
+	case Int32:
+		return "int32"
+	case Uint32:
+		return "uint32"
+	case Float32:
+		return "float32"
+	}
+	return ""
+}
+
+func (g *generator) getter(f *Field) string {
+	switch f.Kind {
+	case Bool:
+		return "cells[c].Bool()"
+	case Int, Int32, Int64:
+		return "cells[c].Int64()"
+	case Uint32, Uint64:
+		return "cells[c].Uint64()"
+	case Float32, Float64:
+		return "cells[c].Float64()"
+	case Bytes:
+		return "cells[c].Bytes()"
+	case Time, Timestamp:
+		return "cells[c].Time()"
+	case Enum:
+		return fmt.Sprintf("%s(&cells[c], %s)", g.carta("EnumValue"), g.q(f.Values))
+	}
+	return "cells[c].String()"
+}
+
+// Candidates returns the names of columns matching a field with the default options of carta.Map, ie, "Title", "title" and "posts_title"
+// for the field Title of an entity nested in the field Posts
+func Candidates(name string, ancestors []string) []string {
+	return names.Rules{Naming: carta.SnakeCase{}.ColumnName}.Candidates(name, ancestors)
+}
+
+func joinPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
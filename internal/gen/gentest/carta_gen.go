@@ -0,0 +1,465 @@
+// Code generated by carta-gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"math"
+
+	"github.com/jackskj/carta"
+	"github.com/jackskj/carta/value"
+)
+
+// MapBlog maps rows onto Blog without reflection, columns match fields as they do with carta.Map,
+// rows are mapped with carta.Map when options are passed
+func MapBlog(rows carta.RowsSource, opts ...carta.Option) ([]Blog, error) {
+	dst := []Blog{}
+	if len(opts) != 0 {
+		err := carta.Map(rows, &dst, opts...)
+		return dst, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var cols [20]int // columns of the fields, -1 if missing
+	for i := range cols {
+		cols[i] = -1
+	}
+	for i, column := range columns {
+		f := -1
+		switch column {
+		case "blog_id":
+			f = 0
+		case "Title", "title":
+			f = 1
+		case "Rating", "rating":
+			f = 2
+		case "Views", "views":
+			f = 3
+		case "Likes", "likes":
+			f = 4
+		case "Status", "status":
+			f = 5
+		case "Published", "published":
+			f = 6
+		case "Created", "created":
+			f = 7
+		case "Subtitle", "subtitle":
+			f = 8
+		case "author_id", "Author_author_id", "author_author_id":
+			f = 9
+		case "Name", "name", "Author_Name", "author_name":
+			f = 10
+		case "tag_id", "Tags_tag_id", "tags_tag_id", "Author_Tags_tag_id", "author_tags_tag_id":
+			f = 11
+		case "tag", "Tags_tag", "tags_tag", "Author_Tags_tag", "author_tags_tag":
+			f = 12
+		case "editor_id", "Editor_editor_id", "editor_editor_id":
+			f = 13
+		case "editor_name", "Editor_editor_name", "editor_editor_name":
+			f = 14
+		case "post_id", "Posts_post_id", "posts_post_id":
+			f = 15
+		case "Posts_Title", "posts_title":
+			f = 16
+		case "Score", "score", "Posts_Score", "posts_score":
+			f = 17
+		case "comment_id", "Comments_comment_id", "comments_comment_id", "Posts_Comments_comment_id", "posts_comments_comment_id":
+			f = 18
+		case "Body", "body", "Comments_Body", "comments_body", "Posts_Comments_Body", "posts_comments_body":
+			f = 19
+		}
+		if f != -1 && cols[f] == -1 {
+			cols[f] = i
+		}
+	}
+	keys0 := []int{cols[0]} // columns determining uniqueness of Blog
+	seen0 := map[string]int{}
+	keys1 := []int{cols[9]} // columns determining uniqueness of Author
+	seen1 := map[string]bool{}
+	current1 := map[string]string{} // keys of the last element of the association, by the keys of the parents
+	keys2 := []int{cols[11]}        // columns determining uniqueness of Tag
+	seen2 := map[string]int{}
+	keys3 := []int{cols[13]} // columns determining uniqueness of Editor
+	seen3 := map[string]bool{}
+	current3 := map[string]string{} // keys of the last element of the association, by the keys of the parents
+	keys4 := []int{cols[15]}        // columns determining uniqueness of Post
+	seen4 := map[string]int{}
+	keys5 := []int{cols[18]} // columns determining uniqueness of Comment
+	seen5 := map[string]int{}
+	err = carta.ScanCells(rows, columns, func(row int, cells []value.Cell) error {
+		key0 := carta.CellKey(cells, keys0)
+		i0, found := seen0[key0]
+		new0 := !found
+		if new0 {
+			i0 = len(dst)
+			dst = append(dst, Blog{})
+			seen0[key0] = i0
+		}
+		e0 := &dst[i0]
+		if new0 {
+			if c := cols[0]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Id", &e0.Id, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Id", &e0.Id, row, &cells[c], err)
+				}
+				if int64(int(v)) != v {
+					return carta.Overflow(columns[c], "Id", &e0.Id, v)
+				}
+				e0.Id = int(v)
+			}
+			if c := cols[1]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Title", &e0.Title, row, &cells[c])
+				}
+				v, err := cells[c].String()
+				if err != nil {
+					return carta.LoadError(columns[c], "Title", &e0.Title, row, &cells[c], err)
+				}
+				e0.Title = v
+			}
+			if c := cols[2]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Rating", &e0.Rating, row, &cells[c])
+				}
+				v, err := cells[c].Float64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Rating", &e0.Rating, row, &cells[c], err)
+				}
+				if a := math.Abs(v); a > math.MaxFloat32 && a <= math.MaxFloat64 {
+					return carta.Overflow(columns[c], "Rating", &e0.Rating, v)
+				}
+				e0.Rating = float32(v)
+			}
+			if c := cols[3]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Views", &e0.Views, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Views", &e0.Views, row, &cells[c], err)
+				}
+				if int64(int32(v)) != v {
+					return carta.Overflow(columns[c], "Views", &e0.Views, v)
+				}
+				e0.Views = int32(v)
+			}
+			if c := cols[4]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Likes", &e0.Likes, row, &cells[c])
+				}
+				v, err := cells[c].Uint64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Likes", &e0.Likes, row, &cells[c], err)
+				}
+				if uint64(uint32(v)) != v {
+					return carta.Overflow(columns[c], "Likes", &e0.Likes, v)
+				}
+				e0.Likes = uint32(v)
+			}
+			if c := cols[5]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Status", &e0.Status, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Status", &e0.Status, row, &cells[c], err)
+				}
+				if int64(Status(v)) != v {
+					return carta.Overflow(columns[c], "Status", &e0.Status, v)
+				}
+				e0.Status = Status(v)
+			}
+			if c := cols[6]; c != -1 {
+				if !cells[c].IsNull() {
+					v, err := cells[c].Bool()
+					if err != nil {
+						return carta.LoadError(columns[c], "Published", &e0.Published, row, &cells[c], err)
+					}
+					e0.Published = v
+				}
+			}
+			if c := cols[7]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Created", &e0.Created, row, &cells[c])
+				}
+				v, err := cells[c].Time()
+				if err != nil {
+					return carta.LoadError(columns[c], "Created", &e0.Created, row, &cells[c], err)
+				}
+				e0.Created = v
+			}
+			if c := cols[8]; c != -1 {
+				if !cells[c].IsNull() {
+					v, err := cells[c].String()
+					if err != nil {
+						return carta.LoadError(columns[c], "Subtitle", &e0.Subtitle, row, &cells[c], err)
+					}
+					e0.Subtitle = &v
+				}
+			}
+		}
+		key1 := key0 + "\x00" + carta.CellKey(cells, keys1)
+		new1 := !seen1[key1]
+		if new1 {
+			seen1[key1] = true
+			current1[key0] = key1
+			e0.Author = &Author{}
+		}
+		if current1[key0] == key1 {
+			e1 := e0.Author
+			if new1 {
+				if c := cols[9]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Author.Id", &e1.Id, row, &cells[c])
+					}
+					v, err := cells[c].Int64()
+					if err != nil {
+						return carta.LoadError(columns[c], "Author.Id", &e1.Id, row, &cells[c], err)
+					}
+					if int64(int(v)) != v {
+						return carta.Overflow(columns[c], "Author.Id", &e1.Id, v)
+					}
+					e1.Id = int(v)
+				}
+				if c := cols[10]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Author.Name", &e1.Name, row, &cells[c])
+					}
+					v, err := cells[c].String()
+					if err != nil {
+						return carta.LoadError(columns[c], "Author.Name", &e1.Name, row, &cells[c], err)
+					}
+					e1.Name = v
+				}
+			}
+			key2 := key1 + "\x00" + carta.CellKey(cells, keys2)
+			i2, found := seen2[key2]
+			new2 := !found
+			if new2 {
+				i2 = len(e1.Tags)
+				e1.Tags = append(e1.Tags, Tag{})
+				seen2[key2] = i2
+			}
+			e2 := &e1.Tags[i2]
+			if new2 {
+				if c := cols[11]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Author.Tags.Id", &e2.Id, row, &cells[c])
+					}
+					v, err := cells[c].Int64()
+					if err != nil {
+						return carta.LoadError(columns[c], "Author.Tags.Id", &e2.Id, row, &cells[c], err)
+					}
+					if int64(int(v)) != v {
+						return carta.Overflow(columns[c], "Author.Tags.Id", &e2.Id, v)
+					}
+					e2.Id = int(v)
+				}
+				if c := cols[12]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Author.Tags.Name", &e2.Name, row, &cells[c])
+					}
+					v, err := cells[c].String()
+					if err != nil {
+						return carta.LoadError(columns[c], "Author.Tags.Name", &e2.Name, row, &cells[c], err)
+					}
+					e2.Name = v
+				}
+			}
+		}
+		key3 := key0 + "\x00" + carta.CellKey(cells, keys3)
+		new3 := !seen3[key3]
+		if new3 {
+			seen3[key3] = true
+			current3[key0] = key3
+			e0.Editor = Editor{}
+		}
+		if current3[key0] == key3 {
+			e3 := &e0.Editor
+			if new3 {
+				if c := cols[13]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Editor.Id", &e3.Id, row, &cells[c])
+					}
+					v, err := cells[c].Int64()
+					if err != nil {
+						return carta.LoadError(columns[c], "Editor.Id", &e3.Id, row, &cells[c], err)
+					}
+					if int64(int(v)) != v {
+						return carta.Overflow(columns[c], "Editor.Id", &e3.Id, v)
+					}
+					e3.Id = int(v)
+				}
+				if c := cols[14]; c != -1 {
+					if cells[c].IsNull() {
+						return carta.NullError(columns[c], "Editor.Name", &e3.Name, row, &cells[c])
+					}
+					v, err := cells[c].String()
+					if err != nil {
+						return carta.LoadError(columns[c], "Editor.Name", &e3.Name, row, &cells[c], err)
+					}
+					e3.Name = v
+				}
+			}
+		}
+		key4 := key0 + "\x00" + carta.CellKey(cells, keys4)
+		i4, found := seen4[key4]
+		new4 := !found
+		if new4 {
+			i4 = len(e0.Posts)
+			e0.Posts = append(e0.Posts, Post{})
+			seen4[key4] = i4
+		}
+		e4 := &e0.Posts[i4]
+		if new4 {
+			if c := cols[15]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Posts.Id", &e4.Id, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Posts.Id", &e4.Id, row, &cells[c], err)
+				}
+				if int64(int(v)) != v {
+					return carta.Overflow(columns[c], "Posts.Id", &e4.Id, v)
+				}
+				e4.Id = int(v)
+			}
+			if c := cols[16]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Posts.Title", &e4.Title, row, &cells[c])
+				}
+				v, err := cells[c].String()
+				if err != nil {
+					return carta.LoadError(columns[c], "Posts.Title", &e4.Title, row, &cells[c], err)
+				}
+				e4.Title = v
+			}
+			if c := cols[17]; c != -1 {
+				if !cells[c].IsNull() {
+					v, err := cells[c].Int64()
+					if err != nil {
+						return carta.LoadError(columns[c], "Posts.Score", &e4.Score, row, &cells[c], err)
+					}
+					e4.Score = &v
+				}
+			}
+		}
+		key5 := key4 + "\x00" + carta.CellKey(cells, keys5)
+		i5, found := seen5[key5]
+		new5 := !found
+		if new5 {
+			i5 = len(e4.Comments)
+			e4.Comments = append(e4.Comments, &Comment{})
+			seen5[key5] = i5
+		}
+		e5 := e4.Comments[i5]
+		if new5 {
+			if c := cols[18]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Posts.Comments.Id", &e5.Id, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Posts.Comments.Id", &e5.Id, row, &cells[c], err)
+				}
+				if int64(int(v)) != v {
+					return carta.Overflow(columns[c], "Posts.Comments.Id", &e5.Id, v)
+				}
+				e5.Id = int(v)
+			}
+			if c := cols[19]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Posts.Comments.Body", &e5.Body, row, &cells[c])
+				}
+				v, err := cells[c].String()
+				if err != nil {
+					return carta.LoadError(columns[c], "Posts.Comments.Body", &e5.Body, row, &cells[c], err)
+				}
+				e5.Body = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// LoadComments maps rows onto Flat without reflection, columns match fields as they do with carta.Map,
+// rows are mapped with carta.Map when options are passed
+func LoadComments(rows carta.RowsSource, opts ...carta.Option) ([]Flat, error) {
+	dst := []Flat{}
+	if len(opts) != 0 {
+		err := carta.Map(rows, &dst, opts...)
+		return dst, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var cols [2]int // columns of the fields, -1 if missing
+	for i := range cols {
+		cols[i] = -1
+	}
+	for i, column := range columns {
+		f := -1
+		switch column {
+		case "comment_id":
+			f = 0
+		case "Body", "body":
+			f = 1
+		}
+		if f != -1 && cols[f] == -1 {
+			cols[f] = i
+		}
+	}
+	keys0 := []int{cols[0], cols[1]} // columns determining uniqueness of Flat
+	seen0 := map[string]int{}
+	err = carta.ScanCells(rows, columns, func(row int, cells []value.Cell) error {
+		key0 := carta.CellKey(cells, keys0)
+		i0, found := seen0[key0]
+		new0 := !found
+		if new0 {
+			i0 = len(dst)
+			dst = append(dst, Flat{})
+			seen0[key0] = i0
+		}
+		e0 := &dst[i0]
+		if new0 {
+			if c := cols[0]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Id", &e0.Id, row, &cells[c])
+				}
+				v, err := cells[c].Int64()
+				if err != nil {
+					return carta.LoadError(columns[c], "Id", &e0.Id, row, &cells[c], err)
+				}
+				if int64(int(v)) != v {
+					return carta.Overflow(columns[c], "Id", &e0.Id, v)
+				}
+				e0.Id = int(v)
+			}
+			if c := cols[1]; c != -1 {
+				if cells[c].IsNull() {
+					return carta.NullError(columns[c], "Body", &e0.Body, row, &cells[c])
+				}
+				v, err := cells[c].String()
+				if err != nil {
+					return carta.LoadError(columns[c], "Body", &e0.Body, row, &cells[c], err)
+				}
+				e0.Body = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
@@ -0,0 +1,155 @@
+package gentest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+)
+
+// rows of values, as carta.MapValues reads them
+type valuesRows struct {
+	columns []string
+	rows    [][]interface{}
+	n       int
+}
+
+func (r *valuesRows) Columns() ([]string, error) { return r.columns, nil }
+func (r *valuesRows) Err() error                 { return nil }
+func (r *valuesRows) Close() error               { return nil }
+
+func (r *valuesRows) Next() bool {
+	if r.n >= len(r.rows) {
+		return false
+	}
+	r.n++
+	return true
+}
+
+func (r *valuesRows) Scan(dest ...interface{}) error {
+	for i, v := range r.rows[r.n-1] {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			return err
+		}
+		if err = dest[i].(sql.Scanner).Scan(dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var blogColumns = []string{
+	"blog_id", "title", "rating", "views", "likes", "status", "published", "created", "subtitle",
+	"author_id", "name", "tag_id", "tag", "editor_id", "editor_name",
+	"post_id", "posts_title", "score", "comment_id", "body",
+}
+
+var created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// a row of blog 1 with the author, editor, post and comment
+func blogRow(author, editor, post, comment int) []interface{} {
+	return []interface{}{
+		1, "blog", 4.5, 10, 20, 2, true, created, nil,
+		author, "author", author * 10, "tag", editor, "editor",
+		post, "post", 7, comment, "comment",
+	}
+}
+
+// compares the mapper generated by carta-gen with carta.Map on the same rows, returns nil if both failed with the same error
+func compare(t *testing.T, columns []string, rows [][]interface{}) []Blog {
+	t.Helper()
+	mapped := []Blog{}
+	mapErr := carta.MapValues(columns, rows, &mapped)
+	generated, genErr := MapBlog(&valuesRows{columns: columns, rows: rows})
+	if mapErr != nil || genErr != nil {
+		if mapErr == nil || genErr == nil || mapErr.Error() != genErr.Error() || reflect.TypeOf(mapErr) != reflect.TypeOf(genErr) {
+			t.Fatalf("generated mapper failed with %v, carta.Map with %v", genErr, mapErr)
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(generated, mapped) {
+		t.Fatalf("generated mapper loaded\n%+v\ncarta.Map loaded\n%+v", generated, mapped)
+	}
+	return mapped
+}
+
+func TestGenerated(t *testing.T) {
+	blogs := compare(t, blogColumns, [][]interface{}{
+		blogRow(1, 1, 1, 1),
+		blogRow(1, 1, 1, 2),
+		blogRow(1, 1, 2, 3),
+		{2, "other", 1.5, 1, 1, 1, false, created, "sub", 3, "other", 30, "tag", 2, "other", 3, "other", nil, 4, "other"},
+	})
+	if len(blogs) != 2 || len(blogs[0].Posts) != 2 || len(blogs[0].Posts[0].Comments) != 2 || blogs[1].Subtitle == nil {
+		t.Fatalf("unexpected blogs %+v", blogs)
+	}
+}
+
+func TestGeneratedAssociation(t *testing.T) {
+	// carta.Map sets associations to the last distinct element their rows map onto
+	blogs := compare(t, blogColumns, [][]interface{}{
+		blogRow(1, 1, 1, 1),
+		blogRow(2, 2, 1, 1),
+		blogRow(1, 1, 2, 2),
+	})
+	if len(blogs) != 1 || blogs[0].Author.Id != 2 || blogs[0].Editor.Id != 2 || len(blogs[0].Author.Tags) != 1 {
+		t.Fatalf("unexpected blogs %+v", blogs)
+	}
+}
+
+func TestGeneratedNoRows(t *testing.T) {
+	blogs, err := MapBlog(&valuesRows{columns: blogColumns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blogs == nil || len(blogs) != 0 {
+		t.Fatalf("expected an empty slice, got %#v", blogs)
+	}
+	compare(t, blogColumns, nil)
+}
+
+func TestGeneratedMissingColumns(t *testing.T) {
+	compare(t, []string{"blog_id", "title", "post_id", "posts_title"}, [][]interface{}{{1, "blog", 1, "post"}, {1, "blog", 2, "post"}})
+}
+
+func TestGeneratedOverflow(t *testing.T) {
+	for name, i := range map[string]int{"views": 3, "likes": 4, "status": 5} {
+		t.Run(name, func(t *testing.T) {
+			row := blogRow(1, 1, 1, 1)
+			row[i] = int64(1) << 40
+			if compare(t, blogColumns, [][]interface{}{row}) != nil {
+				t.Fatal("expected an overflow error")
+			}
+		})
+	}
+	t.Run("negative", func(t *testing.T) {
+		row := blogRow(1, 1, 1, 1)
+		row[4] = -1
+		compare(t, blogColumns, [][]interface{}{row})
+	})
+	t.Run("rating", func(t *testing.T) {
+		row := blogRow(1, 1, 1, 1)
+		row[2] = 1e300
+		compare(t, blogColumns, [][]interface{}{row})
+	})
+	row := blogRow(1, 1, 1, 1)
+	row[3] = int64(1) << 40
+	_, err := MapBlog(&valuesRows{columns: blogColumns, rows: [][]interface{}{row}})
+	if e, ok := err.(*carta.OverflowError); !ok || e.Column != "views" || e.Field != "Views" {
+		t.Fatalf("expected an overflow error of views, got %v", err)
+	}
+}
+
+func TestGeneratedNull(t *testing.T) {
+	for _, i := range []int{1, 16} {
+		row := blogRow(1, 1, 1, 1)
+		row[i] = nil
+		if compare(t, blogColumns, [][]interface{}{row}) != nil {
+			t.Fatalf("expected an error loading null %s", blogColumns[i])
+		}
+	}
+}
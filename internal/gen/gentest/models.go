@@ -0,0 +1,61 @@
+// Package gentest holds structs annotated for carta-gen, the mappers generated for them in carta_gen.go are compared with carta.Map,
+// and carta_gen.go is the golden file of carta-gen, regenerate it after changes of the generator
+package gentest
+
+import "time"
+
+//go:generate go run ../../../cmd/carta-gen
+
+type Status int32
+
+//carta:generate
+type Blog struct {
+	Id        int `db:"blog_id,pk"`
+	Title     string
+	Rating    float32
+	Views     int32
+	Likes     uint32
+	Status    Status
+	Published bool
+	Created   time.Time
+	Subtitle  *string
+	Author    *Author
+	Editor    Editor
+	Posts     []Post
+}
+
+type Author struct {
+	Id   int `db:"author_id,pk"`
+	Name string
+	Tags []Tag
+}
+
+type Editor struct {
+	Id   int    `db:"editor_id,pk"`
+	Name string `db:"editor_name"`
+}
+
+type Post struct {
+	Id       int `db:"post_id,pk"`
+	Title    string
+	Score    *int64
+	Comments []*Comment
+}
+
+type Comment struct {
+	Id   int `db:"comment_id,pk"`
+	Body string
+}
+
+type Tag struct {
+	Id   int    `db:"tag_id,pk"`
+	Name string `db:"tag"`
+}
+
+// Flat is mapped with the function named with the directive
+//
+//carta:generate LoadComments
+type Flat struct {
+	Id   int `db:"comment_id"`
+	Body string
+}
@@ -0,0 +1,63 @@
+// Package names derives the names of columns which match fields, it is shared by carta and by the mappers generated by internal/gen,
+// so that generated mappers match columns as carta.Map does
+package names
+
+import "strings"
+
+// Rules derive the names of columns from the names of fields
+type Rules struct {
+	Naming     func(name string) string // column name of the name of a field, or of its concatenation with ancestors, ie, snake case
+	Normalize  func(name string) string // applied to every name, nil leaves names as they are
+	ExactCase  bool                     // only the names themselves match, not their lower case or the names derived with Naming
+	AutoPrefix bool                     // fields of nested structs match only prefixed names, which may also be joined with dots
+}
+
+// Candidates returns the names of columns matching the field, in the order they are derived, ie, "Title", "title",
+// "Posts_Title", "posts_title" for the field Title of a struct nested in the field Posts,
+// an empty field name is the element of a slice of a basic type, which matches the names of its ancestors
+func (r Rules) Candidates(fieldName string, ancestors []string) []string {
+	var names []string
+	add := func(name string, derived string) {
+		names = append(names, r.normalize(name))
+		if !r.ExactCase {
+			names = append(names, r.normalize(strings.ToLower(name)), r.normalize(derived))
+		}
+	}
+	if fieldName != "" && !(r.AutoPrefix && len(ancestors) != 0) {
+		add(fieldName, r.Naming(fieldName))
+	}
+	for _, concat := range prefixed(fieldName, ancestors, "_") {
+		add(concat, r.Naming(concat))
+	}
+	if r.AutoPrefix {
+		for _, dotted := range prefixed(fieldName, ancestors, ".") {
+			parts := strings.Split(dotted, ".")
+			for j, part := range parts {
+				parts[j] = r.Naming(part)
+			}
+			add(dotted, strings.Join(parts, "."))
+		}
+	}
+	return names
+}
+
+func (r Rules) normalize(name string) string {
+	if r.Normalize == nil {
+		return name
+	}
+	return r.Normalize(name)
+}
+
+// concatenations of the name with its ancestors, starting from the nearest, ie, "Posts_Title" and "Blog_Posts_Title"
+func prefixed(name string, ancestors []string, sep string) []string {
+	var names []string
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if name == "" {
+			name = ancestors[i]
+		} else {
+			name = ancestors[i] + sep + name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,32 @@
+package names
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func snake(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "Id", "_id"))
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		rules     Rules
+		field     string
+		ancestors []string
+		want      []string
+	}{
+		{Rules{Naming: snake}, "BlogId", nil, []string{"BlogId", "blogid", "blog_id"}},
+		{Rules{Naming: snake, ExactCase: true}, "BlogId", []string{"Posts"}, []string{"BlogId", "Posts_BlogId"}},
+		{Rules{Naming: snake}, "", []string{"Blog", "Tags"}, []string{"Tags", "tags", "tags", "Blog_Tags", "blog_tags", "blog_tags"}},
+		{Rules{Naming: snake, AutoPrefix: true, ExactCase: true}, "BlogId", []string{"Author", "Posts"},
+			[]string{"Posts_BlogId", "Author_Posts_BlogId", "Posts.BlogId", "Author.Posts.BlogId"}},
+		{Rules{Naming: snake, Normalize: strings.ToUpper, ExactCase: true}, "BlogId", nil, []string{"BLOGID"}},
+	}
+	for _, test := range tests {
+		if got := test.rules.Candidates(test.field, test.ancestors); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("candidates of %q with ancestors %v are %q, expected %q", test.field, test.ancestors, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package tags parses the tags naming columns, it is shared by carta, carta-gen and cartavet, so that they read tags alike
+package tags
+
+import (
+	"strings"
+	"time"
+)
+
+// Options read by carta, following the name of the column, ie, `db:"id,pk"`
+var Options = map[string]bool{"pk": true, "prefix": true, "null": true, "unit": true, "lenient": true}
+
+// Units of the unit option, of numeric columns loaded onto durations, ie, `db:"ttl,unit=ms"`
+var Units = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// Parse returns the name of the column of the tag, and its comma separated options,
+// options without a value, ie `db:"id,pk"`, are returned with an empty value
+func Parse(tag string) (string, map[string]string) {
+	parts := strings.Split(tag, ",")
+	opts := map[string]string{}
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		if i := strings.Index(opt, "="); i >= 0 {
+			opts[opt[:i]] = opt[i+1:]
+		} else {
+			opts[opt] = ""
+		}
+	}
+	return strings.TrimSpace(parts[0]), opts
+}
@@ -0,0 +1,72 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// prefixed is a destination of MapJoined whose fields only match columns starting with the prefix
+type prefixed struct {
+	prefix string
+	dst    interface{}
+}
+
+// Prefixed makes the fields of a destination passed to MapJoined only match columns starting with the prefix,
+// which is stripped before matching, ie, field Id matches column "user_id" with the prefix "user_"
+func Prefixed(prefix string, dst interface{}) interface{} {
+	return prefixed{prefix, dst}
+}
+
+// MapJoined maps the same rows onto independent destinations, ie, of a join of two tables, without nesting one in the other, example:
+//
+//	users, orders := []User{}, []Order{}
+//	err := carta.MapJoined(rows, []interface{}{carta.Prefixed("user_", &users), carta.Prefixed("order_", &orders)})
+//
+// destinations are pointers to slices, optionally wrapped with Prefixed, and are deduplicated independently,
+// columns are claimed by the destinations in order, so that prefixes should be disjoint, opts apply to every destination
+func MapJoined(rows RowsSource, dst []interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	fields := make([]reflect.StructField, len(dst))
+	dsts := make([]reflect.Value, len(dst))
+	for i, d := range dst {
+		p, ok := d.(prefixed)
+		if !ok {
+			p = prefixed{dst: d}
+		}
+		dstTyp := reflect.TypeOf(p.dst)
+		if dstTyp == nil || !isSlicePtr(dstTyp) {
+			rows.Close()
+			return destinationError(dstTyp, "pointer to a slice")
+		}
+		if err := validateDestination(p.dst); err != nil {
+			rows.Close()
+			return err
+		}
+		name := "D" + strconv.Itoa(i)
+		fields[i] = reflect.StructField{
+			Name: name,
+			Type: dstTyp.Elem(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`%s:"%s,prefix=%s"`, o.tagKey, name, p.prefix)),
+		}
+		dsts[i] = reflect.ValueOf(p.dst)
+	}
+
+	// destinations are mapped as collections of a single struct, whose fields claim columns with their prefixes
+	joined := reflect.New(reflect.StructOf(fields))
+	if err := Map(rows, joined.Interface(), opts...); err != nil {
+		return err
+	}
+	for i, d := range dsts {
+		elems := joined.Elem().Field(i)
+		if elems.IsNil() {
+			elems = reflect.MakeSlice(d.Type().Elem(), 0, 0)
+		}
+		if o.replaceDst || d.Elem().IsNil() {
+			d.Elem().Set(elems)
+		} else {
+			d.Elem().Set(reflect.AppendSlice(d.Elem(), elems))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package carta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MapJSON maps an array of flat JSON objects onto dst as Map does, ie, rows exported from BigQuery or returned by an API,
+// keys of the objects are the columns, in the order they first appear, example:
+//
+//	blogs := []Blog{}
+//	err := carta.MapJSON(strings.NewReader(`[{"blog_id": 1, "post_id": 1}, {"blog_id": 1, "post_id": 2}]`), &blogs)
+//
+// missing keys and null values are null, nested objects and arrays are loaded as raw JSON, ie, onto types implementing
+// json.Unmarshaler through encoding.TextUnmarshaler, rows are deduplicated and nested as rows of a database are
+func MapJSON(r io.Reader, dst interface{}, opts ...Option) error {
+	columns, rows, err := readJSONRows(r)
+	if err != nil {
+		return err
+	}
+	return MapValues(columns, rows, dst, opts...)
+}
+
+// reads objects of a JSON array as rows, columns are the keys of the objects, in the order they first appear
+func readJSONRows(r io.Reader) ([]string, [][]interface{}, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, nil, err
+	}
+	var (
+		columns []string
+		indexes = map[string]int{}
+		objects []map[int]interface{}
+	)
+	for dec.More() {
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, nil, err
+		}
+		object := map[int]interface{}{}
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key := token.(string)
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return nil, nil, err
+			}
+			v, err := jsonValue(raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			i, ok := indexes[key]
+			if !ok {
+				i = len(columns)
+				indexes[key] = i
+				columns = append(columns, key)
+			}
+			object[i] = v
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		objects = append(objects, object)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, errors.New("carta: unexpected JSON after the array of rows")
+	}
+	rows := make([][]interface{}, len(objects))
+	for n, object := range objects {
+		rows[n] = make([]interface{}, len(columns))
+		for i, v := range object {
+			rows[n][i] = v
+		}
+	}
+	return columns, rows, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err == io.EOF {
+		return errors.New("carta: unexpected end of JSON")
+	} else if err != nil {
+		return err
+	}
+	if token != delim {
+		if delim == '[' {
+			return fmt.Errorf("carta: cannot map JSON %v, rows must be an array of objects", token)
+		}
+		return fmt.Errorf("carta: cannot map JSON %v, rows must be objects", token)
+	}
+	return nil
+}
+
+// driver value of a JSON value, integers are int64, other numbers are float64, objects and arrays are raw JSON
+func jsonValue(raw json.RawMessage) (interface{}, error) {
+	switch raw[0] {
+	case 'n':
+		return nil, nil
+	case 't', 'f':
+		return raw[0] == 't', nil
+	case '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case '{', '[':
+		return []byte(raw), nil
+	}
+	if i, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return i, nil
+	}
+	return strconv.ParseFloat(string(raw), 64)
+}
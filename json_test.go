@@ -0,0 +1,129 @@
+package carta_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+// tags of a blog, loaded from a JSON array
+type jsonTags []string
+
+func (t *jsonTags) UnmarshalText(b []byte) error {
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// metadata of a blog, loaded from a JSON object
+type jsonMeta struct {
+	Lang string `json:"lang"`
+}
+
+func (m *jsonMeta) UnmarshalText(b []byte) error {
+	type plain jsonMeta
+	return json.Unmarshal(b, (*plain)(m))
+}
+
+type jsonPost struct {
+	PostId int64
+}
+
+type jsonBlog struct {
+	BlogId int64
+	Title  *string
+	Rating float64
+	Views  int64
+	Tags   *jsonTags
+	Meta   *jsonMeta
+	Posts  []jsonPost
+}
+
+func TestMapJSON(t *testing.T) {
+	// keys are in a different order in every object, title is missing from the second blog
+	input := `[
+		{"blog_id": 1, "title": "first", "rating": 4.5, "views": 9007199254740993, "tags": ["go", "sql"], "meta": {"lang": "en"}, "post_id": 1},
+		{"post_id": 2, "blog_id": 1, "title": "first", "rating": 4.5, "views": 9007199254740993, "tags": ["go", "sql"], "meta": {"lang": "en"}},
+		{"rating": 3, "blog_id": 2, "views": 0, "post_id": 3, "meta": null}
+	]
+`
+	blogs := []jsonBlog{}
+	if err := carta.MapJSON(strings.NewReader(input), &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.BlogId != 1 || first.Title == nil || *first.Title != "first" || first.Rating != 4.5 {
+		t.Errorf("unexpected first blog %+v", first)
+	}
+	if first.Views != 9007199254740993 {
+		t.Errorf("expected integers to be loaded exactly, got %d", first.Views)
+	}
+	if first.Tags == nil || len(*first.Tags) != 2 || (*first.Tags)[0] != "go" || (*first.Tags)[1] != "sql" {
+		t.Errorf("expected tags loaded from the raw array, got %v", first.Tags)
+	}
+	if first.Meta == nil || first.Meta.Lang != "en" {
+		t.Errorf("expected meta loaded from the raw object, got %+v", first.Meta)
+	}
+	if len(first.Posts) != 2 || first.Posts[0].PostId != 1 || first.Posts[1].PostId != 2 {
+		t.Errorf("expected posts 1 and 2, got %+v", first.Posts)
+	}
+	second := blogs[1]
+	if second.BlogId != 2 || second.Title != nil || second.Rating != 3 || second.Meta != nil || second.Tags != nil {
+		t.Errorf("expected missing keys and nulls to leave fields unset, got %+v", second)
+	}
+	if len(second.Posts) != 1 || second.Posts[0].PostId != 3 {
+		t.Errorf("expected post 3, got %+v", second.Posts)
+	}
+}
+
+func TestMapJSONColumnOrder(t *testing.T) {
+	// columns are the keys in the order they first appear, which is the order of fields mapped by position
+	input := `[{"b": 1, "a": 2}, {"c": 3, "a": 4, "b": 5}]`
+	rows := []struct {
+		B int
+		A int
+		C *int
+	}{}
+	if err := carta.MapJSON(strings.NewReader(input), &rows, carta.WithOrdinal(), carta.WithoutDedup()); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].B != 1 || rows[0].A != 2 || rows[0].C != nil || rows[1].B != 5 || rows[1].A != 4 || *rows[1].C != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+}
+
+func TestMapJSONNumbers(t *testing.T) {
+	rows := []struct {
+		Id     int64
+		Amount float64
+	}{}
+	if err := carta.MapJSON(strings.NewReader(`[{"id": 1, "amount": 2}, {"id": 2, "amount": 2.5e1}]`), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Amount != 2 || rows[1].Amount != 25 {
+		t.Fatalf("expected integers and floats loaded onto float fields, got %+v", rows)
+	}
+	if err := carta.MapJSON(strings.NewReader(`[{"id": 1.5}]`), &rows); err == nil {
+		t.Fatal("expected an error loading a fraction onto an integer")
+	}
+}
+
+func TestMapJSONMalformed(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{"id": 1}`,
+		`[1, 2]`,
+		`[{"id": 1}`,
+		`[{"id": 1,}]`,
+		`[{"id": 1}] trailing`,
+		`[{"id": 1} {"id": 2}]`,
+	} {
+		rows := []struct{ Id int }{}
+		if err := carta.MapJSON(strings.NewReader(input), &rows); err == nil {
+			t.Errorf("expected an error for %q, got %+v", input, rows)
+		}
+	}
+}
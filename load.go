@@ -1,38 +1,102 @@
 package carta
 
 import (
-	"database/sql"
+	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/jackskj/carta/value"
+	"google.golang.org/protobuf/proto"
 )
 
-func (m *Mapper) loadRows(rows *sql.Rows, colTyps []*sql.ColumnType) (*resolver, error) {
-	defer rows.Close() // may not need
+// loads rows onto a resolver, if emit is not nil, rows are streamed, emit is called with a resolver holding a single
+// root element once a row of another root element arrives, as well as after the last row,
+// rows of the same root element must then be adjacent, ie, ordered by the key of the root element
+// loading stops with the error of ctx once it is done, which is checked before every row
+// colTypNames are the database type names of the columns the root mapper was planned with
+func (m *Mapper) loadRows(ctx context.Context, rows RowsSource, colTypNames []string, o *options, emit func(rsv *resolver) error) (*resolver, error) {
 	var err error
-	row := make([]interface{}, len(colTyps))
-	colTypNames := make([]string, len(colTyps))
-	for i := 0; i < len(colTyps); i++ {
-		colTypNames[i] = colTyps[i].DatabaseTypeName()
-	}
+	row := make([]interface{}, len(m.columns))
 	rsv := newResolver()
-	for rows.Next() {
-		for i := 0; i < len(colTyps); i++ {
-			row[i] = value.NewCell(colTypNames[i])
+	if o.unnest {
+		rows = newUnnestSource(rows, len(m.columns))
+	}
+	for n := 1; rows.Next(); n++ {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(row); i++ {
+			cell := value.NewCell(colTypNames[i])
+			cell.SetTimeLocation(o.timeLocation)
+			row[i] = cell
 		}
 		if err = rows.Scan(row...); err != nil {
 			return nil, err
 		}
-		if err = loadRow(m, row, rsv); err != nil {
+		if o.oracle {
+			for i := range row {
+				if value.OracleNumberTypes[strings.ToUpper(colTypNames[i])] {
+					row[i].(*value.Cell).ParseNumber()
+				}
+			}
+		}
+		if o.transform != nil {
+			if err = transformRow(row, m.columns, o.transform); err != nil {
+				return nil, err
+			}
+		}
+		if emit != nil && len(rsv.elementOrder) != 0 {
+			if _, found := rsv.elements[getElementId(row, m, rsv, o)]; !found {
+				if err = emit(rsv); err != nil {
+					return nil, err
+				}
+				rsv = newResolver()
+			}
+		}
+		if err = loadRow(m, row, rsv, o); err != nil {
+			switch e := err.(type) {
+			case *EnumValueError:
+				e.Row = n
+			case *MappingError:
+				e.Row = n
+			}
+			return nil, err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if emit != nil && len(rsv.elementOrder) != 0 {
+		if err = emit(rsv); err != nil {
 			return nil, err
 		}
 	}
 	return rsv, nil
 }
 
+// replaces values of the cells with values returned by the transformer set with WithValueTransformer,
+// returned values are converted to driver values, ie, int to int64
+func transformRow(row []interface{}, columns []string, transform func(column string, v interface{}) (interface{}, error)) error {
+	for i, column := range columns {
+		cell := row[i].(*value.Cell)
+		v, err := transform(column, cell.Value())
+		if err != nil {
+			return fmt.Errorf("carta: cannot transform column %s: %w", column, err)
+		}
+		if v, err = driver.DefaultParameterConverter.ConvertValue(v); err != nil {
+			return fmt.Errorf("carta: cannot transform column %s: %w", column, err)
+		}
+		cell.Scan(v)
+	}
+	return nil
+}
+
 // load row maps a single sql row onto a structure that resembles the users struct
 // that mapping is stored in the resolver as a pointer reference to an instance of the struct
 //
@@ -50,20 +114,24 @@ func (m *Mapper) loadRows(rows *sql.Rows, colTyps []*sql.ColumnType) (*resolver,
 // the function contunous to recursivelly map rows for all sub mappings inside Blog
 //  for example, if a blog has many Authors
 // rows are actually []*Cell, theu are passed here as interface since sql scan requires []interface{}
-func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
+func loadRow(m *Mapper, row []interface{}, rsv *resolver, o *options) error {
 	var (
 		err      error
 		dstField reflect.Value // destination field to be set with
 		cell     *value.Cell
 		elem     *element
 		found    bool
+		isNull   bool // basic element loaded from a null column
 	)
 
-	uid := getUniqueId(row, m)
+	uid := getElementId(row, m, rsv, o)
 
 	if elem, found = rsv.elements[uid]; !found {
 		// unique row mapping found, new object
 		loadElem := reflect.New(m.Typ).Elem()
+		if m.isInitializer {
+			loadElem.Addr().Interface().(Initializer).Init()
+		}
 
 		for _, col := range m.PresentColumns {
 			var (
@@ -71,6 +139,7 @@ func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
 				dst      reflect.Value // destination to set
 				typ      reflect.Type  // underlying type of the destination
 				isDstPtr bool          //is the destination a pointer
+				field    Field         // field options, basic mappers use defaults
 			)
 
 			cell = row[col.columnIndex].(*value.Cell)
@@ -80,8 +149,10 @@ func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
 				kind = m.Kind
 				typ = m.Typ
 				isDstPtr = m.IsTypePtr
+				field = Field{unit: time.Second}
 			} else {
 				dstField = loadElem.Field(int(col.i))
+				field = m.Fields[col.i]
 				if m.Fields[col.i].IsPtr {
 					dst = reflect.New(m.Fields[col.i].ElemTyp).Elem()
 					kind = m.Fields[col.i].ElemKind
@@ -94,110 +165,52 @@ func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
 					isDstPtr = false
 				}
 			}
+			if cell.IsNull() && field.nullValue != nil {
+				cell = field.nullValue
+			}
 			if cell.IsNull() {
 				_, nullable := value.NullableTypes[typ]
+				// maps, and slices loaded with converters, ie, registered with RegisterJSON, are left nil
+				nullable = nullable || kind == reflect.Map || kind == reflect.Slice && col.conv != nil
 				if !(isDstPtr || nullable) {
 					if 0 != strings.Compare(typ.Name(), "bool") {
-						return errors.New(fmt.Sprintf("carta: cannot load null value to type %s for column %s", typ, col.name))
+						return columnError(m, col, typ, cell, errors.New("cannot load null value"))
 					}
 				}
 				// no need to set destination if cell is null
-			} else {
-				switch kind {
-				case reflect.Bool:
-					if d, err := cell.Bool(); err != nil {
-						return value.ConvertsionError(err, typ)
-					} else {
-						dst.SetBool(d)
-					}
-				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					if d, err := cell.Uint64(); err != nil {
-						return value.ConvertsionError(err, typ)
-					} else {
-						dst.SetUint(d)
-					}
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					if d, err := cell.Int64(); err != nil {
-						return value.ConvertsionError(err, typ)
-					} else {
-						dst.SetInt(d)
-					}
-				case reflect.String:
-					if d, err := cell.String(); err != nil {
-						return value.ConvertsionError(err, typ)
-					} else {
-						dst.SetString(d)
-					}
-				case reflect.Float32, reflect.Float64:
-					if d, err := cell.Float64(); err != nil {
-						return value.ConvertsionError(err, typ)
-					} else {
-						dst.SetFloat(d)
-					}
-				case reflect.Struct:
-					if strTyp, ok := value.BasicTypes[typ]; ok {
-						// TODO: Type asserion, prevent from calling ValueOf
-						// TODO: make these stupid error checks more concise
-						//  this swich statement should be optimized
-
-						switch strTyp {
-						case value.Time:
-							if d, err := cell.Time(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.Timestamp:
-							if d, err := cell.Timestamp(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullBool:
-							if d, err := cell.NullBool(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullFloat64:
-							if d, err := cell.NullFloat64(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullInt32:
-							if d, err := cell.NullInt32(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullInt64:
-							if d, err := cell.NullInt64(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullString:
-							if d, err := cell.NullString(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						case value.NullTime:
-							if d, err := cell.NullTime(); err != nil {
-								return value.ConvertsionError(err, typ)
-							} else {
-								dst.Set(reflect.ValueOf(d))
-							}
-						}
-					}
+				isNull = m.IsBasic
+			} else if col.conv != nil {
+				if err = setConverted(dst, typ, col.conv, cell); err != nil {
+					return columnError(m, col, typ, cell, err)
 				}
 				if !m.IsBasic && m.Fields[col.i].IsPtr {
 					dstField.Set(dst.Addr())
 				}
+			} else if useUnmarshalBinary(col, cell) {
+				if err = setUnmarshaledBinary(dst, typ, cell); err != nil {
+					return columnError(m, col, typ, cell, err)
+				}
+				if !m.IsBasic && m.Fields[col.i].IsPtr {
+					dstField.Set(dst.Addr())
+				}
+			} else if useUnmarshalText(col, kind, cell) {
+				if err = setUnmarshaledText(dst, typ, cell); err != nil {
+					return columnError(m, col, typ, cell, err)
+				}
+				if !m.IsBasic && m.Fields[col.i].IsPtr {
+					dstField.Set(dst.Addr())
+				}
+			} else {
+				// pointer fields reference the newly allocated destination, which is set below
+				if !m.IsBasic && m.Fields[col.i].IsPtr {
+					dstField.Set(dst.Addr())
+				}
+				if err = setValue(dst, kind, typ, field, cell); err != nil {
+					return columnError(m, col, typ, cell, err)
+				}
 			}
 		}
-		elem = &element{v: loadElem}
+		elem = &element{v: loadElem, isNull: isNull}
 		if len(m.SubMaps) != 0 {
 			elem.subMaps = map[fieldIndex]*resolver{}
 			for i, _ := range m.SubMaps {
@@ -209,7 +222,7 @@ func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
 	}
 
 	for i, subMap := range m.SubMaps {
-		if err = loadRow(subMap, row, elem.subMaps[i]); err != nil {
+		if err = loadRow(subMap, row, elem.subMaps[i], o); err != nil {
 			return err
 		}
 	}
@@ -217,6 +230,209 @@ func loadRow(m *Mapper, row []interface{}, rsv *resolver) error {
 	return nil
 }
 
+// sets the column of errors loading the column onto the field of the mapper, or wraps them with a MappingError,
+// enum and overflow errors are returned as they are, typ is the type of the destination
+func columnError(m *Mapper, col column, typ reflect.Type, cell *value.Cell, err error) error {
+	if e, ok := err.(*value.ConversionError); ok {
+		// the destination type is already known
+		err = e.Err
+	}
+	if e, ok := err.(*value.OverflowError); ok {
+		err = &OverflowError{Value: e.Value, Type: typ}
+	}
+	switch e := err.(type) {
+	case *EnumValueError:
+		e.Column = col.name
+		return e
+	case *OverflowError:
+		e.Column = col.name
+		e.Field = m.path
+		if !m.IsBasic {
+			e.Field = joinPath(m.path, m.Typ.Field(int(col.i)).Name)
+		}
+		return e
+	}
+	e := &MappingError{
+		Column:    col.name,
+		FieldPath: m.path,
+		DestType:  typ,
+		Err:       err,
+	}
+	if !m.IsBasic {
+		e.FieldPath = joinPath(m.path, m.Typ.Field(int(col.i)).Name)
+	}
+	if v := cell.Value(); v != nil {
+		e.SourceType = reflect.TypeOf(v)
+	}
+	return e
+}
+
+// sets the destination with the value of a non null cell, using carta's native conversions
+// kind and typ are of the destination, field holds options set with the tag, ie, the unit of durations
+func setValue(dst reflect.Value, kind reflect.Kind, typ reflect.Type, field Field, cell *value.Cell) error {
+	if i, ok := value.WrapperTypes[typ]; ok {
+		// proto wrappers are loaded through their Value field
+		dst = dst.Field(i)
+		kind = dst.Kind()
+		typ = dst.Type()
+	}
+	switch kind {
+	case reflect.Bool:
+		if field.lenientBool {
+			if d, err := cell.LenientBool(); err != nil {
+				return value.ConvertsionError(err, typ)
+			} else {
+				dst.SetBool(d)
+			}
+		} else if d, err := cell.Bool(); err != nil {
+			return value.ConvertsionError(err, typ)
+		} else {
+			dst.SetBool(d)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if d, err := cell.Uint64(); err != nil {
+			return value.ConvertsionError(err, typ)
+		} else if dst.OverflowUint(d) {
+			return &OverflowError{Value: d, Type: typ}
+		} else {
+			dst.SetUint(d)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.BasicTypes[typ] == value.GoDuration {
+			if d, err := cell.Duration(field.unit); err != nil {
+				return value.ConvertsionError(err, typ)
+			} else {
+				dst.SetInt(int64(d))
+			}
+		} else if d, err := cell.Int64(); err != nil {
+			return value.ConvertsionError(err, typ)
+		} else if dst.OverflowInt(d) {
+			return &OverflowError{Value: d, Type: typ}
+		} else {
+			dst.SetInt(d)
+		}
+	case reflect.String:
+		if d, err := cell.String(); err != nil {
+			return value.ConvertsionError(err, typ)
+		} else {
+			dst.SetString(d)
+		}
+	case reflect.Float32, reflect.Float64:
+		if d, err := cell.Float64(); err != nil {
+			return value.ConvertsionError(err, typ)
+		} else if dst.OverflowFloat(d) {
+			return &OverflowError{Value: d, Type: typ}
+		} else {
+			dst.SetFloat(d)
+		}
+	case reflect.Struct, reflect.Slice:
+		if _, ok := value.BasicTypes[typ]; !ok && kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			// only reachable through wrappers.BytesValue
+			if d, err := cell.Bytes(); err != nil {
+				return value.ConvertsionError(err, typ)
+			} else {
+				dst.SetBytes(d)
+			}
+		} else if strTyp, ok := value.BasicTypes[typ]; ok {
+			// TODO: Type asserion, prevent from calling ValueOf
+			// TODO: make these stupid error checks more concise
+			//  this swich statement should be optimized
+
+			switch strTyp {
+			case value.Time:
+				if d, err := cell.Time(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.Timestamp:
+				if d, err := cell.Timestamp(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					// set through a pointer, since the message holds a mutex
+					dst.Set(reflect.ValueOf(&d).Elem())
+				}
+			case value.NullBool:
+				if d, err := cell.NullBool(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.NullFloat64:
+				if d, err := cell.NullFloat64(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.NullInt32:
+				if d, err := cell.NullInt32(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.NullInt64:
+				if d, err := cell.NullInt64(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.NullString:
+				if d, err := cell.NullString(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.NullTime:
+				if d, err := cell.NullTime(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.IP:
+				if d, err := cell.IP(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			case value.Duration:
+				if d, err := cell.Duration(field.unit); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					pb := dst.Addr().Interface().(*duration.Duration)
+					pb.Seconds = int64(d / time.Second)
+					pb.Nanos = int32(d % time.Second)
+				}
+			case value.ProtoJSON:
+				if err := cell.ProtoJSON(dst.Addr().Interface().(proto.Message)); err != nil {
+					return value.ConvertsionError(err, typ)
+				}
+			case value.RawBytes:
+				dst.SetBytes(cell.RawBytes())
+			case value.Raw:
+				dst.Set(reflect.ValueOf(value.RawValue{Value: cell.Value()}))
+			case value.IPNet:
+				if d, err := cell.IPNet(); err != nil {
+					return value.ConvertsionError(err, typ)
+				} else {
+					dst.Set(reflect.ValueOf(d))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// id of the element a row maps onto, rows which map onto an element already in the resolver have its id
+// rows loaded onto destinations of basic types, ie, *[]string, are not deduplicated, nor are rows of mappers
+// which are not parents of collections with WithoutDedup
+func getElementId(row []interface{}, m *Mapper, rsv *resolver, o *options) uniqueValId {
+	if o.noDedup && !m.parent || m.IsBasic && m.dstTyp != nil {
+		// every row is a new element
+		return uniqueValId(strconv.Itoa(len(rsv.elementOrder)))
+	}
+	return getUniqueId(row, m)
+}
+
 // Generates unique id based on the ancestors of the struct as well as currently considered colum values
 func getUniqueId(row []interface{}, m *Mapper) uniqueValId {
 	// TODO: set capacity of the uid slice, using bytes.buffer
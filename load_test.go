@@ -0,0 +1,352 @@
+package carta_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type host struct {
+	Id   int
+	Addr net.IP
+	Net  *net.IPNet
+}
+
+func TestTextIP(t *testing.T) {
+	hosts := []host{}
+	err := carta.MapValues([]string{"id", "addr", "net"}, [][]interface{}{{1, "192.168.0.10", "10.0.0.0/8"}}, &hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || !hosts[0].Addr.Equal(net.ParseIP("192.168.0.10")) || hosts[0].Net == nil || hosts[0].Net.String() != "10.0.0.0/8" {
+		t.Fatalf("inet text columns did not load onto net.IP and net.IPNet, got %+v", hosts)
+	}
+}
+
+type counter struct {
+	Id    int
+	Views int64
+	Likes uint32
+}
+
+func TestOverflowError(t *testing.T) {
+	tests := []struct {
+		column string
+		v      interface{}
+	}{
+		{"views", 1e20},
+		{"views", "99999999999999999999"},
+		{"likes", -1},
+		{"likes", "-1"},
+		{"likes", int64(1) << 40},
+	}
+	for _, test := range tests {
+		row := []interface{}{1, 0, 0}
+		if test.column == "views" {
+			row[1] = test.v
+		} else {
+			row[2] = test.v
+		}
+		counters := []counter{}
+		err := carta.MapValues([]string{"id", "views", "likes"}, [][]interface{}{row}, &counters)
+		e, ok := err.(*carta.OverflowError)
+		if !ok {
+			t.Errorf("expected an OverflowError loading %v onto %s, got %T: %v", test.v, test.column, err, err)
+		} else if e.Column != test.column || e.Field == "" {
+			t.Errorf("expected the column %s and its field, got %+v", test.column, e)
+		}
+	}
+}
+
+type overflowedPost struct {
+	Id int8
+}
+
+type overflowedBlog struct {
+	Id     int
+	Posts  []overflowedPost
+	Counts []uint8 `db:"count"`
+}
+
+func TestOverflowErrorField(t *testing.T) {
+	tests := []struct {
+		row    []interface{}
+		column string
+		field  string
+	}{
+		{[]interface{}{1, 1000, 1}, "id", "Posts.Id"},
+		{[]interface{}{1, 1, 1000}, "count", "Counts"},
+	}
+	for _, test := range tests {
+		blogs := []overflowedBlog{}
+		err := carta.MapValues([]string{"id", "id", "count"}, [][]interface{}{test.row}, &blogs, carta.WithOrdinal())
+		e, ok := err.(*carta.OverflowError)
+		if !ok {
+			t.Errorf("expected an OverflowError loading %v, got %T: %v", test.row, err, err)
+		} else if e.Column != test.column || e.Field != test.field {
+			t.Errorf("expected the column %s and the field %s, got %+v", test.column, test.field, e)
+		} else if !strings.Contains(e.Error(), "overflows field "+test.field+" of type") {
+			t.Errorf("expected the path of the field in the error, got %v", e)
+		}
+	}
+}
+
+type nullable struct {
+	Id     int
+	Title  *string
+	Views  *int64
+	Rating *float64
+	Draft  *bool
+	Labels []*string `db:"label"`
+}
+
+func TestNullPointers(t *testing.T) {
+	columns := []string{"id", "title", "views", "rating", "draft", "label"}
+	rows := [][]interface{}{
+		{1, "blog", int64(10), 4.5, true, "go"},
+		{1, "blog", int64(10), 4.5, true, nil},
+		{2, nil, nil, nil, nil, nil},
+	}
+	blogs := []nullable{}
+	if err := carta.MapValues(columns, rows, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	loaded := blogs[0]
+	if loaded.Title == nil || *loaded.Title != "blog" || loaded.Views == nil || *loaded.Views != 10 ||
+		loaded.Rating == nil || *loaded.Rating != 4.5 || loaded.Draft == nil || !*loaded.Draft {
+		t.Fatalf("non null columns did not load onto pointer fields, got %+v", loaded)
+	}
+	if len(loaded.Labels) != 2 || loaded.Labels[0] == nil || *loaded.Labels[0] != "go" || loaded.Labels[1] != nil {
+		t.Fatalf("expected a nil element for the null label, got %v", loaded.Labels)
+	}
+	null := blogs[1]
+	if null.Title != nil || null.Views != nil || null.Rating != nil || null.Draft != nil {
+		t.Fatalf("null columns did not load as nil pointers, got %+v", null)
+	}
+}
+
+type defaulted struct {
+	Id     int
+	Status ticketStatus `db:"status,null=OPEN"`
+	Count  int64        `db:"count,null=0"`
+	Rating float64      `db:"rating,null=-1.5"`
+	Title  string       `db:"title,null=untitled"`
+	Author *string      `db:"author,null=anonymous"`
+}
+
+func TestNullTagOption(t *testing.T) {
+	columns := []string{"id", "status", "count", "rating", "title", "author"}
+	rows := [][]interface{}{
+		{1, "CLOSED", int64(3), 4.5, "first", "ann"},
+		{2, nil, nil, nil, nil, nil},
+	}
+	items := []defaulted{}
+	if err := carta.MapValues(columns, rows, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", items)
+	}
+	loaded := items[0]
+	if loaded.Status != 2 || loaded.Count != 3 || loaded.Rating != 4.5 || loaded.Title != "first" || loaded.Author == nil || *loaded.Author != "ann" {
+		t.Fatalf("expected non null columns to be loaded as they are, got %+v", loaded)
+	}
+	null := items[1]
+	if null.Status != 1 || null.Count != 0 || null.Rating != -1.5 || null.Title != "untitled" || null.Author == nil || *null.Author != "anonymous" {
+		t.Fatalf("expected the values of the null option in place of null columns, got %+v", null)
+	}
+}
+
+// version loaded with UnmarshalText, ie, "1.2"
+type version struct {
+	Major, Minor int
+}
+
+func (v *version) UnmarshalText(b []byte) error {
+	if _, err := fmt.Sscanf(string(b), "%d.%d", &v.Major, &v.Minor); err != nil {
+		return fmt.Errorf("invalid version %q", b)
+	}
+	return nil
+}
+
+// level is loaded natively from numbers, and with UnmarshalText from text
+type level int
+
+func (l *level) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type release struct {
+	Id       int
+	Version  version
+	Previous *version
+	Level    level
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	columns := []string{"id", "version", "previous", "level"}
+	rows := [][]interface{}{
+		{1, "1.2", []byte("1.1"), "high"},
+		{2, []byte("2.0"), nil, int64(1)},
+	}
+	releases := []release{}
+	if err := carta.MapValues(columns, rows, &releases); err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %+v", releases)
+	}
+	first := releases[0]
+	if first.Version != (version{1, 2}) || first.Previous == nil || *first.Previous != (version{1, 1}) || first.Level != 2 {
+		t.Fatalf("expected text and bytes to be loaded with UnmarshalText, got %+v", first)
+	}
+	second := releases[1]
+	if second.Version != (version{2, 0}) || second.Previous != nil || second.Level != 1 {
+		t.Fatalf("expected a nil pointer for null, and numbers loaded natively onto level, got %+v", second)
+	}
+
+	releases = []release{}
+	err := carta.MapValues(columns, [][]interface{}{{1, "latest", nil, "low"}}, &releases)
+	if err == nil || !strings.Contains(err.Error(), `invalid version "latest"`) {
+		t.Fatalf("expected the error of UnmarshalText, got %v", err)
+	}
+}
+
+// digest loaded with UnmarshalBinary from binary columns, and with UnmarshalText from hex encoded text columns
+type digest struct {
+	Sum     [4]byte
+	FromHex bool
+}
+
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if len(b) != len(d.Sum) {
+		return fmt.Errorf("invalid digest of %d bytes", len(b))
+	}
+	copy(d.Sum[:], b)
+	return nil
+}
+
+func (d *digest) UnmarshalText(b []byte) error {
+	sum, err := hex.DecodeString(string(b))
+	if err != nil {
+		return err
+	}
+	d.FromHex = true
+	return d.UnmarshalBinary(sum)
+}
+
+// pair of bytes, which only unmarshals binary, so it is loaded with UnmarshalBinary from any column
+type bytePair struct {
+	First, Second byte
+}
+
+func (p *bytePair) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return fmt.Errorf("invalid pair of %d bytes", len(b))
+	}
+	p.First, p.Second = b[0], b[1]
+	return nil
+}
+
+type blob struct {
+	Id     int
+	Digest digest
+	Hex    *digest
+	Pair   bytePair
+}
+
+func TestBinaryUnmarshaler(t *testing.T) {
+	rows, err := carta.ReplayRows(strings.NewReader(`{
+		"columns": ["id", "digest", "hex", "pair"],
+		"types": ["INT", "BYTEA", "TEXT", "TEXT"],
+		"rows": [[{"int": 1}, {"bytes": "AQIDBA=="}, {"string": "01020304"}, {"string": "AB"}], [{"int": 2}, {"bytes": "AQIDBA=="}, null, {"bytes": "QUI="}]]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := []blob{}
+	if err = carta.Map(rows, &blobs); err != nil {
+		t.Fatal(err)
+	}
+	sum := [4]byte{1, 2, 3, 4}
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs, got %+v", blobs)
+	}
+	first := blobs[0]
+	if first.Digest != (digest{Sum: sum}) || first.Hex == nil || *first.Hex != (digest{Sum: sum, FromHex: true}) {
+		t.Fatalf("expected binary columns loaded with UnmarshalBinary, and text columns with UnmarshalText, got %+v", first)
+	}
+	for _, b := range blobs {
+		if b.Pair != (bytePair{'A', 'B'}) {
+			t.Fatalf("expected the pair loaded with UnmarshalBinary from text and bytes, got %+v", b.Pair)
+		}
+	}
+	if blobs[1].Hex != nil {
+		t.Fatalf("expected a nil pointer for null, got %+v", blobs[1].Hex)
+	}
+
+	blobs = []blob{}
+	err = carta.MapValues([]string{"id", "pair"}, [][]interface{}{{1, "ABC"}}, &blobs)
+	if err == nil || !strings.Contains(err.Error(), "invalid pair of 3 bytes") {
+		t.Fatalf("expected the error of UnmarshalBinary, got %v", err)
+	}
+}
+
+type mappedComment struct {
+	CommentId int
+	Likes     int
+}
+
+type mappedPost struct {
+	PostId   int
+	Comments []mappedComment
+	Tags     []int `db:"tag"`
+}
+
+func TestMappingError(t *testing.T) {
+	columns := []string{"post_id", "comment_id", "likes", "tag"}
+	tests := []struct {
+		row        []interface{}
+		column     string
+		fieldPath  string
+		sourceType reflect.Type
+		destType   reflect.Type
+	}{
+		{[]interface{}{1, 1, nil, 1}, "likes", "Comments.Likes", nil, reflect.TypeOf(0)},
+		{[]interface{}{1, 1, "many", 1}, "likes", "Comments.Likes", reflect.TypeOf(""), reflect.TypeOf(0)},
+		{[]interface{}{1, 1, 1, "first"}, "tag", "Tags", reflect.TypeOf(""), reflect.TypeOf(0)},
+	}
+	for _, test := range tests {
+		rows := [][]interface{}{{1, 0, 0, 0}, test.row}
+		posts := []mappedPost{}
+		err := carta.MapValues(columns, rows, &posts)
+		var e *carta.MappingError
+		if !errors.As(err, &e) {
+			t.Errorf("expected a MappingError loading %v, got %T: %v", test.row, err, err)
+			continue
+		}
+		if e.Column != test.column || e.FieldPath != test.fieldPath || e.SourceType != test.sourceType || e.DestType != test.destType || e.Row != 2 {
+			t.Errorf("expected column %s, field %s, source %v and destination %v of row 2, got %+v", test.column, test.fieldPath, test.sourceType, test.destType, e)
+		}
+		if e.Unwrap() == nil || !strings.HasPrefix(err.Error(), "carta: cannot load column "+test.column+" of row 2 onto field "+test.fieldPath) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package carta
+
+import "reflect"
+
+// Logger logs diagnostics of the mapping, such as columns which do not match any field, it is implemented by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// logger is the logger used by default, set with SetLogger, nothing is logged by default
+var logger Logger
+
+// SetLogger sets the logger used by default, the logger can also be set for a single call with WithLogger,
+// nil disables logging, must be set before mapping
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// WithLogger logs diagnostics of this call with the logger, instead of the logger set with SetLogger
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// loggers which log warnings as structured records, ie, the logger returned by NewSlogLogger
+type structuredLogger interface {
+	logWarning(dstTyp reflect.Type, w Warning)
+}
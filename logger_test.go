@@ -0,0 +1,41 @@
+package carta_test
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+// maps a row with an unmatched column onto blogs
+func mapWarned(t *testing.T, opts ...carta.Option) {
+	t.Helper()
+	blogs := []warnedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "post_id", "title", "extra"}, [][]interface{}{{int64(1), int64(1), "a", int64(0)}}, &blogs, opts...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	mapWarned(t, carta.WithLogger(log.New(&buf, "", 0)))
+	if expected := "carta: mapping onto *[]carta_test.warnedBlog: column extra does not match any field\n"; buf.String() != expected {
+		t.Fatalf("expected log %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	var def, call bytes.Buffer
+	carta.SetLogger(log.New(&def, "", 0))
+	defer carta.SetLogger(nil)
+	mapWarned(t)
+	if def.Len() == 0 {
+		t.Fatal("expected the warning to be logged with the default logger")
+	}
+	def.Reset()
+	mapWarned(t, carta.WithLogger(log.New(&call, "", 0)))
+	if def.Len() != 0 || call.Len() == 0 {
+		t.Fatalf("expected the warning to be logged with the logger of the call only, got %q and %q", def.String(), call.String())
+	}
+}
@@ -1,10 +1,14 @@
 package carta
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackskj/carta/value"
 )
@@ -34,6 +38,14 @@ type Field struct {
 	IsPtr    bool
 	ElemTyp  reflect.Type // if Typ is *int, elemTyp is int
 	ElemKind reflect.Kind // if kind is ptr and typ is *int, elem kind is int
+
+	tagged bool          // the name is set with a tag
+	opts   tagOptions    // options following the name in the tag
+	unit   time.Duration // unit of numeric columns loaded onto durations
+
+	lenientBool bool // parse text such as "Y" or "off" onto bools, set with the lenient tag option
+
+	nullValue *value.Cell // loaded in place of null columns, set with the null tag option, ie, `db:"status,null=UNSPECIFIED"`
 }
 
 type Mapper struct {
@@ -82,67 +94,425 @@ type Mapper struct {
 	// Nested structs which correspond to any has-one has-many relationships
 	// int is the ith element of this struct where the submap exists
 	SubMaps map[fieldIndex]*Mapper
+
+	path string // path of names of the ancestor fields in go, ie, "Author.Address", empty for the destination
+
+	isInitializer bool // pointers to the type implement Initializer
+
+	parent bool // collections loading columns are nested in the mapper, its rows are deduplicated even with WithoutDedup
+
+	// set on the root mapper only
+	dstTyp  reflect.Type // type of the destination
+	columns []string     // columns the mapper was planned with
+	opts    *options     // options of mappers built with NewMapper
+	typed   sync.Map     // mappers built with NewMapper planned again with the database types of the columns, by the types
+
+	unmatchedColumns []int    // indexes of columns which did not match any field
+	unmatchedFields  []string // paths of fields which did not match any column, ie, "Posts.Title"
+
+	mapKey fieldIndex // field keying the elements of map destinations
 }
 
 // Maps db rows onto the complex struct,
-// Response must be a struct, pointer to a struct for our response, a slice of structs or slice of pointers to a struct
-func Map(rows *sql.Rows, dst interface{}) error {
+// Response must be a struct, pointer to a struct for our response, a slice of structs or slice of pointers to a struct,
+// or a map of structs or of pointers to structs, ie, *map[int64]User, keyed by the field tagged with the pk option, or set with WithMapKey
+// destinations can also be pointers to basic types, ie, *int64, for results of a single row, which return ErrNoRows if there are no rows
+// entities are appended to slices and added to maps, unless WithReplace is set
+// opts tune the behavior of this call only
+func Map(rows RowsSource, dst interface{}, opts ...Option) error {
+	return MapContext(context.Background(), rows, dst, opts...)
+}
+
+// MapContext maps rows onto dst as Map does, ctx is checked before every row,
+// mapping stops with the error of ctx once it is canceled or its deadline is exceeded, rows are then closed
+func MapContext(ctx context.Context, rows RowsSource, dst interface{}, opts ...Option) error {
+	defer rows.Close()
+	return mapResultSet(ctx, rows, dst, newOptions(opts))
+}
+
+// MapMulti maps result sets of rows onto the destinations in order, ie, of stored procedures or batched statements,
+// advancing with rows.NextResultSet, the number of result sets must match the number of destinations,
+// sources of rows without a NextResultSet method have a single result set, example:
+//
+//	err := carta.MapMulti(rows, []interface{}{&blogs, &authors}, carta.WithStrictColumns())
+//
+// opts apply to every result set
+func MapMulti(rows RowsSource, dst []interface{}, opts ...Option) error {
+	defer rows.Close()
+	o := newOptions(opts)
+	sets, _ := rows.(resultSetSource)
+	for i := range dst {
+		if i != 0 && (sets == nil || !sets.NextResultSet()) {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("carta: rows have %d result sets, cannot map them onto %d destinations", i, len(dst))
+		}
+		if err := mapResultSet(context.Background(), rows, dst[i], o); err != nil {
+			return err
+		}
+	}
+	if sets != nil && sets.NextResultSet() {
+		return fmt.Errorf("carta: rows have more than %d result sets, pass a destination for each", len(dst))
+	}
+	return nil
+}
+
+// maps the current result set of rows onto dst, rows are not closed
+func mapResultSet(ctx context.Context, rows RowsSource, dst interface{}, o *options) (err error) {
 	var (
 		mapper *Mapper
-		err    error
-		rsv    *resolver
+		hit    bool
 	)
+	trace := startTrace(ctx, reflect.TypeOf(dst), o)
+	defer func() { trace.finish(err) }()
+	if err = validateDestination(dst); err != nil {
+		return err
+	}
 	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
-	columnTypes, err := rows.ColumnTypes()
+	columnTypes, err := columnTypeNames(rows, columns)
 	if err != nil {
 		return err
 	}
+	if mapper, hit, err = cachedMapper(reflect.TypeOf(dst), columns, columnTypes, o); err != nil {
+		return err
+	}
+	trace.mapper(mapper, hit)
+	return mapper.mapRows(ctx, trace.rows(rows), columnTypes, dst, o, trace)
+}
+
+// loads the mapper from the cache, or plans and caches it, mappers are cached by the names and database types of the columns,
+// so that columns of the same names but of other types, ie, after a schema change, are planned again,
+// hit reports whether the mapper was loaded from the cache
+func cachedMapper(dstTyp reflect.Type, columns []string, columnTypes []string, o *options) (mapper *Mapper, hit bool, err error) {
+	cache := o.mapperCache()
+	if cache == nil {
+		mapper, err = planMapper(dstTyp, columns, columnTypes, o)
+		return mapper, false, err
+	}
+	entry := mapperEntry{columns, columnTypes, dstTyp, o.key()}
+	key := entry.raw()
+	if mapper, ok := cache.loadMap(key); ok {
+		return mapper, true, nil
+	}
+	start := time.Now()
+	if mapper, err = planMapper(dstTyp, columns, columnTypes, o); err != nil {
+		return nil, false, err
+	}
+	cache.storeMap(key, entry, mapper, time.Since(start))
+	return mapper, false, nil
+}
+
+// MapSingle maps rows onto exactly one struct, dst must be a pointer to a struct,
+// unlike Map, which loads the first of many entities onto a struct destination, MapSingle returns ErrNoRows if there are no rows,
+// and ErrTooManyRows if rows map onto more than one struct after removing duplicates, dst is only set on success
+func MapSingle(rows RowsSource, dst interface{}, opts ...Option) error {
 	dstTyp := reflect.TypeOf(dst)
-	mapper, ok := mapperCache.loadMap(columns, dstTyp)
-	if !ok {
-		if !(isSlicePtr(dstTyp) || isStructPtr(dstTyp)) {
-			return fmt.Errorf("carta: cannot map rows onto %s, destination must be pointer to a slice(*[]) or pointer to a struct", dstTyp)
+	if dstTyp == nil || !isStructPtr(dstTyp) {
+		rows.Close()
+		return destinationError(dstTyp, "pointer to a struct")
+	}
+	if err := validateDestination(dst); err != nil {
+		rows.Close()
+		return err
+	}
+	all := reflect.New(reflect.SliceOf(dstTyp.Elem()))
+	if err := Map(rows, all.Interface(), opts...); err != nil {
+		return err
+	}
+	switch all.Elem().Len() {
+	case 0:
+		return ErrNoRows
+	case 1:
+		reflect.ValueOf(dst).Elem().Set(all.Elem().Index(0))
+		return nil
+	}
+	return ErrTooManyRows
+}
+
+// NewMapper builds the mapping of columns onto a destination ahead of time, ie, at startup, so that invalid tags fail fast,
+// and mapping on the hot path skips the cache lookup. dst is a pointer to a slice or to a struct, as passed to Map,
+// only its type is used, ie, (*[]Blog)(nil). Since types of the columns are not known until rows are mapped, the mapping is
+// planned again with the database types of the columns the first time rows of those types are mapped, so that converters
+// registered for specific column types, ie, with RegisterGeometry, are chosen over converters registered for any column.
+func NewMapper(dst interface{}, columns []string, opts ...Option) (*Mapper, error) {
+	o := newOptions(opts)
+	mapper, err := planMapper(reflect.TypeOf(dst), columns, nil, o)
+	if err != nil {
+		return nil, err
+	}
+	mapper.opts = o
+	return mapper, nil
+}
+
+// Map maps rows onto dst using a mapper built with NewMapper, rows must have the columns the mapper was built with,
+// and dst must be of the same type
+func (m *Mapper) Map(rows RowsSource, dst interface{}) error {
+	return m.MapContext(context.Background(), rows, dst)
+}
+
+// MapContext maps rows onto dst as Map does, ctx is checked before every row, as with carta.MapContext
+func (m *Mapper) MapContext(ctx context.Context, rows RowsSource, dst interface{}) (err error) {
+	defer rows.Close()
+	if m.opts == nil {
+		return errors.New("carta: mapper was not built with NewMapper")
+	}
+	trace := startTrace(ctx, reflect.TypeOf(dst), m.opts)
+	defer func() { trace.finish(err) }()
+	trace.mapper(m, true)
+	if dstTyp := reflect.TypeOf(dst); dstTyp != m.dstTyp {
+		return errorf(ErrTypeMismatch, "carta: cannot map rows onto %s with a mapper built for %s", dstTyp, m.dstTyp)
+	}
+	if err := validateDestination(dst); err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if strings.Join(columns, ",") != strings.Join(m.columns, ",") {
+		return fmt.Errorf("carta: columns %s do not match columns %s of the mapper", strings.Join(columns, ", "), strings.Join(m.columns, ", "))
+	}
+	columnTypes, err := columnTypeNames(rows, columns)
+	if err != nil {
+		return err
+	}
+	typed, err := m.withColumnTypes(columnTypes)
+	if err != nil {
+		return err
+	}
+	return typed.mapRows(ctx, trace.rows(rows), columnTypes, dst, m.opts, trace)
+}
+
+// mapper planned with the database types of the columns, which is the mapper itself if the types are not known
+func (m *Mapper) withColumnTypes(columnTypes []string) (*Mapper, error) {
+	if strings.Join(columnTypes, "") == "" {
+		return m, nil
+	}
+	key := strings.Join(columnTypes, ",")
+	if typed, ok := m.typed.Load(key); ok {
+		return typed.(*Mapper), nil
+	}
+	typed, err := planMapper(m.dstTyp, m.columns, columnTypes, m.opts)
+	if err != nil {
+		return nil, err
+	}
+	typed.opts = m.opts
+	actual, _ := m.typed.LoadOrStore(key, typed)
+	return actual.(*Mapper), nil
+}
+
+// Validate checks columns, ie, of a prepared statement, against a mapper built with NewMapper, before any query is run.
+// Returns an error listing columns which differ from the columns the mapper was built with, columns which do not match
+// any field, as well as fields which do not match any column
+func (m *Mapper) Validate(columns []string) error {
+	if m.opts == nil {
+		return errors.New("carta: mapper was not built with NewMapper")
+	}
+	planned, given := map[string]bool{}, map[string]bool{}
+	for _, c := range m.columns {
+		planned[c] = true
+	}
+	for _, c := range columns {
+		given[c] = true
+	}
+	problems := []string{}
+	if unknown := missingColumns(columns, planned); len(unknown) != 0 {
+		problems = append(problems, fmt.Sprintf("columns %s are not columns of the mapper", strings.Join(unknown, ", ")))
+	}
+	if missing := missingColumns(m.columns, given); len(missing) != 0 {
+		problems = append(problems, fmt.Sprintf("columns %s of the mapper are missing", strings.Join(missing, ", ")))
+	}
+	if len(problems) == 0 && strings.Join(columns, ",") != strings.Join(m.columns, ",") {
+		problems = append(problems, fmt.Sprintf("columns are not in the order of the mapper, %s", strings.Join(m.columns, ", ")))
+	}
+	if len(m.unmatchedColumns) != 0 {
+		unmatched := make([]string, len(m.unmatchedColumns))
+		for i, columnIndex := range m.unmatchedColumns {
+			unmatched[i] = m.columns[columnIndex]
 		}
+		problems = append(problems, fmt.Sprintf("columns %s do not match any field", strings.Join(unmatched, ", ")))
+	}
+	if len(m.unmatchedFields) != 0 {
+		problems = append(problems, fmt.Sprintf("fields %s do not match any column", strings.Join(m.unmatchedFields, ", ")))
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("carta: invalid mapping onto %s: %s", m.dstTyp, strings.Join(problems, "; "))
+	}
+	return nil
+}
 
-		// generate new mapper
-		if mapper, err = newMapper(dstTyp); err != nil {
-			return err
+// columns which are not in the set
+func missingColumns(columns []string, set map[string]bool) []string {
+	missing := []string{}
+	for _, c := range columns {
+		if !set[c] {
+			missing = append(missing, c)
 		}
+	}
+	return missing
+}
 
-		// determine field names
-		if err = determineFieldsNames(mapper); err != nil {
-			return err
+// plans the mapping of columns onto the type of the destination, columnTypes may be nil if types are not known
+func planMapper(dstTyp reflect.Type, columns []string, columnTypes []string, o *options) (*Mapper, error) {
+	var (
+		mapper *Mapper
+		err    error
+	)
+	if err = validateDestinationType(dstTyp); err != nil {
+		return nil, err
+	}
+	planTyp := dstTyp
+	if isScalarPtr(dstTyp) {
+		// scalars are planned as slices of their type
+		planTyp = reflect.PtrTo(reflect.SliceOf(dstTyp.Elem()))
+	} else if isMapPtr(dstTyp) {
+		// maps are planned as slices of their elements
+		elemTyp := dstTyp.Elem().Elem()
+		if !(elemTyp.Kind() == reflect.Struct || isStructPtr(elemTyp)) || isBasicType(elemTyp) {
+			return nil, &DestinationError{Type: dstTyp, Err: ErrUnsupportedDestination, expected: "pointer to a map of structs or of pointers to structs"}
 		}
+		planTyp = reflect.PtrTo(reflect.SliceOf(elemTyp))
+	}
 
-		// Allocate columns
+	// generate new mapper
+	if mapper, err = newMapper(planTyp, o); err != nil {
+		return nil, err
+	}
+	mapper.dstTyp = dstTyp
+	mapper.columns = columns
+
+	// determine field names
+	if err = determineFieldsNames(mapper, o); err != nil {
+		return nil, err
+	}
+
+	// Allocate columns
+	allColumns := make([]column, len(columns))
+	for i, columnName := range columns {
+		allColumns[i] = column{
+			name:        columnName,
+			columnIndex: i,
+		}
+		if columnTypes != nil {
+			allColumns[i].typ = columnTypes[i]
+		}
+	}
+	if o.ordinal {
+		for i := allocateOrdinalColumns(mapper, allColumns, 0); i < len(columns); i++ {
+			mapper.unmatchedColumns = append(mapper.unmatchedColumns, i)
+		}
+	} else {
 		columnsByName := map[string]column{}
-		for i, columnName := range columns {
-			columnsByName[columnName] = column{
-				name:        columnName,
-				typ:         columnTypes[i],
-				columnIndex: i,
-			}
+		for _, c := range allColumns {
+			columnsByName[c.name] = c
 		}
-		if err = allocateColumns(mapper, columnsByName); err != nil {
-			return err
+		if err = allocateColumns(mapper, columnsByName, o); err != nil {
+			return nil, err
 		}
+		for _, c := range columnsByName {
+			mapper.unmatchedColumns = append(mapper.unmatchedColumns, c.columnIndex)
+		}
+		sort.Ints(mapper.unmatchedColumns)
+	}
+	mapper.unmatchedFields = findUnmatchedFields(mapper, "")
+	markParents(mapper)
+	if isMapPtr(dstTyp) {
+		if mapper.mapKey, err = findMapKey(mapper, o); err != nil {
+			return nil, err
+		}
+	}
+	return mapper, nil
+}
 
-		mapperCache.storeMap(columns, dstTyp, mapper)
+// marks mappers which are parents of collections loading columns, at any depth, since rows of the same parent
+// must be loaded onto the same element for its collections to hold them, reports whether m, or its submaps, load columns
+func markParents(m *Mapper) bool {
+	loads := len(m.PresentColumns) != 0
+	for _, subMap := range m.SubMaps {
+		if !markParents(subMap) {
+			continue
+		}
+		loads = true
+		if subMap.Crd == Collection || subMap.parent {
+			m.parent = true
+		}
+	}
+	return loads
+}
 
+// finds the field keying map destinations, which is the field the column set with WithMapKey maps onto,
+// or the only field tagged with the pk option
+func findMapKey(m *Mapper, o *options) (fieldIndex, error) {
+	keys := []fieldIndex{}
+	for _, col := range m.PresentColumns {
+		if o.mapKey != "" && col.name == o.mapKey || o.mapKey == "" && m.Fields[col.i].opts.has("pk") {
+			keys = append(keys, col.i)
+		}
+	}
+	if len(keys) != 1 {
+		if o.mapKey != "" {
+			return 0, errorf(ErrInvalidDestination, "carta: cannot key %s, column %s does not map onto a field", m.dstTyp, o.mapKey)
+		}
+		return 0, errorf(ErrInvalidDestination, "carta: cannot key %s, exactly one mapped field must be tagged with the pk option, or the column set with WithMapKey", m.dstTyp)
 	}
+	// integers are convertible to strings as runes, which is never the intent
+	fieldTyp, keyTyp := m.Fields[keys[0]].Typ, m.dstTyp.Elem().Key()
+	if !fieldTyp.ConvertibleTo(keyTyp) || keyTyp.Kind() == reflect.String && fieldTyp.Kind() != reflect.String {
+		return 0, errorf(ErrTypeMismatch, "carta: cannot key %s with field %s of type %s", m.dstTyp, m.Typ.Field(int(keys[0])).Name, fieldTyp)
+	}
+	return keys[0], nil
+}
 
-	if rsv, err = mapper.loadRows(rows, columnTypes); err != nil {
+// loads rows onto the destination using the root mapper
+// trace observes the call, if it is traced
+func (m *Mapper) mapRows(ctx context.Context, rows RowsSource, columnTypes []string, dst interface{}, o *options, trace *mapTrace) error {
+	var (
+		rsv *resolver
+		err error
+	)
+	m.warn(o)
+	if err = m.checkMatched(o); err != nil {
 		return err
 	}
 
-	return setDst(mapper, reflect.ValueOf(dst), rsv)
+	if rsv, err = m.loadRows(ctx, rows, columnTypes, o, nil); err != nil {
+		return err
+	}
+	trace.entities(len(rsv.elementOrder))
+
+	if isScalarPtr(m.dstTyp) {
+		return setScalarDst(m, reflect.ValueOf(dst), rsv, o)
+	} else if isMapPtr(m.dstTyp) {
+		return setMapDst(m, reflect.ValueOf(dst), rsv, o)
+	} else if isSlicePtr(m.dstTyp) && o.replaceDst {
+		reflect.ValueOf(dst).Elem().Set(reflect.MakeSlice(m.dstTyp.Elem(), 0, len(rsv.elementOrder)))
+	}
+	return setDst(m, reflect.ValueOf(dst), rsv, o)
+}
 
+// fails if none of the columns matched, or if columns or fields did not match, when set with the strict options
+func (m *Mapper) checkMatched(o *options) error {
+	if len(m.columns) != 0 && len(m.unmatchedColumns) == len(m.columns) {
+		return errorf(ErrNoColumnsMatched, "carta: none of the columns %s match %s", strings.Join(m.columns, ", "), m.dstTyp)
+	}
+	if o.strictColumns && len(m.unmatchedColumns) != 0 {
+		unmatched := make([]string, len(m.unmatchedColumns))
+		for i, columnIndex := range m.unmatchedColumns {
+			unmatched[i] = m.columns[columnIndex]
+		}
+		return errorf(ErrUnmatchedColumn, "carta: columns %s do not match any field of %s", strings.Join(unmatched, ", "), m.dstTyp)
+	}
+	if o.strictFields && len(m.unmatchedFields) != 0 {
+		return errorf(ErrUnmatchedField, "carta: fields %s of %s do not match any column", strings.Join(m.unmatchedFields, ", "), m.dstTyp)
+	}
+	return nil
 }
 
-func newMapper(t reflect.Type) (*Mapper, error) {
+func newMapper(t reflect.Type, o *options) (*Mapper, error) {
 	var (
 		crd     Cardinality
 		elemTyp reflect.Type
@@ -183,7 +553,7 @@ func newMapper(t reflect.Type) (*Mapper, error) {
 	}
 
 	if crd == Unknown {
-		return nil, errors.New("carts: unknown mapping")
+		return nil, destinationError(t, mapDestinations)
 	}
 
 	mapper = &Mapper{
@@ -193,15 +563,17 @@ func newMapper(t reflect.Type) (*Mapper, error) {
 		Typ:       elemTyp,
 		Kind:      elemTyp.Kind(),
 		IsTypePtr: isTypePtr,
+
+		isInitializer: reflect.PtrTo(elemTyp).Implements(initializerType),
 	}
-	if subMaps, err = findSubMaps(mapper.Typ); err != nil {
+	if subMaps, err = findSubMaps(mapper.Typ, o); err != nil {
 		return nil, err
 	}
 	mapper.SubMaps = subMaps
 	return mapper, nil
 }
 
-func findSubMaps(t reflect.Type) (map[fieldIndex]*Mapper, error) {
+func findSubMaps(t reflect.Type, o *options) (map[fieldIndex]*Mapper, error) {
 	var (
 		subMap *Mapper
 		err    error
@@ -212,8 +584,8 @@ func findSubMaps(t reflect.Type) (map[fieldIndex]*Mapper, error) {
 	}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		if isExported(field) && isSubMap(field.Type) {
-			if subMap, err = newMapper(field.Type); err != nil {
+		if isExported(field) && !isIgnored(field, o) && isSubMap(field.Type) {
+			if subMap, err = newMapper(field.Type, o); err != nil {
 				return nil, err
 			}
 			subMaps[fieldIndex(i)] = subMap
@@ -222,7 +594,7 @@ func findSubMaps(t reflect.Type) (map[fieldIndex]*Mapper, error) {
 	return subMaps, nil
 }
 
-func determineFieldsNames(m *Mapper) error {
+func determineFieldsNames(m *Mapper, o *options) error {
 	var (
 		name string
 	)
@@ -234,17 +606,31 @@ func determineFieldsNames(m *Mapper) error {
 
 	for i := 0; i < m.Typ.NumField(); i++ {
 		field := m.Typ.Field(i)
-		if isExported(field) {
-			if tag := nameFromTag(field.Tag); tag != "" {
+		if isExported(field) && !isIgnored(field, o) {
+			tag, opts := parseTag(field.Tag, o.tagKey)
+			if tag != "" {
 				name = tag
 			} else {
 				name = field.Name
 			}
+			unit, err := opts.unit()
+			if err != nil {
+				return err
+			}
 			f := Field{
-				Name:  name,
-				Typ:   field.Type,
-				Kind:  field.Type.Kind(),
-				IsPtr: (field.Type.Kind() == reflect.Ptr),
+				Name:   name,
+				Typ:    field.Type,
+				Kind:   field.Type.Kind(),
+				IsPtr:  (field.Type.Kind() == reflect.Ptr),
+				tagged: tag != "",
+				opts:   opts,
+				unit:   unit,
+
+				lenientBool: opts.has("lenient"),
+			}
+			if null, ok := opts["null"]; ok {
+				f.nullValue = value.NewCell("")
+				f.nullValue.SetString(null)
 			}
 			if f.IsPtr {
 				f.ElemKind = field.Type.Elem().Kind()
@@ -255,20 +641,53 @@ func determineFieldsNames(m *Mapper) error {
 	}
 	m.Fields = fields
 	for _, subMap := range m.SubMaps {
-		if err := determineFieldsNames(subMap); err != nil {
+		if err := determineFieldsNames(subMap, o); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// finds the paths of basic fields, including the ones of nested structs, which did not match any column
+// path is the path of the mapper, empty for the root mapper
+func findUnmatchedFields(m *Mapper, path string) []string {
+	unmatched := []string{}
+	if m.IsBasic {
+		if len(m.PresentColumns) == 0 {
+			unmatched = append(unmatched, path)
+		}
+		return unmatched
+	}
+	claimed := map[fieldIndex]bool{}
+	for _, c := range m.PresentColumns {
+		claimed[c.i] = true
+	}
+	for i := 0; i < m.Typ.NumField(); i++ {
+		field, ok := m.Fields[fieldIndex(i)]
+		if !ok {
+			// unexported or ignored
+			continue
+		}
+		fieldPath := m.Typ.Field(i).Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		if subMap, ok := m.SubMaps[fieldIndex(i)]; ok {
+			unmatched = append(unmatched, findUnmatchedFields(subMap, fieldPath)...)
+		} else if isBasicType(field.Typ) && !claimed[fieldIndex(i)] {
+			unmatched = append(unmatched, fieldPath)
+		}
+	}
+	return unmatched
+}
+
 func isExported(f reflect.StructField) bool {
 	return (f.PkgPath == "")
 }
 
-func nameFromTag(t reflect.StructTag) string {
-	return t.Get(CartaTagKey)
-
+// fields tagged with "-", ie, `db:"-"`, are never mapped, even if a column with a matching name exists
+func isIgnored(f reflect.StructField, o *options) bool {
+	return f.Tag.Get(o.tagKey) == "-"
 }
 
 func isSubMap(t reflect.Type) bool {
@@ -280,10 +699,14 @@ func isSubMap(t reflect.Type) bool {
 
 // Basic types are any types that are intended to be set from sql row data
 // Primative fields, sql.NullXXX, time.Time, proto timestamp qualify as basic
+// as well as any type with a registered converter or implementing encoding.TextUnmarshaler or encoding.BinaryUnmarshaler
 func isBasicType(t reflect.Type) bool {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	if findConverter(t, "") != nil || isTextUnmarshaler(t) || isBinaryUnmarshaler(t) {
+		return true
+	}
 	if _, ok := value.BasicKinds[t.Kind()]; ok {
 		return true
 	}
@@ -301,3 +724,12 @@ func isStructPtr(t reflect.Type) bool {
 func isSlicePtr(t reflect.Type) bool {
 	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice
 }
+
+// pointers to basic types, ie, *int64, *time.Time or *sql.NullString
+func isScalarPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isBasicType(t.Elem())
+}
+
+func isMapPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Map
+}
@@ -0,0 +1,111 @@
+package carta_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type convertedId struct {
+	Via string
+}
+
+type withConvertedId struct {
+	Id convertedId
+}
+
+// rows of ids, with a database type
+type typedIdRows struct {
+	*idRows
+	typ string
+}
+
+func (r *typedIdRows) DatabaseTypeNames() ([]string, error) { return []string{r.typ}, nil }
+
+func TestNewMapperColumnTypes(t *testing.T) {
+	carta.RegisterConverter("", convertedId{}, func(src interface{}) (interface{}, error) {
+		return convertedId{"any"}, nil
+	})
+	carta.RegisterConverter("TYPED_ID", convertedId{}, func(src interface{}) (interface{}, error) {
+		return convertedId{"typed"}, nil
+	})
+	mapper, err := carta.NewMapper((*[]withConvertedId)(nil), []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		rows carta.RowsSource
+		via  string
+	}{
+		{&typedIdRows{newIdRows(1), "TYPED_ID"}, "typed"},
+		{newIdRows(1), "any"},
+		{&typedIdRows{newIdRows(1), "INT8"}, "any"},
+		{&typedIdRows{newIdRows(1), "TYPED_ID"}, "typed"},
+	} {
+		dst := []withConvertedId{}
+		if err := mapper.Map(test.rows, &dst); err != nil {
+			t.Fatal(err)
+		}
+		if len(dst) != 1 || dst[0].Id.Via != test.via {
+			t.Fatalf("expected the converter of %s columns, got %+v", test.via, dst)
+		}
+	}
+}
+
+func TestNewMapper(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]streamed)(nil), []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		dst := []streamed{}
+		if err := mapper.Map(newIdRows(3), &dst); err != nil {
+			t.Fatal(err)
+		}
+		if len(dst) != 3 || dst[2].Id != 2 {
+			t.Fatalf("expected 3 entities, got %+v", dst)
+		}
+	}
+	if err := mapper.Map(newIdRows(1), &[]withConvertedId{}); !errors.Is(err, carta.ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch mapping onto another type, got %v", err)
+	}
+	if _, err := carta.NewMapper([]streamed{}, []string{"id"}); !errors.Is(err, carta.ErrInvalidDestination) {
+		t.Fatalf("expected ErrInvalidDestination building a mapper of a slice, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	mapper, err := carta.NewMapper((*[]normalized)(nil), []string{"blog_id", "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.Validate([]string{"blog_id", "title"}); err != nil {
+		t.Fatalf("expected the columns of the mapper to be valid, got %v", err)
+	}
+	for _, test := range []struct {
+		columns []string
+		problem string
+	}{
+		{[]string{"blog_id", "title", "views"}, "columns views are not columns of the mapper"},
+		{[]string{"blog_id"}, "columns title of the mapper are missing"},
+		{[]string{"title", "blog_id"}, "columns are not in the order of the mapper"},
+	} {
+		if err := mapper.Validate(test.columns); err == nil || !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%v: expected %q, got %v", test.columns, test.problem, err)
+		}
+	}
+
+	unmatched, err := carta.NewMapper((*[]normalized)(nil), []string{"blog_id", "views"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = unmatched.Validate([]string{"blog_id", "views"})
+	if err == nil || !strings.Contains(err.Error(), "columns views do not match any field") || !strings.Contains(err.Error(), "fields Title do not match any column") {
+		t.Fatalf("expected unmatched columns and fields to be reported, got %v", err)
+	}
+	if err := (&carta.Mapper{}).Validate(nil); err == nil {
+		t.Fatal("expected an error validating a mapper which was not built with NewMapper")
+	}
+}
@@ -281,6 +281,27 @@ func TestPGTypes(m *testing.T) {
 	testResults["TestPGTypes"] = resp
 }
 
+func TestProtoOptional(m *testing.T) {
+	resp := []*td.OptionalTest{}
+	for _, query := range []string{td.NullQueryPG, td.NotNullQueryPG} {
+		if err := carta.Map(queryPG(query), &resp); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	respMySQL := []*td.OptionalTest{}
+	for _, query := range []string{td.NullQueryMySql, td.NotNullQueryMySQL} {
+		if err := carta.Map(queryMysql(query), &respMySQL); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	ansPG, _ := json.Marshal(resp)
+	ansMySQL, _ := json.Marshal(respMySQL)
+	if string(ansPG) != string(ansMySQL) {
+		log.Fatal(errors.New("Test Proto Optional Produced Inconsistent Results"))
+	}
+	testResults["TestProtoOptional"] = resp
+}
+
 func TestRelation(m *testing.T) {
 	ans := []byte{}
 	for _, rows := range query(td.RelationTestQuery) {
@@ -297,3 +318,58 @@ func TestRelation(m *testing.T) {
 		testResults["TestRelation"] = resp
 	}
 }
+
+func TestDescribeMapping(m *testing.T) {
+	columns := []string{"blog_id", "blog_title", "author_id", "post_id", "post_subject", "unknown"}
+	d, err := carta.DescribeMapping(&[]td.Blog{}, columns)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if len(d.Columns) != 2 || d.Columns[0].Field != "BlogId" || d.Columns[1].Column != "blog_title" {
+		m.Errorf("unexpected columns of Blog: %+v", d.Columns)
+	}
+	if len(d.Nested) != 2 {
+		m.Fatalf("expected Author and Posts to be nested, got %d", len(d.Nested))
+	}
+	if author := d.Nested[0]; author.Path != "Author" || author.Cardinality != carta.Association || len(author.Columns) != 1 {
+		m.Errorf("unexpected mapping of Author: %+v", author)
+	}
+	if posts := d.Nested[1]; posts.Path != "Posts" || posts.Cardinality != carta.Collection || len(posts.Columns) != 2 ||
+		posts.Columns[1].Field != "Posts.PostSubject" {
+		m.Errorf("unexpected mapping of Posts: %+v", posts)
+	}
+	if len(d.UnmatchedColumns) != 1 || d.UnmatchedColumns[0] != "unknown" {
+		m.Errorf("expected unknown to be unmatched, got %v", d.UnmatchedColumns)
+	}
+}
+
+func TestDestination(m *testing.T) {
+	var nilBlogs *[]td.Blog
+	tests := []struct {
+		dst interface{}
+		err error
+	}{
+		{nil, carta.ErrNilPointer},
+		{[]td.Blog{}, carta.ErrNotPointer},
+		{td.Blog{}, carta.ErrNotPointer},
+		{nilBlogs, carta.ErrNilPointer},
+		{(*td.Blog)(nil), carta.ErrNilPointer},
+		{&map[int]int{}, carta.ErrUnsupportedDestination},
+		{new(chan int), carta.ErrUnsupportedDestination},
+	}
+	for _, test := range tests {
+		err := carta.Map(queryPG(td.BlogQuery), test.dst)
+		if !errors.Is(err, test.err) || !errors.Is(err, carta.ErrInvalidDestination) {
+			m.Errorf("%T: expected %v, got %v", test.dst, test.err, err)
+		}
+		var e *carta.DestinationError
+		if !errors.As(err, &e) {
+			m.Errorf("%T: expected a DestinationError, got %T", test.dst, err)
+		}
+	}
+	for _, dst := range []interface{}{&[]td.Blog{}, &[]*td.Blog{}, &td.Blog{}} {
+		if err := carta.Map(queryPG(td.BlogQuery), dst); err != nil {
+			m.Errorf("%T: %s", dst, err)
+		}
+	}
+}
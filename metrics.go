@@ -0,0 +1,72 @@
+package carta
+
+import (
+	"expvar"
+	"reflect"
+	"sync"
+)
+
+// MetricsHook measures calls of Map, ie, to dashboard latency of mapping and efficiency of the cache per destination type,
+// see the cartaprom module for a collector of Prometheus, and NewExpvarMetrics. OnMapStart is called before the rows are read,
+// and OnMapDone once mapping ends, hooks are called concurrently by concurrent calls
+type MetricsHook interface {
+	OnMapStart(dst reflect.Type)
+	OnMapDone(stats MapStats)
+}
+
+// metricsHook is the metrics hook used by default, set with SetMetricsHook, calls are not measured by default
+var metricsHook MetricsHook
+
+// SetMetricsHook sets the metrics hook used by default, the hook can also be set for a single call with WithMetricsHook,
+// nil disables metrics, must be set before mapping
+func SetMetricsHook(h MetricsHook) {
+	metricsHook = h
+}
+
+// WithMetricsHook measures the call with the hook, instead of the hook set with SetMetricsHook
+func WithMetricsHook(h MetricsHook) Option {
+	return func(o *options) {
+		o.metrics = h
+	}
+}
+
+// NewExpvarMetrics returns a metrics hook publishing counters of calls with expvar, under the name, ie, "carta",
+// counters are keyed by the type of the destination, ie, {"*[]main.Blog": {"calls": 2, "rows": 10, ...}},
+// as calls, errors, rows, entities, cache_hits, cache_misses and duration_ns, which is the total duration of the calls.
+// It panics if the name is already published, as expvar.Publish does
+func NewExpvarMetrics(name string) MetricsHook {
+	return &expvarMetrics{vars: expvar.NewMap(name)}
+}
+
+type expvarMetrics struct {
+	mu   sync.Mutex
+	vars *expvar.Map // counters of every destination type are held in a map
+}
+
+func (e *expvarMetrics) OnMapStart(dst reflect.Type) {}
+
+func (e *expvarMetrics) OnMapDone(stats MapStats) {
+	name := "<nil>"
+	if stats.Destination != nil {
+		name = stats.Destination.String()
+	}
+	e.mu.Lock()
+	vars, ok := e.vars.Get(name).(*expvar.Map)
+	if !ok {
+		vars = new(expvar.Map).Init()
+		e.vars.Set(name, vars)
+	}
+	e.mu.Unlock()
+	vars.Add("calls", 1)
+	if stats.Err != nil {
+		vars.Add("errors", 1)
+	}
+	vars.Add("rows", int64(stats.Rows))
+	vars.Add("entities", int64(stats.Entities))
+	if stats.CacheHit {
+		vars.Add("cache_hits", 1)
+	} else {
+		vars.Add("cache_misses", 1)
+	}
+	vars.Add("duration_ns", int64(stats.Duration))
+}
@@ -0,0 +1,103 @@
+package carta_test
+
+import (
+	"expvar"
+	"reflect"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+// metrics hook recording its calls
+type metricsRecorder struct {
+	started []reflect.Type
+	done    []carta.MapStats
+}
+
+func (m *metricsRecorder) OnMapStart(dst reflect.Type) {
+	m.started = append(m.started, dst)
+}
+
+func (m *metricsRecorder) OnMapDone(stats carta.MapStats) {
+	m.done = append(m.done, stats)
+}
+
+func TestWithMetricsHook(t *testing.T) {
+	m := &metricsRecorder{}
+	dst := []cached{}
+	rows := [][]interface{}{{int64(1)}, {int64(1)}, {int64(2)}}
+	if err := carta.MapValues([]string{"id"}, rows, &dst, carta.WithMetricsHook(m), carta.WithCache(carta.NewCache())); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.started) != 1 || len(m.done) != 1 {
+		t.Fatalf("expected a start and an end of the call, got %d and %d", len(m.started), len(m.done))
+	}
+	typ := reflect.TypeOf(&dst)
+	if m.started[0] != typ || m.done[0].Destination != typ {
+		t.Fatalf("expected the destination %s, got %s and %s", typ, m.started[0], m.done[0].Destination)
+	}
+	if stats := m.done[0]; stats.Rows != 3 || stats.Entities != 2 || stats.CacheHit || stats.Err != nil {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSetMetricsHook(t *testing.T) {
+	m := &metricsRecorder{}
+	carta.SetMetricsHook(m)
+	defer carta.SetMetricsHook(nil)
+	dst := []cached{}
+	if err := carta.MapValues([]string{"id"}, [][]interface{}{{int64(1)}}, &dst); err != nil {
+		t.Fatal(err)
+	}
+	// the hook of the call is used instead of the default one
+	if err := carta.MapValues([]string{"id"}, [][]interface{}{{int64(1)}}, &dst, carta.WithMetricsHook(&metricsRecorder{})); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.done) != 1 {
+		t.Fatalf("expected the default hook to measure a call, got %d", len(m.done))
+	}
+}
+
+func TestNewExpvarMetrics(t *testing.T) {
+	hook := carta.NewExpvarMetrics("carta_test")
+	cache := carta.NewCache()
+	opts := []carta.Option{carta.WithMetricsHook(hook), carta.WithCache(cache)}
+	for i := 0; i < 2; i++ {
+		dst := []cached{}
+		rows := [][]interface{}{{int64(1)}, {int64(2)}}
+		if err := carta.MapValues([]string{"id"}, rows, &dst, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := []cached{}
+	err := carta.MapValues([]string{"id", "extra"}, [][]interface{}{{int64(1), int64(1)}}, &dst, append(opts, carta.WithStrictColumns())...)
+	if err == nil {
+		t.Fatal("expected an error of the unmatched column")
+	}
+
+	vars, ok := expvar.Get("carta_test").(*expvar.Map)
+	if !ok {
+		t.Fatal("expected the metrics to be published")
+	}
+	counters, ok := vars.Get("*[]carta_test.cached").(*expvar.Map)
+	if !ok {
+		t.Fatalf("expected counters of the destination, got %s", vars)
+	}
+	expected := map[string]int64{"calls": 3, "errors": 1, "rows": 4, "entities": 4, "cache_hits": 1, "cache_misses": 2}
+	for name, n := range expected {
+		v, ok := counters.Get(name).(*expvar.Int)
+		if !ok || v.Value() != n {
+			t.Errorf("expected %s to be %d, got %v", name, n, counters.Get(name))
+		}
+	}
+	if v, ok := counters.Get("duration_ns").(*expvar.Int); !ok || v.Value() <= 0 {
+		t.Errorf("expected the duration of the calls, got %v", counters.Get("duration_ns"))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected publishing the name twice to panic")
+		}
+	}()
+	carta.NewExpvarMetrics("carta_test")
+}
@@ -0,0 +1,69 @@
+package carta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+// result sets of ids, one after another
+type resultSets struct {
+	*idRows
+	sets []*idRows
+}
+
+func newResultSets(sizes ...int) *resultSets {
+	r := &resultSets{}
+	for _, n := range sizes {
+		r.sets = append(r.sets, newIdRows(n))
+	}
+	r.idRows = r.sets[0]
+	return r
+}
+
+func (r *resultSets) NextResultSet() bool {
+	if len(r.sets) == 1 {
+		return false
+	}
+	r.sets = r.sets[1:]
+	r.idRows = r.sets[0]
+	return true
+}
+
+type unmatched struct {
+	Id    int
+	Title string
+}
+
+func TestMapMultiOptions(t *testing.T) {
+	first, second := []streamed{}, []streamed{}
+	if err := carta.MapMulti(newResultSets(2, 3), []interface{}{&first, &second}); err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 || len(second) != 3 {
+		t.Fatalf("expected 2 and 3 entities, got %+v and %+v", first, second)
+	}
+	strict := []unmatched{}
+	if err := carta.MapMulti(newResultSets(1, 1), []interface{}{&first, &strict}, carta.WithStrictFields()); err == nil {
+		t.Fatal("expected the options to apply to the second result set")
+	}
+}
+
+func TestMapJoinedOptions(t *testing.T) {
+	ids := []streamed{{Id: -1}}
+	if err := carta.MapJoined(newIdRows(2), []interface{}{&ids}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected entities to be appended, got %+v", ids)
+	}
+	if err := carta.MapJoined(newIdRows(2), []interface{}{&ids}, carta.WithReplace()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected the slice to be replaced, got %+v", ids)
+	}
+	if err := carta.MapJoined(newIdRows(1), []interface{}{&[]unmatched{}}, carta.WithStrictFields()); err == nil {
+		t.Fatal("expected the options to apply to the destinations")
+	}
+}
@@ -0,0 +1,235 @@
+package carta
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholder is the style of the parameters of a driver, which named parameters are expanded to
+type Placeholder int
+
+const (
+	// QuestionPlaceholder is used by MySQL and SQLite, ie, "?"
+	QuestionPlaceholder Placeholder = iota
+	// DollarPlaceholder is used by PostgreSQL, ie, "$1"
+	DollarPlaceholder
+	// AtPlaceholder is used by SQL Server, ie, "@p1"
+	AtPlaceholder
+	// ColonPlaceholder is used by Oracle, ie, ":1"
+	ColonPlaceholder
+)
+
+// WithPlaceholder sets the style named parameters are expanded to by QueryNamed, QuestionPlaceholder by default
+func WithPlaceholder(p Placeholder) Option {
+	return func(o *options) {
+		o.placeholder = p
+	}
+}
+
+// QueryNamed runs the query on db as Query does, with named parameters, ie, ":author_id" or "@author_id",
+// which are taken from params, a map keyed by the names, or a struct, or pointer to a struct, whose fields match the names
+// as they match columns, example:
+//
+//	blogs := []Blog{}
+//	err := carta.QueryNamed(ctx, db, "select * from blog where author_id = :author_id", Filter{AuthorId: 1}, &blogs,
+//		carta.WithPlaceholder(carta.DollarPlaceholder))
+//
+// parameters are expanded to placeholders of the style set with WithPlaceholder, names in quotes, dollar quoted strings of PostgreSQL, ie, "$$:n$$", and comments, casts, ie, "::text",
+// slices of arrays, ie, "tags[1:n]", and variables of SQL Server, ie, "@@ROWCOUNT", are left as they are
+func QueryNamed(ctx context.Context, db Querier, query string, params interface{}, dst interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	values, err := namedValues(params, o)
+	if err != nil {
+		return err
+	}
+	query, args, err := expandNamed(query, values, o.placeholder)
+	if err != nil {
+		return err
+	}
+	return Query(ctx, db, query, args, dst, opts...)
+}
+
+// values of named parameters keyed by their names, fields of structs are keyed by the column names they match
+func namedValues(params interface{}, o *options) (map[string]interface{}, error) {
+	if m, ok := params.(map[string]interface{}); ok {
+		return m, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(params))
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		values := map[string]interface{}{}
+		for _, key := range v.MapKeys() {
+			values[key.String()] = v.MapIndex(key).Interface()
+		}
+		return values, nil
+	case v.Kind() == reflect.Struct:
+		values := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !isExported(field) || isIgnored(field, o) {
+				continue
+			}
+			name, _ := parseTag(field.Tag, o.tagKey)
+			if name == "" {
+				name = field.Name
+			}
+			for candidate := range getColumnNameCandidates(name, nil, o) {
+				if _, ok := values[candidate]; !ok {
+					values[candidate] = v.Field(i).Interface()
+				}
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("carta: cannot read named parameters from %T, params must be a map or a struct", params)
+}
+
+// expands named parameters of the query to placeholders, returns the expanded query and the arguments in order,
+// parameters are read up to the first character which is not a letter, digit or "_"
+func expandNamed(query string, values map[string]interface{}, p Placeholder) (string, []interface{}, error) {
+	var (
+		b         strings.Builder
+		args      []interface{}
+		quote     byte
+		subscript int                // depth of brackets of subscripts of arrays, ie, "tags[1:n]"
+		indexes   = map[string]int{} // positions of parameters already expanded, reused by numbered placeholders
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && dollarQuote(query, i) != "":
+			// dollar quoted string of PostgreSQL, ie, "$$:n$$" or "$body$:n$body$"
+			tag := dollarQuote(query, i)
+			end := strings.Index(query[i+len(tag):], tag)
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 2 * len(tag)
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case strings.HasPrefix(query[i:], "--"):
+			// comment up to the end of the line
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		case c == '[' && (subscript != 0 || isSubscript(query[:i])):
+			subscript++
+		case c == ']' && subscript != 0:
+			subscript--
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			// cast, ie, "::text"
+			b.WriteString("::")
+			i++
+			continue
+		case c == '@' && i+1 < len(query) && query[i+1] == '@':
+			// variable of SQL Server, ie, "@@ROWCOUNT"
+			j := i + 2
+			for j < len(query) && isNamePart(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j - 1
+			continue
+		case (c == ':' && subscript == 0 || c == '@') && i+1 < len(query) && isNameStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isNamePart(query[j]) {
+				j++
+			}
+			name := query[i+1 : j]
+			v, ok := values[name]
+			if !ok {
+				return "", nil, fmt.Errorf("carta: named parameter %s is missing", name)
+			}
+			if index, ok := indexes[name]; ok && p != QuestionPlaceholder {
+				b.WriteString(p.format(index))
+			} else {
+				args = append(args, v)
+				indexes[name] = len(args)
+				b.WriteString(p.format(len(args)))
+			}
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), args, nil
+}
+
+// formats the placeholder of the nth argument, starting from 1
+func (p Placeholder) format(n int) string {
+	switch p {
+	case DollarPlaceholder:
+		return "$" + strconv.Itoa(n)
+	case AtPlaceholder:
+		return "@p" + strconv.Itoa(n)
+	case ColonPlaceholder:
+		return ":" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// whether a bracket following the query is a subscript, ie, "tags[", "tags[1][" or "(tags)[", rather than an array, ie, "array["
+func isSubscript(query string) bool {
+	if query == "" {
+		return false
+	}
+	if c := query[len(query)-1]; c == ']' || c == ')' {
+		return true
+	}
+	j := len(query)
+	for j > 0 && isNamePart(query[j-1]) {
+		j--
+	}
+	return j != len(query) && !strings.EqualFold(query[j:], "array")
+}
+
+// tag opening a dollar quoted string at i, ie, "$$" or "$body$", empty if there is none,
+// positional parameters, ie, "$1", and dollar signs within identifiers, ie, "a$b", do not open strings
+func dollarQuote(query string, i int) string {
+	if i > 0 && (isNamePart(query[i-1]) || query[i-1] == '$') {
+		return ""
+	}
+	j := i + 1
+	if j < len(query) && isNameStart(query[j]) {
+		for j < len(query) && isNamePart(query[j]) {
+			j++
+		}
+	}
+	if j >= len(query) || query[j] != '$' {
+		return ""
+	}
+	return query[i : j+1]
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
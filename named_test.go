@@ -0,0 +1,83 @@
+package carta_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestQueryNamed(t *testing.T) {
+	params := map[string]interface{}{"id": 1, "n": 2}
+	tests := []struct {
+		query    string
+		expanded string
+		args     []interface{}
+	}{
+		{"select * from blog where id = :id", "select * from blog where id = $1", []interface{}{1}},
+		{"select * from blog where id = @id or parent = :id", "select * from blog where id = $1 or parent = $1", []interface{}{1}},
+		{"select ':id', \"@id\", id::text from blog", "select ':id', \"@id\", id::text from blog", nil},
+		{"select * from blog -- :n is not a parameter\nwhere id = :id", "select * from blog -- :n is not a parameter\nwhere id = $1", []interface{}{1}},
+		{"select * from blog /* :n, @n */ where id = :id", "select * from blog /* :n, @n */ where id = $1", []interface{}{1}},
+		{"select * from blog /* unterminated :n", "select * from blog /* unterminated :n", nil},
+		{"select tags[1:n], tags[:n], (tags)[id:n][1] from blog where id = :id", "select tags[1:n], tags[:n], (tags)[id:n][1] from blog where id = $1", []interface{}{1}},
+		{"select tags[:n] from blog where id = any(ARRAY[:id])", "select tags[:n] from blog where id = any(ARRAY[$1])", []interface{}{1}},
+		{"select * from blog where id in (:id, :n)", "select * from blog where id in ($1, $2)", []interface{}{1, 2}},
+		{"update blog set n = :n where id = :id; select @@ROWCOUNT", "update blog set n = $1 where id = $2; select @@ROWCOUNT", []interface{}{2, 1}},
+		{"select $$it's :n$$, id from blog where id = :id", "select $$it's :n$$, id from blog where id = $1", []interface{}{1}},
+		{"select $body$ :n $$ @n $body$ from blog where id = :id", "select $body$ :n $$ @n $body$ from blog where id = $1", []interface{}{1}},
+		{"select $a$ :n $b$ :id $a$, :n", "select $a$ :n $b$ :id $a$, $1", []interface{}{2}},
+		{"select a$b, $1 from blog where id = :id", "select a$b, $1 from blog where id = $1", []interface{}{1}},
+		{"select $$ unterminated :n", "select $$ unterminated :n", nil},
+	}
+	for _, test := range tests {
+		q := &recordingQuerier{}
+		err := carta.QueryNamed(context.Background(), q, test.query, params, &[]normalized{}, carta.WithPlaceholder(carta.DollarPlaceholder))
+		if err != errRecorded {
+			t.Fatalf("expected the error of the querier for %q, got %v", test.query, err)
+		}
+		if q.query != test.expanded || !reflect.DeepEqual(q.args, test.args) {
+			t.Errorf("%q is expanded to %q %v, expected %q %v", test.query, q.query, q.args, test.expanded, test.args)
+		}
+	}
+}
+
+type blogFilter struct {
+	AuthorId int
+	Title    string `db:"name"`
+	ignored  int
+}
+
+func TestQueryNamedParams(t *testing.T) {
+	tests := []struct {
+		params      interface{}
+		placeholder carta.Placeholder
+		expanded    string
+		args        []interface{}
+	}{
+		{blogFilter{AuthorId: 1, Title: "blog"}, carta.QuestionPlaceholder, "select * from blog where author_id = ? and (name = ? or ? = 0)", []interface{}{1, "blog", 1}},
+		{&blogFilter{AuthorId: 1, Title: "blog"}, carta.AtPlaceholder, "select * from blog where author_id = @p1 and (name = @p2 or @p1 = 0)", []interface{}{1, "blog"}},
+		{map[string]interface{}{"author_id": 1, "name": "blog"}, carta.ColonPlaceholder, "select * from blog where author_id = :1 and (name = :2 or :1 = 0)", []interface{}{1, "blog"}},
+		{map[string]string{"author_id": "1", "name": "blog"}, carta.DollarPlaceholder, "select * from blog where author_id = $1 and (name = $2 or $1 = 0)", []interface{}{"1", "blog"}},
+	}
+	query := "select * from blog where author_id = :author_id and (name = :name or @author_id = 0)"
+	for _, test := range tests {
+		q := &recordingQuerier{}
+		err := carta.QueryNamed(context.Background(), q, query, test.params, &[]normalized{}, carta.WithPlaceholder(test.placeholder))
+		if err != errRecorded {
+			t.Fatalf("expected the error of the querier for %T, got %v", test.params, err)
+		}
+		if q.query != test.expanded || !reflect.DeepEqual(q.args, test.args) {
+			t.Errorf("params %T are expanded to %q %v, expected %q %v", test.params, q.query, q.args, test.expanded, test.args)
+		}
+	}
+
+	q := &recordingQuerier{}
+	if err := carta.QueryNamed(context.Background(), q, "select * from blog where id = :id", blogFilter{}, &[]normalized{}); err == nil || err == errRecorded {
+		t.Fatalf("expected an error for a missing parameter, got %v", err)
+	}
+	if err := carta.QueryNamed(context.Background(), q, "select * from blog where id = :id", []int{1}, &[]normalized{}); err == nil || err == errRecorded {
+		t.Fatalf("expected an error for params which are not a map or a struct, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package carta
+
+import "reflect"
+
+// NamingStrategy derives a column name from the name of a field, ie, "first_name" from FirstName,
+// fields match columns with their names as they are, in lower case, and as derived by the strategy
+type NamingStrategy interface {
+	ColumnName(fieldName string) string
+}
+
+// SnakeCase is the default naming strategy, which converts camel case names to snake case, ie, BlogId to blog_id
+type SnakeCase struct{}
+
+func (SnakeCase) ColumnName(fieldName string) string {
+	return toSnakeCase(fieldName)
+}
+
+// naming is the naming strategy used by default, set with SetNamingStrategy
+var naming NamingStrategy = SnakeCase{}
+
+// SetNamingStrategy sets the naming strategy used by default, the strategy can also be set for a single call with WithNamingStrategy,
+// must be set before mapping, mappers planned with the previous strategy are removed from caches
+func SetNamingStrategy(s NamingStrategy) {
+	naming = s
+	invalidateCaches()
+}
+
+// FieldNameResolver returns the names of columns which a field matches, replacing the default matching,
+// parent holds the names of the ancestor fields joined with ".", ie, "Blog.Author", and is empty for fields of the destination.
+// Useful for unusual column conventions, ie, "tbl$col"
+type FieldNameResolver func(field reflect.StructField, parent string) []string
+
+// fieldNameResolver is the field name resolver used by default, set with SetFieldNameResolver
+var fieldNameResolver FieldNameResolver
+
+// SetFieldNameResolver sets the field name resolver used by default, the resolver can also be set for a single call with
+// WithFieldNameResolver, nil restores the default matching, must be set before mapping, mappers planned with the previous resolver
+// are removed from caches
+func SetFieldNameResolver(r FieldNameResolver) {
+	fieldNameResolver = r
+	invalidateCaches()
+}
@@ -0,0 +1,117 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackskj/carta/value"
+)
+
+// RegisterNested registers a conversion onto fields of the type of dst, a struct, or a slice of structs or of pointers to structs,
+// from columns of nested records, ie, STRUCT and LIST columns of DuckDB, or record columns of BigQuery and Spanner,
+// which are loaded as JSON, so that they map onto nested structs without joins, example:
+//
+//	// select id, address, posts from blog, where address is STRUCT(street VARCHAR, city VARCHAR), and posts is a LIST of STRUCTs
+//	carta.RegisterNested(Address{})
+//	carta.RegisterNested([]Post{})
+//
+// keys of the records match fields of the structs as columns do, ie, by tags or by snake case names,
+// elements of lists are not deduplicated, and records nested in records must have their types registered as well.
+// Unexported fields are not loaded, and methods of the structs, ie, AfterMap, are not called. Registering a type resets cached mappers
+func RegisterNested(dst interface{}) {
+	t := reflect.TypeOf(dst)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	elemTyp := t
+	if t.Kind() == reflect.Slice {
+		elemTyp = t.Elem()
+	}
+	structTyp := elemTyp
+	if structTyp.Kind() == reflect.Ptr {
+		structTyp = structTyp.Elem()
+	}
+	if structTyp.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("carta: cannot register %s as nested, it must be a struct, or a slice of structs or of pointers to structs", t))
+	}
+
+	// records are mapped onto an unnamed struct of the exported fields, which is not registered, and copied to the registered type
+	shadow := newShadowStruct(structTyp)
+	shadowElemTyp := shadow.typ
+	if elemTyp.Kind() == reflect.Ptr {
+		shadowElemTyp = reflect.PtrTo(shadow.typ)
+	}
+	registerConverter(t, "", func(c *value.Cell) (interface{}, error) {
+		text, err := c.String()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.TrimSpace(text), "{") {
+			text = "[" + text + "]"
+		}
+		columns, rows, err := readJSONRows(strings.NewReader(text))
+		if err != nil {
+			return nil, err
+		}
+		records := reflect.New(reflect.SliceOf(shadowElemTyp))
+		if err = MapValues(columns, rows, records.Interface(), WithoutDedup()); err != nil {
+			return nil, err
+		}
+		records = records.Elem()
+		if t.Kind() != reflect.Slice {
+			if records.Len() != 1 {
+				return nil, fmt.Errorf("carta: cannot load %d records onto %s", records.Len(), t)
+			}
+			return shadow.copy(records.Index(0), t).Interface(), nil
+		}
+		v := reflect.MakeSlice(t, records.Len(), records.Len())
+		for i := 0; i < records.Len(); i++ {
+			if elemTyp.Kind() == reflect.Ptr && records.Index(i).IsNil() {
+				continue
+			}
+			v.Index(i).Set(shadow.copy(records.Index(i), elemTyp))
+		}
+		return v.Interface(), nil
+	})
+}
+
+// unnamed struct with the exported fields of a struct, embedded structs are plain fields of the shadow,
+// as reflect.StructOf cannot embed every type
+type shadowStruct struct {
+	typ    reflect.Type
+	fields []int // indexes of the fields of the shadow in the struct
+}
+
+func newShadowStruct(t reflect.Type) *shadowStruct {
+	s := &shadowStruct{}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isExported(f) {
+			continue
+		}
+		fields = append(fields, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag})
+		s.fields = append(s.fields, i)
+	}
+	s.typ = reflect.StructOf(fields)
+	return s
+}
+
+// value of typ, the struct or a pointer to it, with the fields of v, a shadow or a pointer to it
+func (s *shadowStruct) copy(v reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Ptr {
+		dst := reflect.New(typ.Elem())
+		s.copyFields(v.Elem(), dst.Elem())
+		return dst
+	}
+	dst := reflect.New(typ).Elem()
+	s.copyFields(v, dst)
+	return dst
+}
+
+func (s *shadowStruct) copyFields(src, dst reflect.Value) {
+	for j, i := range s.fields {
+		dst.Field(i).Set(src.Field(j))
+	}
+}
@@ -0,0 +1,115 @@
+package carta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type nestedAddress struct {
+	Street string
+	City   string `db:"town"`
+}
+
+type nestedPost struct {
+	Id    int
+	Title *string
+}
+
+type nestedComment struct {
+	Text string
+}
+
+type NestedAudit struct {
+	Author string
+}
+
+type nestedDraft struct {
+	Draft bool
+}
+
+// record with embedded structs, fields of unexported structs and unexported fields are never loaded
+type nestedRevision struct {
+	NestedAudit
+	nestedDraft
+	Number int
+	secret string
+}
+
+type nestedBlog struct {
+	Id        int
+	Address   nestedAddress
+	Posts     []nestedPost
+	Comments  []*nestedComment
+	Revisions []nestedRevision
+}
+
+func init() {
+	carta.RegisterNested(nestedAddress{})
+	carta.RegisterNested([]nestedPost{})
+	carta.RegisterNested([]*nestedComment{})
+	carta.RegisterNested([]nestedRevision{})
+}
+
+func TestRegisterNested(t *testing.T) {
+	columns := []string{"id", "address", "posts", "comments", "revisions"}
+	rows := [][]interface{}{
+		{
+			int64(1),
+			`{"street": "Main", "town": "Springfield"}`,
+			`[{"id": 1, "title": "first"}, {"id": 1, "title": null}]`,
+			`[{"text": "nice"}, {"text": "nice"}]`,
+			`[{"author": "ann", "number": 2, "draft": true, "secret": "x"}]`,
+		},
+		{int64(2), []byte(`{"street": "Elm"}`), `[]`, `[]`, `[]`},
+	}
+	blogs := []nestedBlog{}
+	if err := carta.MapValues(columns, rows, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected 2 blogs, got %+v", blogs)
+	}
+	first := blogs[0]
+	if first.Address != (nestedAddress{Street: "Main", City: "Springfield"}) {
+		t.Errorf("expected the address loaded from the STRUCT column, got %+v", first.Address)
+	}
+	// elements of lists are not deduplicated
+	if len(first.Posts) != 2 || first.Posts[0].Id != 1 || first.Posts[0].Title == nil || *first.Posts[0].Title != "first" ||
+		first.Posts[1].Id != 1 || first.Posts[1].Title != nil {
+		t.Errorf("expected the posts loaded from the LIST column, got %+v", first.Posts)
+	}
+	if len(first.Comments) != 2 || first.Comments[0] == nil || first.Comments[1] == nil ||
+		first.Comments[0] == first.Comments[1] || first.Comments[1].Text != "nice" {
+		t.Errorf("expected pointers to distinct comments, got %+v", first.Comments)
+	}
+	if len(first.Revisions) != 1 || first.Revisions[0].Author != "ann" || first.Revisions[0].Number != 2 || first.Revisions[0].Draft || first.Revisions[0].secret != "" {
+		t.Errorf("expected fields of the embedded struct to be loaded, and unexported fields not to be, got %+v", first.Revisions)
+	}
+	second := blogs[1]
+	if second.Address != (nestedAddress{Street: "Elm"}) || len(second.Posts) != 0 || len(second.Comments) != 0 {
+		t.Errorf("unexpected second blog %+v", second)
+	}
+}
+
+func TestRegisterNestedErrors(t *testing.T) {
+	columns := []string{"id", "address"}
+	for _, address := range []string{`[{"street": "Main"}, {"street": "Elm"}]`, `{"street": `} {
+		blogs := []struct {
+			Id      int
+			Address nestedAddress
+		}{}
+		if err := carta.MapValues(columns, [][]interface{}{{int64(1), address}}, &blogs); err == nil {
+			t.Errorf("expected an error loading %s onto a record, got %+v", address, blogs)
+		}
+	}
+}
+
+func TestRegisterNestedNotStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a slice of strings to panic")
+		}
+	}()
+	carta.RegisterNested([]string{})
+}
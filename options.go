@@ -0,0 +1,280 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Option configures a single call to Map, ie, carta.Map(rows, &blogs, opts...)
+type Option func(*options)
+
+// options of a single call to Map, the zero value is the default behavior
+// options which change how a mapper is planned must be part of the cache key
+type options struct {
+	tagKey     string // key of the struct tags naming the columns
+	autoPrefix bool   // fields of nested structs only match columns prefixed with their ancestors
+	noDedup    bool   // every row is loaded as a new element, without removing duplicates
+	replaceDst bool   // slices and maps are replaced with new ones holding only the mapped entities, instead of appending to them
+
+	strictColumns bool // fail if any column does not match a field
+	strictFields  bool // fail if any field does not match a column
+
+	naming    NamingStrategy           // derives column names from names of fields
+	exactCase bool                     // names are matched only as they are, without lower or snake case variants
+	tagsOnly  bool                     // only fields with tags match columns
+	normalize func(name string) string // applied to both column names and names of fields before matching
+	uncached  bool                     // plans depend on functions set with options, which cannot be told apart in cache keys
+	funcsKey  string                   // identifies the functions set with options, so that plans depending on them are cached
+
+	resolveNames FieldNameResolver // replaces the default matching of fields
+
+	aliases map[string]string // paths of fields keyed by the column names which map onto them
+	ordinal bool              // columns map onto fields by position
+
+	mapKey string // column keying map destinations
+	column string // column loaded onto basic destinations
+
+	placeholder Placeholder // style named parameters of QueryNamed are expanded to
+	oracle      bool        // numbers arriving as text are loaded as numbers, and identifiers are upper cased, as by Oracle
+
+	afterMap  func(entity interface{}) error                          // called with every mapped entity of the destination
+	transform func(column string, v interface{}) (interface{}, error) // called with every value before it is loaded
+
+	warnings *[]Warning // unmatched columns and fields are appended
+	logger   Logger     // logs diagnostics
+
+	cache   *Cache // mappers are cached in, instead of the global cache
+	noCache bool   // mappers are neither loaded from nor stored in a cache
+
+	timeLocation *time.Location // time data is converted to, instead of the location set with SetTimeLocation
+
+	csvNull *string // fields of csv records loaded as null
+	unnest  bool    // a row is loaded for each element of array columns
+
+	tracer  Tracer      // traces calls of Map
+	metrics MetricsHook // measures calls of Map
+}
+
+// WithTagKey reads struct tags with the key, ie, "sql" or "json", instead of "db"
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.tagKey = key
+	}
+}
+
+// WithAutoPrefix makes fields of nested structs only match columns prefixed with the names of the parent fields,
+// separated by "_" or ".", ie, City of the Address field matches "address_city", "address.city" or "Address_City", but not "city"
+func WithAutoPrefix() Option {
+	return func(o *options) {
+		o.autoPrefix = true
+	}
+}
+
+// WithoutDedup loads every row as a new element instead of removing duplicate rows,
+// which is faster for flat result sets with one row per struct, and keeps rows which are legitimately identical.
+// Structs nesting collections are still deduplicated, since their rows must be grouped for the collections to hold them,
+// only elements of the innermost collections, ie, posts of []Blog with Posts []Post, are loaded for every row
+func WithoutDedup() Option {
+	return func(o *options) {
+		o.noDedup = true
+	}
+}
+
+// WithReplace replaces slice and map destinations with new ones holding only the mapped entities, ie, to reuse a destination
+// for every page of results, by default, entities are appended to slices and added to maps
+func WithReplace() Option {
+	return func(o *options) {
+		o.replaceDst = true
+	}
+}
+
+// WithStrictColumns fails the mapping if any column of the result does not match a field of the destination,
+// which catches typos in queries and tags, the error wraps ErrUnmatchedColumn
+func WithStrictColumns() Option {
+	return func(o *options) {
+		o.strictColumns = true
+	}
+}
+
+// WithStrictFields fails the mapping if any exported field of the destination, including fields of nested structs,
+// does not match a column, which keeps queries and structs in lockstep, use the "-" tag to exclude fields,
+// the error wraps ErrUnmatchedField
+func WithStrictFields() Option {
+	return func(o *options) {
+		o.strictFields = true
+	}
+}
+
+// WithExactCase matches columns only with names of fields as they are, without their lower or snake case variants,
+// ie, field BlogId only matches column "BlogId", and `db:"blog_id"` only matches "blog_id"
+func WithExactCase() Option {
+	return func(o *options) {
+		o.exactCase = true
+	}
+}
+
+// WithTagsOnly matches columns only with fields which name their column with a tag, other fields are not loaded
+func WithTagsOnly() Option {
+	return func(o *options) {
+		o.tagsOnly = true
+	}
+}
+
+// WithNameNormalizer applies normalize to both column names and names of fields before matching them,
+// ie, strings.ToUpper to match upper case columns returned by Oracle. Since closures of the same function cannot be told apart,
+// mappers planned with a normalizer are not cached, build a mapper with NewMapper to reuse it
+func WithNameNormalizer(normalize func(name string) string) Option {
+	return func(o *options) {
+		o.normalize = normalize
+		o.uncached = true
+	}
+}
+
+// WithOracle adapts carta to Oracle and godror, columns match fields regardless of case, since Oracle upper cases names
+// of columns, ie, BLOG_ID matches BlogId, and NUMBER columns, which godror returns as godror.Number, are loaded as int64,
+// or as float64 if they are not integral, ie, as passed to converters and value transformers,
+// numeric fields parse them regardless of this option.
+// Named parameters are expanded to the style of Oracle, ie, ":1", and CheckTable reads columns from all_tab_columns,
+// with names of tables upper cased, unless quoted, ie, "blog" is looked up as BLOG, and `"blog"` as blog
+func WithOracle() Option {
+	return func(o *options) {
+		o.normalize = strings.ToLower
+		o.oracle = true
+		o.placeholder = ColonPlaceholder
+	}
+}
+
+// normalizeName applies the normalizer set with WithNameNormalizer, if any
+func (o *options) normalizeName(name string) string {
+	if o.normalize == nil {
+		return name
+	}
+	return o.normalize(name)
+}
+
+// WithNamingStrategy derives column names from names of fields with the strategy, instead of converting them to snake case,
+// mappers planned with strategies which are functions, or hold functions, are not cached, since closures cannot be told apart
+func WithNamingStrategy(s NamingStrategy) Option {
+	return func(o *options) {
+		o.naming = s
+		if !isComparable(reflect.TypeOf(s)) {
+			o.uncached = true
+		}
+	}
+}
+
+// whether values of the type identify themselves in cache keys, ie, are comparable and are not functions
+func isComparable(t reflect.Type) bool {
+	if t == nil || t.Kind() == reflect.Func {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		return isComparable(t.Elem())
+	}
+	return t.Comparable()
+}
+
+// WithFieldNameResolver matches fields with the names of columns returned by the resolver, instead of the default matching,
+// since closures of the same function cannot be told apart, mappers planned with a resolver are not cached,
+// build a mapper with NewMapper, or pass WithCacheKey, to reuse it
+func WithFieldNameResolver(r FieldNameResolver) Option {
+	return func(o *options) {
+		o.resolveNames = r
+		o.uncached = true
+	}
+}
+
+// WithCacheKey identifies the functions set with other options, ie, with WithFieldNameResolver or WithNameNormalizer,
+// so that mappers planned with them are cached under the key, instead of being planned on every call,
+// calls passing the same key must pass functions which match the same columns, ie, carta.WithCacheKey("gorm")
+func WithCacheKey(key string) Option {
+	return func(o *options) {
+		o.funcsKey = key
+	}
+}
+
+// WithColumnAliases maps columns onto the fields at the paths, ie, map[string]string{"u_name": "User.Name"},
+// paths consist of names of fields in go, relative to the struct being mapped, aliased columns only map onto their fields,
+// which allows mapping aliases of a specific query without changing tags shared across queries
+func WithColumnAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		if o.aliases == nil {
+			o.aliases = map[string]string{}
+		}
+		for column, path := range aliases {
+			o.aliases[column] = path
+		}
+	}
+}
+
+// matchAlias determines whether a column maps onto the field at the path, aliased columns only map onto their fields,
+// other columns map if their name matched
+func (o *options) matchAlias(column string, path string, matched bool) bool {
+	if alias, ok := o.aliases[column]; ok {
+		return alias == path
+	}
+	return matched
+}
+
+// WithOrdinal maps columns onto fields by position instead of by name, in the order the fields are declared,
+// fields of nested structs take the position of the nested struct, which helps with queries returning empty or repeated column names
+func WithOrdinal() Option {
+	return func(o *options) {
+		o.ordinal = true
+	}
+}
+
+// WithMapKey keys map destinations, ie, *map[int64]User, with the field the column maps onto,
+// instead of the field tagged with the pk option
+func WithMapKey(column string) Option {
+	return func(o *options) {
+		o.mapKey = column
+	}
+}
+
+// WithColumn loads the column onto destinations of basic types, ie, *[]int64, which is only needed if there is more than one column
+func WithColumn(column string) Option {
+	return func(o *options) {
+		o.column = column
+	}
+}
+
+// WithValueTransformer calls transform with the name of the column and every value, as it arrived from the driver,
+// the returned value is loaded instead, ie, to trim padded CHAR columns or to load sentinel values such as -1 as null.
+// v is one of nil, int64, float64, bool, []byte, string or time.Time, returned values are converted as arguments of queries are
+func WithValueTransformer(transform func(column string, v interface{}) (interface{}, error)) Option {
+	return func(o *options) {
+		o.transform = transform
+	}
+}
+
+// WithTimeLocation converts time data loaded by this call to the location, as SetTimeLocation does for all calls,
+// ie, to load time data in the time zone of a user, it overrides the location set with SetTimeLocation
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.timeLocation = loc
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{tagKey: tagKey, naming: naming, resolveNames: fieldNameResolver, logger: logger, tracer: tracer, metrics: metricsHook}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// key identifies the options which change how a mapper is planned,
+// mappers planned with different options are cached separately
+func (o *options) key() string {
+	aliases := make([]string, 0, len(o.aliases))
+	for column, path := range o.aliases {
+		aliases = append(aliases, column+"="+path)
+	}
+	sort.Strings(aliases)
+	return fmt.Sprintf("funcs=%q,tag=%s,autoprefix=%t,naming=%#v,exactcase=%t,tagsonly=%t,aliases=%s,ordinal=%t,mapkey=%s,column=%s,oracle=%t",
+		o.funcsKey, o.tagKey, o.autoPrefix, o.naming, o.exactCase, o.tagsOnly, strings.Join(aliases, ";"), o.ordinal, o.mapKey, o.column, o.oracle)
+}
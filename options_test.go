@@ -0,0 +1,475 @@
+package carta_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+)
+
+type normalized struct {
+	BlogId int
+	Title  string
+}
+
+func TestNameNormalizerClosures(t *testing.T) {
+	columns := []string{"x_blog_id", "title"}
+	rows := [][]interface{}{{1, "first"}}
+	trim := func(prefix string) func(string) string {
+		return func(name string) string { return strings.TrimPrefix(name, prefix) }
+	}
+	matched := []normalized{}
+	if err := carta.MapValues(columns, rows, &matched, carta.WithNameNormalizer(trim("x_"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 || matched[0].BlogId != 1 || matched[0].Title != "first" {
+		t.Fatalf("columns did not match with the normalizer, got %+v", matched)
+	}
+	// a closure of the same function with other state must not reuse the plan of the first one
+	unmatched := []normalized{}
+	if err := carta.MapValues(columns, rows, &unmatched, carta.WithNameNormalizer(trim("y_"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(unmatched) != 1 || unmatched[0].BlogId != 0 || unmatched[0].Title != "first" {
+		t.Fatalf("columns matched with the plan of another normalizer, got %+v", unmatched)
+	}
+}
+
+func TestFieldNameResolverClosures(t *testing.T) {
+	columns := []string{"tbl$blog_id", "title"}
+	rows := [][]interface{}{{1, "first"}}
+	prefixed := func(table string) carta.FieldNameResolver {
+		return func(field reflect.StructField, parent string) []string {
+			return []string{table + "$" + carta.SnakeCase{}.ColumnName(field.Name), strings.ToLower(field.Name)}
+		}
+	}
+	matched := []normalized{}
+	if err := carta.MapValues(columns, rows, &matched, carta.WithFieldNameResolver(prefixed("tbl"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 || matched[0].BlogId != 1 || matched[0].Title != "first" {
+		t.Fatalf("columns did not match with the resolver, got %+v", matched)
+	}
+	// a resolver of the same factory with other state must not reuse the plan of the first one
+	unmatched := []normalized{}
+	if err := carta.MapValues(columns, rows, &unmatched, carta.WithFieldNameResolver(prefixed("other"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(unmatched) != 1 || unmatched[0].BlogId != 0 || unmatched[0].Title != "first" {
+		t.Fatalf("columns matched with the plan of another resolver, got %+v", unmatched)
+	}
+}
+
+func TestWithCacheKey(t *testing.T) {
+	columns := []string{"tbl$blog_id", "title"}
+	rows := [][]interface{}{{1, "first"}}
+	prefixed := func(table string) carta.FieldNameResolver {
+		return func(field reflect.StructField, parent string) []string {
+			return []string{table + "$" + carta.SnakeCase{}.ColumnName(field.Name), strings.ToLower(field.Name)}
+		}
+	}
+	cache := carta.NewCache()
+	for _, table := range []string{"tbl", "tbl", "other"} {
+		blogs := []normalized{}
+		opts := []carta.Option{carta.WithFieldNameResolver(prefixed(table)), carta.WithCacheKey(table), carta.WithCache(cache)}
+		if err := carta.MapValues(columns, rows, &blogs, opts...); err != nil {
+			t.Fatal(err)
+		}
+		if matched := table == "tbl"; len(blogs) != 1 || (blogs[0].BlogId == 1) != matched {
+			t.Fatalf("%s: columns matched with the plan of another resolver, got %+v", table, blogs)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Fatalf("expected mappers to be cached under each key, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+type event struct {
+	Id int
+	At time.Time
+}
+
+func TestWithTimeLocation(t *testing.T) {
+	columns := []string{"id", "at"}
+	at := time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC)
+	est := time.FixedZone("EST", -5*3600)
+	events := []event{}
+	if err := carta.MapValues(columns, [][]interface{}{{1, at}}, &events, carta.WithTimeLocation(est)); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].At.Location() != est || events[0].At.Hour() != 10 {
+		t.Fatalf("expected 10:00 EST, got %+v", events)
+	}
+	// the location of one call must not leak into the next one
+	events = []event{}
+	if err := carta.MapValues(columns, [][]interface{}{{1, at}}, &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].At.Location() != time.UTC {
+		t.Fatalf("expected the location of the driver, got %+v", events)
+	}
+
+	// locations are applied once rows are loaded, so the mapper is planned once for every location
+	cache := carta.NewCache()
+	for _, loc := range []*time.Location{est, time.UTC} {
+		events = []event{}
+		if err := carta.MapValues(columns, [][]interface{}{{1, at}}, &events, carta.WithCache(cache), carta.WithTimeLocation(loc)); err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 1 || events[0].At.Location() != loc {
+			t.Fatalf("expected a time in %s, got %+v", loc, events)
+		}
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != 1 {
+		t.Fatalf("expected the mapper to be reused for another location, got %d misses and %d hits", stats.Misses, stats.Hits)
+	}
+}
+
+func TestWithReplace(t *testing.T) {
+	columns := []string{"blog_id", "title"}
+	blogs := []normalized{}
+	for _, id := range []int{1, 2} {
+		if err := carta.MapValues(columns, [][]interface{}{{id, "blog"}}, &blogs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(blogs) != 2 {
+		t.Fatalf("expected entities to be appended by default, got %+v", blogs)
+	}
+	if err := carta.MapValues(columns, [][]interface{}{{3, "blog"}}, &blogs, carta.WithReplace()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].BlogId != 3 {
+		t.Fatalf("expected the slice to be replaced, got %+v", blogs)
+	}
+
+	byId := map[int]normalized{}
+	for _, id := range []int{1, 2} {
+		if err := carta.MapValues(columns, [][]interface{}{{id, "blog"}}, &byId, carta.WithMapKey("blog_id")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(byId) != 2 {
+		t.Fatalf("expected entities to be added to the map by default, got %+v", byId)
+	}
+	if err := carta.MapValues(columns, [][]interface{}{{3, "blog"}}, &byId, carta.WithMapKey("blog_id"), carta.WithReplace()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := byId[3]; len(byId) != 1 || !ok {
+		t.Fatalf("expected the map to be replaced, got %+v", byId)
+	}
+}
+
+func TestStrictColumns(t *testing.T) {
+	blogs := []normalized{}
+	err := carta.MapValues([]string{"blog_id", "title", "titel"}, [][]interface{}{{1, "blog", "typo"}}, &blogs, carta.WithStrictColumns())
+	if !errors.Is(err, carta.ErrUnmatchedColumn) || !strings.Contains(err.Error(), "titel") {
+		t.Fatalf("expected ErrUnmatchedColumn listing titel, got %v", err)
+	}
+	if err = carta.MapValues([]string{"blog_id", "title"}, [][]interface{}{{1, "blog"}}, &blogs, carta.WithStrictColumns()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStrictFields(t *testing.T) {
+	blogs := []normalized{}
+	err := carta.MapValues([]string{"blog_id"}, [][]interface{}{{1}}, &blogs, carta.WithStrictFields())
+	if !errors.Is(err, carta.ErrUnmatchedField) || !strings.Contains(err.Error(), "Title") {
+		t.Fatalf("expected ErrUnmatchedField listing Title, got %v", err)
+	}
+	if err = carta.MapValues([]string{"blog_id", "title"}, [][]interface{}{{1, "blog"}}, &blogs, carta.WithStrictFields()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type dedupPost struct {
+	Title string
+}
+
+type dedupBlog struct {
+	BlogId int
+	Posts  []dedupPost
+}
+
+func TestWithoutDedup(t *testing.T) {
+	rows := [][]interface{}{{1, "first"}, {1, "first"}, {2, "second"}}
+	flat := []normalized{}
+	if err := carta.MapValues([]string{"blog_id", "title"}, rows, &flat, carta.WithoutDedup()); err != nil {
+		t.Fatal(err)
+	}
+	if len(flat) != 3 {
+		t.Fatalf("expected every row to be kept, got %+v", flat)
+	}
+	nested := []dedupBlog{}
+	if err := carta.MapValues([]string{"blog_id", "posts_title"}, rows, &nested, carta.WithoutDedup()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []dedupBlog{{1, []dedupPost{{"first"}, {"first"}}}, {2, []dedupPost{{"second"}}}}
+	if !reflect.DeepEqual(nested, expected) {
+		t.Fatalf("expected rows to be grouped by blog, keeping every post, got %+v", nested)
+	}
+}
+
+type person struct {
+	AuthorId int
+	Name     string
+}
+
+type prefixedBlog struct {
+	BlogId int
+	Editor person `db:"editor,prefix=editor_"`
+}
+
+func TestPrefixTag(t *testing.T) {
+	blogs := []prefixedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "editor_author_id", "editor_name"}, [][]interface{}{{1, 2, "ed"}}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Editor != (person{2, "ed"}) {
+		t.Fatalf("expected the editor to claim prefixed columns, got %+v", blogs)
+	}
+	blogs = []prefixedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "author_id", "name"}, [][]interface{}{{1, 2, "ed"}}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Editor != (person{}) {
+		t.Fatalf("expected columns without the prefix not to match the editor, got %+v", blogs)
+	}
+}
+
+type reviewedBlog struct {
+	BlogId   int
+	Writer   person
+	Reviewer person
+}
+
+func TestWithAutoPrefix(t *testing.T) {
+	columns := []string{"blog_id", "writer_author_id", "writer_name", "reviewer.author_id", "reviewer.name"}
+	blogs := []reviewedBlog{}
+	if err := carta.MapValues(columns, [][]interface{}{{1, 2, "wr", 3, "rev"}}, &blogs, carta.WithAutoPrefix()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Writer != (person{2, "wr"}) || blogs[0].Reviewer != (person{3, "rev"}) {
+		t.Fatalf("expected nested structs to claim columns prefixed with their fields, got %+v", blogs)
+	}
+	blogs = []reviewedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "author_id"}, [][]interface{}{{1, 2}}, &blogs, carta.WithAutoPrefix()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Writer.AuthorId != 0 || blogs[0].Reviewer.AuthorId != 0 {
+		t.Fatalf("expected columns without the prefix not to match nested structs, got %+v", blogs)
+	}
+}
+
+type keyedBlog struct {
+	BlogId int `db:"blog_id,pk"`
+	Rating float64
+}
+
+type unkeyedBlog struct {
+	BlogId int
+	Rating float64
+}
+
+func TestPrimaryKeyTag(t *testing.T) {
+	columns := []string{"blog_id", "rating"}
+	rows := [][]interface{}{{1, 4.5}, {1, 4.6}, {2, 4.5}}
+	keyed := []keyedBlog{}
+	if err := carta.MapValues(columns, rows, &keyed); err != nil {
+		t.Fatal(err)
+	}
+	if len(keyed) != 2 || keyed[0].BlogId != 1 || keyed[1].BlogId != 2 {
+		t.Fatalf("expected rows to be deduplicated by the key, got %+v", keyed)
+	}
+	unkeyed := []unkeyedBlog{}
+	if err := carta.MapValues(columns, rows, &unkeyed); err != nil {
+		t.Fatal(err)
+	}
+	if len(unkeyed) != 3 {
+		t.Fatalf("expected rows to be deduplicated by all columns, got %+v", unkeyed)
+	}
+}
+
+type taggedBlog struct {
+	BlogId int `db:"id"`
+	Title  string
+}
+
+func TestWithExactCase(t *testing.T) {
+	rows := [][]interface{}{{1, "first"}}
+	blogs := []normalized{}
+	if err := carta.MapValues([]string{"BlogId", "Title"}, rows, &blogs, carta.WithExactCase()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (normalized{1, "first"}) {
+		t.Fatalf("expected names to match as they are, got %+v", blogs)
+	}
+	blogs = []normalized{}
+	err := carta.MapValues([]string{"blog_id", "title"}, rows, &blogs, carta.WithExactCase())
+	if !errors.Is(err, carta.ErrNoColumnsMatched) {
+		t.Fatalf("expected snake and lower case names not to match, got %v, %+v", err, blogs)
+	}
+}
+
+func TestWithTagsOnly(t *testing.T) {
+	blogs := []taggedBlog{}
+	if err := carta.MapValues([]string{"id", "title"}, [][]interface{}{{1, "first"}}, &blogs, carta.WithTagsOnly()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (taggedBlog{1, ""}) {
+		t.Fatalf("expected only tagged fields to match, got %+v", blogs)
+	}
+}
+
+// blog tagged for sql and json, and for the default key of carta
+type multiTagged struct {
+	BlogId int    `db:"blog_id" sql:"id" json:"blogId"`
+	Title  string `sql:"name"`
+}
+
+func TestWithTagKey(t *testing.T) {
+	blogs := []multiTagged{}
+	if err := carta.MapValues([]string{"id", "name"}, [][]interface{}{{1, "first"}}, &blogs, carta.WithTagKey("sql")); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (multiTagged{1, "first"}) {
+		t.Fatalf("expected fields to match the sql tags, got %+v", blogs)
+	}
+	// the mapper of the sql tags must not be reused for the default key
+	blogs = []multiTagged{}
+	if err := carta.MapValues([]string{"blog_id", "title"}, [][]interface{}{{2, "second"}}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (multiTagged{2, "second"}) {
+		t.Fatalf("expected fields to match the db tags, got %+v", blogs)
+	}
+}
+
+func TestSetTagKey(t *testing.T) {
+	carta.SetTagKey("json")
+	defer carta.SetTagKey(carta.CartaTagKey)
+	blogs := []multiTagged{}
+	if err := carta.MapValues([]string{"blogId", "title"}, [][]interface{}{{1, "first"}}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (multiTagged{1, "first"}) {
+		t.Fatalf("expected fields to match the json tags, got %+v", blogs)
+	}
+	// the key of the call is read instead of the default one
+	blogs = []multiTagged{}
+	if err := carta.MapValues([]string{"id", "name"}, [][]interface{}{{2, "second"}}, &blogs, carta.WithTagKey("sql")); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (multiTagged{2, "second"}) {
+		t.Fatalf("expected fields to match the sql tags, got %+v", blogs)
+	}
+}
+
+// derives column names in lower camel case, ie, BlogId to blogId
+type lowerCamelCase struct{}
+
+func (lowerCamelCase) ColumnName(fieldName string) string {
+	return strings.ToLower(fieldName[:1]) + fieldName[1:]
+}
+
+func TestNamingStrategy(t *testing.T) {
+	columns := []string{"blogId", "title"}
+	rows := [][]interface{}{{1, "first"}}
+	blogs := []normalized{}
+	if err := carta.MapValues(columns, rows, &blogs, carta.WithNamingStrategy(lowerCamelCase{})); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (normalized{1, "first"}) {
+		t.Fatalf("expected columns to match names derived by the strategy, got %+v", blogs)
+	}
+	blogs = []normalized{}
+	if err := carta.MapValues(columns, rows, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].BlogId != 0 {
+		t.Fatalf("expected blogId not to match with snake case, got %+v", blogs)
+	}
+
+	carta.SetNamingStrategy(lowerCamelCase{})
+	defer carta.SetNamingStrategy(carta.SnakeCase{})
+	blogs = []normalized{}
+	if err := carta.MapValues(columns, rows, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0] != (normalized{1, "first"}) {
+		t.Fatalf("expected columns to match names derived by the default strategy, got %+v", blogs)
+	}
+}
+
+// namingFunc adapts a function to NamingStrategy
+type namingFunc func(fieldName string) string
+
+func (f namingFunc) ColumnName(fieldName string) string { return f(fieldName) }
+
+func prefixedNaming(prefix string) carta.NamingStrategy {
+	return namingFunc(func(fieldName string) string { return prefix + fieldName })
+}
+
+func TestNamingStrategyClosures(t *testing.T) {
+	columns := []string{"a_Title", "b_Title"}
+	rows := [][]interface{}{{"a", "b"}}
+	for _, prefix := range []string{"a_", "b_"} {
+		blogs := []normalized{}
+		if err := carta.MapValues(columns, rows, &blogs, carta.WithNamingStrategy(prefixedNaming(prefix))); err != nil {
+			t.Fatal(err)
+		}
+		if len(blogs) != 1 || blogs[0].Title != prefix[:1] {
+			t.Fatalf("expected the column of the strategy with prefix %s to match, got %+v", prefix, blogs)
+		}
+	}
+}
+
+type authoredBlog struct {
+	BlogId int
+	Author person
+}
+
+func TestFieldNameResolverParent(t *testing.T) {
+	parents := map[string]string{}
+	resolve := func(field reflect.StructField, parent string) []string {
+		parents[field.Name] = parent
+		return []string{"c$" + strings.ToLower(field.Name)}
+	}
+	carta.SetFieldNameResolver(resolve)
+	defer carta.SetFieldNameResolver(nil)
+	blogs := []authoredBlog{}
+	if err := carta.MapValues([]string{"c$blogid", "c$authorid"}, [][]interface{}{{1, 2}}, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].BlogId != 1 || blogs[0].Author.AuthorId != 2 {
+		t.Fatalf("expected columns to match names returned by the default resolver, got %+v", blogs)
+	}
+	if parents["BlogId"] != "" || parents["AuthorId"] != "Author" {
+		t.Fatalf("expected the paths of ancestors to be passed to the resolver, got %v", parents)
+	}
+}
+
+func TestWithColumnAliases(t *testing.T) {
+	blogs := []authoredBlog{}
+	aliases := carta.WithColumnAliases(map[string]string{"b": "BlogId", "u_name": "Author.Name"})
+	if err := carta.MapValues([]string{"b", "u_name"}, [][]interface{}{{1, "ann"}}, &blogs, aliases); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].BlogId != 1 || blogs[0].Author.Name != "ann" {
+		t.Fatalf("expected aliases to map onto the paths of fields, got %+v", blogs)
+	}
+}
+
+func TestWithOrdinal(t *testing.T) {
+	blogs := []authoredBlog{}
+	columns := []string{"id", "id", ""}
+	if err := carta.MapValues(columns, [][]interface{}{{1, 2, "ann"}}, &blogs, carta.WithOrdinal()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].BlogId != 1 || blogs[0].Author != (person{2, "ann"}) {
+		t.Fatalf("expected columns to map onto fields by position, got %+v", blogs)
+	}
+}
@@ -0,0 +1,90 @@
+package carta_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+// number as returned by godror for NUMBER columns
+type number string
+
+// rows of Oracle, which returns upper case columns and NUMBER columns as numbers
+type oracleRows struct {
+	columns []string
+	types   []string
+	rows    [][]interface{}
+	n       int
+}
+
+func (r *oracleRows) Columns() ([]string, error)           { return r.columns, nil }
+func (r *oracleRows) DatabaseTypeNames() ([]string, error) { return r.types, nil }
+func (r *oracleRows) Err() error                           { return nil }
+func (r *oracleRows) Close() error                         { return nil }
+func (r *oracleRows) Next() bool {
+	r.n++
+	return r.n <= len(r.rows)
+}
+func (r *oracleRows) Scan(dest ...interface{}) error {
+	for i, v := range r.rows[r.n-1] {
+		if err := dest[i].(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type oracleBlog struct {
+	BlogId  int64
+	Rating  float64
+	Score   float32
+	Display string
+}
+
+func TestWithOracle(t *testing.T) {
+	rows := &oracleRows{
+		columns: []string{"BLOG_ID", "RATING", "SCORE", "DISPLAY"},
+		types:   []string{"NUMBER", "NUMBER", "BINARY_DOUBLE", "NUMBER"},
+		rows:    [][]interface{}{{number("1"), number("4.5"), number("0.25"), number("12.30")}},
+	}
+	values := map[string]interface{}{}
+	record := carta.WithValueTransformer(func(column string, v interface{}) (interface{}, error) {
+		values[column] = v
+		return v, nil
+	})
+	blogs := []oracleBlog{}
+	if err := carta.Map(rows, &blogs, carta.WithOracle(), record); err != nil {
+		t.Fatal(err)
+	}
+	expected := []oracleBlog{{BlogId: 1, Rating: 4.5, Score: 0.25, Display: "12.30"}}
+	if !reflect.DeepEqual(blogs, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, blogs)
+	}
+	if values["BLOG_ID"] != int64(1) || values["RATING"] != 4.5 || values["SCORE"] != 0.25 {
+		t.Fatalf("expected numbers to be loaded as int64 and float64, got %#v", values)
+	}
+}
+
+func TestCheckTableOracle(t *testing.T) {
+	tests := []struct {
+		table string
+		query string
+		args  []interface{}
+	}{
+		{"blog", "select column_name from all_tab_columns where table_name = :1 and owner = sys_context('USERENV', 'CURRENT_SCHEMA') order by column_id", []interface{}{"BLOG"}},
+		{`"blog"`, "select column_name from all_tab_columns where table_name = :1 and owner = sys_context('USERENV', 'CURRENT_SCHEMA') order by column_id", []interface{}{"blog"}},
+		{"app.blog", "select column_name from all_tab_columns where table_name = :1 and owner = :2 order by column_id", []interface{}{"BLOG", "APP"}},
+	}
+	for _, test := range tests {
+		q := &recordingQuerier{}
+		if _, err := carta.CheckTable(context.Background(), q, (*[]normalized)(nil), test.table, carta.WithOracle()); err != errRecorded {
+			t.Fatalf("expected the error of the querier, got %v", err)
+		}
+		if q.query != test.query || !reflect.DeepEqual(q.args, test.args) {
+			t.Errorf("columns of %s are queried with %q %v, expected %q %v", test.table, q.query, q.args, test.query, test.args)
+		}
+	}
+}
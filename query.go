@@ -0,0 +1,26 @@
+package carta
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier runs queries, it is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Query runs the query with args on db, and maps the rows onto dst as MapContext does, example:
+//
+//	blogs := []Blog{}
+//	err := carta.Query(ctx, db, "select * from blog where author_id = $1", []interface{}{authorId}, &blogs)
+//
+// rows are always closed, and errors of iterating over the rows are returned
+func Query(ctx context.Context, db Querier, query string, args []interface{}, dst interface{}, opts ...Option) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return MapContext(ctx, rows, dst, opts...)
+}
@@ -0,0 +1,281 @@
+package carta
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// RecordRows wraps rows, so that their columns, database types and values are written to w as JSON once they are read,
+// to be replayed with ReplayRows, ie, to turn result sets of production into regression tests of mappings, example:
+//
+//	rows, err := db.Query("select * from blog left join post on blog.id = post.blog_id")
+//	...
+//	err = carta.Map(carta.RecordFile(rows, "testdata/blogs.json"), &blogs)
+//
+//	// in tests
+//	rows, err := carta.ReplayFile("testdata/blogs.json")
+//	...
+//	err = carta.Map(rows, &blogs)
+//
+// values are recorded once Next returns false, errors writing them are returned by Err, only the first result set is recorded
+func RecordRows(rows RowsSource, w io.Writer) RowsSource {
+	return &recordSource{RowsSource: rows, write: func(data []byte) error {
+		_, err := w.Write(data)
+		return err
+	}}
+}
+
+// RecordFile wraps rows as RecordRows does, the recording is written to the file at path, which is created or truncated
+func RecordFile(rows RowsSource, path string) RowsSource {
+	return &recordSource{RowsSource: rows, write: func(data []byte) error {
+		return os.WriteFile(path, data, 0644)
+	}}
+}
+
+// ReplayRows reads rows recorded with RecordRows, the rows have the columns and database types of the recorded rows
+func ReplayRows(r io.Reader) (RowsSource, error) {
+	rec := &recording{}
+	if err := json.NewDecoder(r).Decode(rec); err != nil {
+		return nil, fmt.Errorf("carta: cannot read recorded rows: %w", err)
+	}
+	if len(rec.Types) != len(rec.Columns) {
+		return nil, fmt.Errorf("carta: recorded rows have %d types for %d columns", len(rec.Types), len(rec.Columns))
+	}
+	rows := make([][]interface{}, len(rec.Rows))
+	for n, row := range rec.Rows {
+		if len(row) != len(rec.Columns) {
+			return nil, fmt.Errorf("carta: recorded row %d has %d values, expected a value for each of %d columns", n+1, len(row), len(rec.Columns))
+		}
+		rows[n] = make([]interface{}, len(row))
+		for i, v := range row {
+			rows[n][i] = v.value()
+		}
+	}
+	return &replaySource{valuesSource: valuesSource{columns: rec.Columns, rows: rows}, types: rec.Types}, nil
+}
+
+// ReplayFile reads rows recorded to the file at path, as ReplayRows does
+func ReplayFile(path string) (RowsSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReplayRows(f)
+}
+
+// recorded rows, as written to files
+type recording struct {
+	Columns []string           `json:"columns"`
+	Types   []string           `json:"types"`
+	Rows    [][]*recordedValue `json:"rows"`
+}
+
+// driver value of a column, null if nil, exactly one field is set, so that values keep their types
+type recordedValue struct {
+	Int    *int64     `json:"int,omitempty"`
+	Float  *float64   `json:"float,omitempty"`
+	Bool   *bool      `json:"bool,omitempty"`
+	String *string    `json:"string,omitempty"`
+	Bytes  *[]byte    `json:"bytes,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+func newRecordedValue(v interface{}) (*recordedValue, error) {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return nil, err
+	}
+	switch dv := dv.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return &recordedValue{Int: &dv}, nil
+	case float64:
+		if math.IsNaN(dv) || math.IsInf(dv, 0) {
+			return nil, fmt.Errorf("%v cannot be recorded as JSON", dv)
+		}
+		return &recordedValue{Float: &dv}, nil
+	case bool:
+		return &recordedValue{Bool: &dv}, nil
+	case string:
+		return &recordedValue{String: &dv}, nil
+	case []byte:
+		return &recordedValue{Bytes: &dv}, nil
+	case time.Time:
+		return &recordedValue{Time: &dv}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", dv)
+}
+
+func (v *recordedValue) value() interface{} {
+	switch {
+	case v == nil:
+		return nil
+	case v.Int != nil:
+		return *v.Int
+	case v.Float != nil:
+		return *v.Float
+	case v.Bool != nil:
+		return *v.Bool
+	case v.String != nil:
+		return *v.String
+	case v.Bytes != nil:
+		return *v.Bytes
+	case v.Time != nil:
+		return *v.Time
+	}
+	return nil
+}
+
+// source recording the rows of the source as they are read
+type recordSource struct {
+	RowsSource
+	write func(data []byte) error
+
+	rec       *recording // nil until the columns are read
+	values    []rawValue // values of the current row
+	done      bool       // rows were read, and recorded
+	recordErr error
+}
+
+// reads the columns and their database types of the source
+func (s *recordSource) describe() error {
+	if s.rec != nil {
+		return nil
+	}
+	columns, err := s.RowsSource.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := columnTypeNames(s.RowsSource, columns)
+	if err != nil {
+		return err
+	}
+	s.rec = &recording{Columns: columns, Types: types}
+	s.values = make([]rawValue, len(columns))
+	return nil
+}
+
+func (s *recordSource) Columns() ([]string, error) {
+	if err := s.describe(); err != nil {
+		return nil, err
+	}
+	return s.rec.Columns, nil
+}
+
+func (s *recordSource) DatabaseTypeNames() ([]string, error) {
+	if err := s.describe(); err != nil {
+		return nil, err
+	}
+	return s.rec.Types, nil
+}
+
+func (s *recordSource) Next() bool {
+	if s.RowsSource.Next() {
+		return true
+	}
+	if !s.done && s.RowsSource.Err() == nil && s.recordErr == nil {
+		s.done = true
+		if s.recordErr = s.describe(); s.recordErr == nil {
+			s.recordErr = s.flush()
+		}
+	}
+	return false
+}
+
+func (s *recordSource) Scan(dest ...interface{}) error {
+	if err := s.describe(); err != nil {
+		return err
+	}
+	if len(dest) != len(s.values) {
+		return fmt.Errorf("carta: expected a destination for each of %d columns, got %d", len(s.values), len(dest))
+	}
+	scanners := make([]sql.Scanner, len(dest))
+	for i, d := range dest {
+		scanner, ok := d.(sql.Scanner)
+		if !ok {
+			return fmt.Errorf("carta: cannot record value of column %s onto %T, destinations must implement sql.Scanner", s.rec.Columns[i], d)
+		}
+		scanners[i] = scanner
+	}
+	raw := make([]interface{}, len(s.values))
+	for i := range s.values {
+		s.values[i] = rawValue{}
+		raw[i] = &s.values[i]
+	}
+	if err := s.RowsSource.Scan(raw...); err != nil {
+		return err
+	}
+	row := make([]*recordedValue, len(s.values))
+	for i, v := range s.values {
+		rv, err := newRecordedValue(v.v)
+		if err != nil {
+			s.recordErr = fmt.Errorf("carta: cannot record value of column %s: %w", s.rec.Columns[i], err)
+			return s.recordErr
+		}
+		row[i] = rv
+		if err = scanners[i].Scan(v.v); err != nil {
+			return err
+		}
+	}
+	s.rec.Rows = append(s.rec.Rows, row)
+	return nil
+}
+
+func (s *recordSource) Err() error {
+	if err := s.RowsSource.Err(); err != nil {
+		return err
+	}
+	return s.recordErr
+}
+
+// writes the recording as JSON, with a row per line, so that changes of recordings are easy to review
+func (s *recordSource) flush() error {
+	var buf bytes.Buffer
+	columns, err := json.Marshal(s.rec.Columns)
+	if err != nil {
+		return fmt.Errorf("carta: cannot record rows: %w", err)
+	}
+	types, err := json.Marshal(s.rec.Types)
+	if err != nil {
+		return fmt.Errorf("carta: cannot record rows: %w", err)
+	}
+	fmt.Fprintf(&buf, "{\n  \"columns\": %s,\n  \"types\": %s,\n  \"rows\": [", columns, types)
+	for n, row := range s.rec.Rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("carta: cannot record row %d: %w", n+1, err)
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n    ")
+		buf.Write(data)
+	}
+	if len(s.rec.Rows) > 0 {
+		buf.WriteString("\n  ")
+	}
+	buf.WriteString("]\n}\n")
+	if err = s.write(buf.Bytes()); err != nil {
+		return fmt.Errorf("carta: cannot record rows: %w", err)
+	}
+	return nil
+}
+
+// source of recorded rows
+type replaySource struct {
+	valuesSource
+	types []string
+}
+
+func (s *replaySource) DatabaseTypeNames() ([]string, error) {
+	return s.types, nil
+}
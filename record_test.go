@@ -0,0 +1,120 @@
+package carta_test
+
+import (
+	"bytes"
+	"database/sql"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackskj/carta"
+)
+
+type recorded struct {
+	Id     int64
+	Rating float64
+	Draft  bool
+	Data   string
+	At     time.Time
+	Note   *string
+}
+
+// scans values as they are passed by a source
+type capturedValue struct {
+	v interface{}
+}
+
+func (c *capturedValue) Scan(src interface{}) error {
+	c.v = src
+	return nil
+}
+
+func recordedRows() *oracleRows {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &oracleRows{
+		columns: []string{"id", "rating", "draft", "data", "at", "note"},
+		types:   []string{"INT8", "FLOAT8", "BOOL", "BYTEA", "TIMESTAMPTZ", "TEXT"},
+		rows: [][]interface{}{
+			{int64(1), 4.5, true, []byte{0, 1, 2}, at, "note"},
+			{int64(2), 3.0, false, []byte{}, at.Add(time.Hour), nil},
+		},
+	}
+}
+
+func TestRecordRows(t *testing.T) {
+	var buf bytes.Buffer
+	expected := []recorded{}
+	if err := carta.Map(carta.RecordRows(recordedRows(), &buf), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if len(expected) != 2 || expected[0].Note == nil || expected[1].Note != nil {
+		t.Fatalf("recorded rows did not map, got %+v", expected)
+	}
+	recording := buf.String()
+
+	rows, err := carta.ReplayRows(strings.NewReader(recording))
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := rows.(carta.ColumnTypeSource).DatabaseTypeNames()
+	if err != nil || !reflect.DeepEqual(types, recordedRows().types) {
+		t.Fatalf("expected the recorded types, got %v, %v", types, err)
+	}
+	replayed := []recorded{}
+	if err := carta.Map(rows, &replayed); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed, expected) {
+		t.Fatalf("replayed rows differ\ngot:  %+v\nwant: %+v", replayed, expected)
+	}
+
+	// values keep their driver types
+	rows, _ = carta.ReplayRows(strings.NewReader(recording))
+	values := make([]capturedValue, 6)
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if !rows.Next() {
+		t.Fatal("expected a replayed row")
+	}
+	if err := rows.Scan(dest...); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range recordedRows().rows[0] {
+		if !reflect.DeepEqual(values[i].v, v) {
+			t.Errorf("column %d: expected %#v, got %#v", i, v, values[i].v)
+		}
+	}
+}
+
+func TestRecordRowsErrors(t *testing.T) {
+	rows := recordedRows()
+	rows.rows[1][1] = math.NaN()
+	var buf bytes.Buffer
+	if err := carta.Map(carta.RecordRows(rows, &buf), &[]recorded{}); err == nil || !strings.Contains(err.Error(), "rating") {
+		t.Fatalf("expected an error recording NaN of the rating column, got %v", err)
+	}
+
+	source := carta.RecordRows(recordedRows(), &buf)
+	source.Next()
+	var id int64
+	if err := source.Scan(&id, new(sql.RawBytes), nil, nil, nil, nil); err == nil {
+		t.Fatal("expected an error for destinations which are not scanners")
+	}
+	if err := source.Scan(&capturedValue{}); err == nil {
+		t.Fatal("expected an error for fewer destinations than columns")
+	}
+
+	for _, recording := range []string{
+		`{"columns": ["id", "note"], "types": ["INT8", "TEXT"], "rows": [[{"int": 1}]]}`,
+		`{"columns": ["id", "note"], "types": ["INT8"], "rows": []}`,
+		`{"columns": ["id"`,
+	} {
+		if _, err := carta.ReplayRows(strings.NewReader(recording)); err == nil {
+			t.Errorf("expected an error replaying %s", recording)
+		}
+	}
+}
@@ -30,6 +30,7 @@ type (
 type element struct {
 	v       reflect.Value // value of a struct that is mapped, this is never a pointer, its either a primative or struct
 	subMaps map[fieldIndex]*resolver
+	isNull  bool // only used by basic mappers, null elements of pointer collections, ie []*string, are set to nil
 }
 
 type resolver struct {
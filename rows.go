@@ -0,0 +1,69 @@
+package carta
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jackskj/carta/value"
+)
+
+// RowsSource is a source of rows mapped by carta, it is implemented by *sql.Rows, as well as by adapters of other drivers,
+// mocks or in-memory fixtures. Scan is called with a destination per column, which implements sql.Scanner and ValueSetter,
+// and must be passed driver values, ie, nil, int64, float64, bool, []byte, string or time.Time, as database/sql does
+type RowsSource interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+// ValueSetter is implemented by the destinations passed to Scan of a RowsSource, besides sql.Scanner,
+// sources of typed columns, ie, of arrow records, can set values onto them without converting them to driver values
+type ValueSetter interface {
+	SetNull()
+	SetInt64(v int64)
+	SetFloat64(v float64)
+	SetBool(v bool)
+	SetString(v string)
+	SetBytes(v []byte) // v is copied
+	SetTime(v time.Time)
+}
+
+var _ ValueSetter = (*value.Cell)(nil)
+
+// ColumnTypeSource is implemented by sources of rows which know the database types of their columns, ie, "INT8" or "JSONB",
+// which choose registered converters and decoding of columns, such as of geometries. *sql.Rows, and types embedding it, are supported without it,
+// columns of other sources are loaded without their database types
+type ColumnTypeSource interface {
+	DatabaseTypeNames() ([]string, error)
+}
+
+// implemented by sources with multiple result sets, ie, *sql.Rows
+type resultSetSource interface {
+	NextResultSet() bool
+}
+
+// implemented by *sql.Rows, as well as by types embedding it, ie, *sqlx.Rows
+type sqlColumnTypeSource interface {
+	ColumnTypes() ([]*sql.ColumnType, error)
+}
+
+// database type names of the columns of the source, empty if not known
+func columnTypeNames(rows RowsSource, columns []string) ([]string, error) {
+	switch r := rows.(type) {
+	case sqlColumnTypeSource:
+		columnTypes, err := r.ColumnTypes()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(columnTypes))
+		for i, t := range columnTypes {
+			names[i] = t.DatabaseTypeName()
+		}
+		return names, nil
+	case ColumnTypeSource:
+		return r.DatabaseTypeNames()
+	}
+	return make([]string, len(columns)), nil
+}
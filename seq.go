@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package carta
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+)
+
+var errStopSeq = errors.New("carta: sequence stopped")
+
+// MapSeq returns an iterator mapping rows onto T one entity at a time, as MapEach does, example:
+//
+//	for blog, err := range carta.MapSeq[Blog](rows) {
+//		if err != nil {
+//			...
+//		}
+//		...
+//	}
+//
+// T is a struct, a pointer to a struct, or a basic type. Rows of the same entity must be adjacent, as with MapEach.
+// An error is yielded with the zero T and ends the sequence, rows are closed once the sequence ends, including on break
+func MapSeq[T any](rows RowsSource, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := streamRows(context.Background(), rows, reflect.TypeOf((*[]T)(nil)), opts, func(entity reflect.Value) error {
+			if !yield(entity.Interface().(T), nil) {
+				return errStopSeq
+			}
+			return nil
+		})
+		if err != nil && err != errStopSeq {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package carta_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestMapSeq(t *testing.T) {
+	n := 0
+	for e, err := range carta.MapSeq[streamed](newIdRows(3)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Id != n {
+			t.Fatalf("expected entity %d, got %+v", n, e)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 entities, got %d", n)
+	}
+}
+
+func TestMapSeqBreak(t *testing.T) {
+	rows := newIdRows(10)
+	for e, err := range carta.MapSeq[*streamed](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Id == 1 {
+			break
+		}
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected rows to be closed on break")
+	}
+}
+
+func TestMapSeqError(t *testing.T) {
+	n := 0
+	for e, err := range carta.MapSeq[struct {
+		Id   int
+		Name string
+	}](newIdRows(3), carta.WithStrictFields()) {
+		n++
+		if !errors.Is(err, carta.ErrUnmatchedField) || e.Id != 0 {
+			t.Fatalf("expected ErrUnmatchedField with the zero entity, got %+v and %v", e, err)
+		}
+	}
+	if n != 1 {
+		t.Fatalf("expected a single error, got %d values", n)
+	}
+}
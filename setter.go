@@ -2,10 +2,13 @@ package carta
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 )
 
-func setDst(m *Mapper, dst reflect.Value, rsv *resolver) error {
+// sets the elements of the resolver onto the destination, hooks set with WithAfterMap and AfterMap methods
+// are called with elements of the root mapper once their children are set
+func setDst(m *Mapper, dst reflect.Value, rsv *resolver, o *options) error {
 	// dst is  always a pointer
 	dstIndirect := reflect.Indirect(dst)
 
@@ -62,14 +65,23 @@ func setDst(m *Mapper, dst reflect.Value, rsv *resolver) error {
 			}
 
 			// setting the child
-			setDst(subMap, childDst, subMapRsv)
+			setDst(subMap, childDst, subMapRsv, o)
 		}
 	}
 
+	isRoot := m.dstTyp != nil
+	isAfterMapper := reflect.PtrTo(m.Typ).Implements(afterMapperType)
 	for _, uid := range rsv.elementOrder {
 		elem := rsv.elements[uid]
+		if isRoot && !elem.isNull {
+			if err := afterMap(elem.v.Addr(), isAfterMapper, o); err != nil {
+				return err
+			}
+		}
 		if m.Crd == Collection {
-			if m.IsTypePtr {
+			if m.IsTypePtr && elem.isNull {
+				dstIndirect.Set(reflect.Append(dstIndirect, reflect.Zero(reflect.PtrTo(m.Typ))))
+			} else if m.IsTypePtr {
 				dstIndirect.Set(reflect.Append(dstIndirect, elem.v.Addr()))
 			} else {
 				dstIndirect.Set(reflect.Append(dstIndirect, elem.v))
@@ -80,3 +92,46 @@ func setDst(m *Mapper, dst reflect.Value, rsv *resolver) error {
 	}
 	return nil
 }
+
+// sets scalar destinations, rows are loaded onto a slice first, which must have a single element
+func setScalarDst(m *Mapper, dst reflect.Value, rsv *resolver, o *options) error {
+	elems := reflect.New(reflect.SliceOf(m.dstTyp.Elem()))
+	if err := setDst(m, elems, rsv, o); err != nil {
+		return err
+	}
+	switch elems.Elem().Len() {
+	case 0:
+		return ErrNoRows
+	case 1:
+		dst.Elem().Set(elems.Elem().Index(0))
+		return nil
+	}
+	return ErrTooManyRows
+}
+
+// sets map destinations, elements are set onto a slice first, then keyed by the field of the mapper
+// the map is created if it is nil, and existing entries with the same keys are replaced, unless the map is replaced as a whole
+func setMapDst(m *Mapper, dst reflect.Value, rsv *resolver, o *options) error {
+	mapTyp := m.dstTyp.Elem()
+	elems := reflect.New(reflect.SliceOf(mapTyp.Elem()))
+	if err := setDst(m, elems, rsv, o); err != nil {
+		return err
+	}
+	keys := make([]reflect.Value, elems.Elem().Len())
+	seen := map[interface{}]bool{}
+	for i := range keys {
+		keys[i] = reflect.Indirect(elems.Elem().Index(i)).Field(int(m.mapKey)).Convert(mapTyp.Key())
+		if seen[keys[i].Interface()] {
+			return fmt.Errorf("carta: duplicate key %v in %s, elements must have unique keys", keys[i], m.dstTyp)
+		}
+		seen[keys[i].Interface()] = true
+	}
+	dstMap := dst.Elem()
+	if dstMap.IsNil() || o.replaceDst {
+		dstMap.Set(reflect.MakeMapWithSize(mapTyp, len(keys)))
+	}
+	for i, key := range keys {
+		dstMap.SetMapIndex(key, elems.Elem().Index(i))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build go1.21
+
+package carta
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// NewSlogLogger returns a Logger which logs with l, warnings are logged as structured records at the warn level,
+// with the destination type, the column and the path of the field as attributes, example:
+//
+//	carta.SetLogger(carta.NewSlogLogger(slog.Default()))
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Printf(format string, v ...interface{}) {
+	s.l.Info(fmt.Sprintf(format, v...))
+}
+
+func (s slogLogger) logWarning(dstTyp reflect.Type, w Warning) {
+	attrs := []slog.Attr{slog.String("destination", dstTyp.String())}
+	msg := "carta: field does not match any column"
+	if w.Column != "" {
+		msg = "carta: column does not match any field"
+		attrs = append(attrs, slog.String("column", w.Column))
+	} else {
+		attrs = append(attrs, slog.String("field", w.Field))
+	}
+	s.l.LogAttrs(context.Background(), slog.LevelWarn, msg, attrs...)
+}
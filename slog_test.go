@@ -0,0 +1,50 @@
+//go:build go1.21
+
+package carta_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := carta.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	blogs := []warnedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "post_id", "extra"}, [][]interface{}{{int64(1), int64(1), int64(0)}}, &blogs, carta.WithLogger(l)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]string{
+		{"level": "WARN", "msg": "carta: column does not match any field", "destination": "*[]carta_test.warnedBlog", "column": "extra"},
+		{"level": "WARN", "msg": "carta: field does not match any column", "destination": "*[]carta_test.warnedBlog", "field": "Posts.Title"},
+	}
+	dec := json.NewDecoder(&buf)
+	for _, attrs := range expected {
+		record := map[string]interface{}{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("expected a record of %v, got %v", attrs, err)
+		}
+		for k, v := range attrs {
+			if record[k] != v {
+				t.Errorf("expected %s to be %q, got %v", k, v, record[k])
+			}
+		}
+	}
+	if dec.More() {
+		t.Fatal("expected a record of every warning only")
+	}
+
+	buf.Reset()
+	l.Printf("mapped %d rows", 2)
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "INFO" || record["msg"] != "mapped 2 rows" {
+		t.Fatalf("expected Printf to log at the info level, got %v", record)
+	}
+}
@@ -0,0 +1,187 @@
+package carta
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// MapEach streams rows, mapping them onto one entity at a time, which is passed to fn once all of its rows were read,
+// so that large results are not held in memory. dst is a pointer to a struct, only its type is used, ie, (*Blog)(nil),
+// fn receives a pointer to a new struct of that type for every entity. Rows of the same entity must be adjacent,
+// ie, ordered by the key of the entity, otherwise the entity is passed more than once.
+// Mapping stops when fn returns an error, which is then returned, rows are closed in any case
+func MapEach(rows RowsSource, dst interface{}, fn func(dst interface{}) error, opts ...Option) error {
+	dstTyp := reflect.TypeOf(dst)
+	if dstTyp == nil || !isStructPtr(dstTyp) {
+		rows.Close()
+		return destinationError(dstTyp, "pointer to a struct")
+	}
+	sliceTyp := reflect.PtrTo(reflect.SliceOf(dstTyp.Elem()))
+	return streamRows(context.Background(), rows, sliceTyp, opts, func(entity reflect.Value) error {
+		return fn(entity.Addr().Interface())
+	})
+}
+
+// MessageStream is a stream which messages are sent on, implemented by grpc.ServerStream and by generated server streams
+type MessageStream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+}
+
+// MapToStream maps rows onto messages of the type of msg, and sends every message on the stream once all of its rows were read,
+// ie, in server streaming methods of grpc, so that the client receives messages while the rows are read, example:
+//
+//	func (s *server) ListBlogs(req *pb.ListBlogsRequest, stream pb.BlogService_ListBlogsServer) error {
+//		rows, err := s.db.QueryContext(stream.Context(), "select ...")
+//		...
+//		return carta.MapToStream(rows, stream, (*pb.Blog)(nil))
+//	}
+//
+// msg is a pointer to a struct, only its type is used, messages are sent as pointers to new structs of that type.
+// Rows are read only as fast as the stream accepts messages, since sending blocks while flow control of the stream is exhausted,
+// mapping stops once the context of the stream is done. Rows of the same message must be adjacent, as with MapEach
+func MapToStream(rows RowsSource, stream MessageStream, msg interface{}, opts ...Option) error {
+	msgTyp := reflect.TypeOf(msg)
+	if msgTyp == nil || !isStructPtr(msgTyp) {
+		rows.Close()
+		return destinationError(msgTyp, "pointer to a struct")
+	}
+	sliceTyp := reflect.PtrTo(reflect.SliceOf(msgTyp.Elem()))
+	return streamRows(stream.Context(), rows, sliceTyp, opts, func(entity reflect.Value) error {
+		return stream.SendMsg(entity.Addr().Interface())
+	})
+}
+
+// maps rows onto a pointer to a slice of the type, fn is called with every entity once all of its rows were read
+func streamRows(ctx context.Context, rows RowsSource, sliceTyp reflect.Type, opts []Option, fn func(entity reflect.Value) error) (err error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	trace := startTrace(ctx, sliceTyp, o)
+	defer func() { trace.finish(err) }()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	columnTypes, err := columnTypeNames(rows, columns)
+	if err != nil {
+		return err
+	}
+	mapper, hit, err := cachedMapper(sliceTyp, columns, columnTypes, o)
+	if err != nil {
+		return err
+	}
+	trace.mapper(mapper, hit)
+	mapper.warn(o)
+	if err = mapper.checkMatched(o); err != nil {
+		return err
+	}
+	n := 0 // entities passed to fn
+	_, err = mapper.loadRows(ctx, trace.rows(rows), columnTypes, o, func(rsv *resolver) error {
+		entities := reflect.New(sliceTyp.Elem())
+		if err := setDst(mapper, entities, rsv, o); err != nil {
+			return err
+		}
+		for i := 0; i < entities.Elem().Len(); i++ {
+			n++
+			trace.entities(n)
+			if err := fn(entities.Elem().Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// MapChan maps rows onto T in a new goroutine, sending every entity on the returned channel once all of its rows were read,
+// so that mapping can be pipelined with processing, example:
+//
+//	blogs, errs := carta.MapChan[Blog](ctx, rows)
+//	for blog := range blogs {
+//		...
+//	}
+//	if err := <-errs; err != nil {
+//		...
+//	}
+//
+// T is a struct, a pointer to a struct, or a basic type. Rows of the same entity must be adjacent, as with MapEach.
+// Both channels are closed once mapping ends, the error channel receives at most one error, after the entities channel is closed.
+// Consumers which stop reading before the entities channel is closed must cancel ctx, which stops the goroutine and closes the rows,
+// the error channel then receives the error of the context
+func MapChan[T any](ctx context.Context, rows RowsSource, opts ...Option) (<-chan T, <-chan error) {
+	entities := make(chan T)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		err := streamRows(ctx, rows, reflect.TypeOf((*[]T)(nil)), opts, func(entity reflect.Value) error {
+			select {
+			case entities <- entity.Interface().(T):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(entities)
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return entities, errs
+}
+
+// MapToJSON streams rows onto w as a JSON array of the entities they map onto, ie, in HTTP handlers which only serialize
+// the result, so that the destination is never held in memory, example:
+//
+//	err := carta.MapToJSON(rows, w, (*[]Blog)(nil))
+//
+// shape is a pointer to a slice, as passed to Map, only its type is used. Entities are encoded with encoding/json
+// once all of their rows were read, rows of the same entity must be adjacent, as with MapEach.
+// The array is not terminated if mapping fails, entities encoded before the error are still written to w,
+// so that callers which already sent part of the response can tell where it stopped
+func MapToJSON(rows RowsSource, w io.Writer, shape interface{}, opts ...Option) error {
+	return MapToJSONContext(context.Background(), rows, w, shape, opts...)
+}
+
+// MapToJSONContext streams rows onto w as MapToJSON does, mapping stops once ctx is done, ie, once the client of an HTTP request
+// went away, example:
+//
+//	err := carta.MapToJSONContext(r.Context(), rows, w, (*[]Blog)(nil))
+func MapToJSONContext(ctx context.Context, rows RowsSource, w io.Writer, shape interface{}, opts ...Option) error {
+	sliceTyp := reflect.TypeOf(shape)
+	if sliceTyp == nil || !isSlicePtr(sliceTyp) {
+		rows.Close()
+		return destinationError(sliceTyp, "pointer to a slice")
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("["); err != nil {
+		rows.Close()
+		return err
+	}
+	first := true
+	err := streamRows(ctx, rows, sliceTyp, opts, func(entity reflect.Value) error {
+		if !first {
+			if _, err := bw.WriteString(","); err != nil {
+				return err
+			}
+		}
+		first = false
+		data, err := json.Marshal(entity.Interface())
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(data)
+		return err
+	})
+	if err != nil {
+		// entities which were encoded are written, the error of mapping takes precedence over errors writing them
+		bw.Flush()
+		return err
+	}
+	if _, err = bw.WriteString("]\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
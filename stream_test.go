@@ -0,0 +1,250 @@
+package carta_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type streamed struct {
+	Id int
+}
+
+// rows of ids, which records whether it was closed
+type idRows struct {
+	ids    []int64
+	n      int
+	closed chan struct{}
+}
+
+func newIdRows(n int) *idRows {
+	r := &idRows{closed: make(chan struct{})}
+	for i := 0; i < n; i++ {
+		r.ids = append(r.ids, int64(i))
+	}
+	return r
+}
+
+func (r *idRows) Columns() ([]string, error) { return []string{"id"}, nil }
+func (r *idRows) Err() error                 { return nil }
+func (r *idRows) Next() bool {
+	r.n++
+	return r.n <= len(r.ids)
+}
+func (r *idRows) Scan(dest ...interface{}) error {
+	return dest[0].(sql.Scanner).Scan(r.ids[r.n-1])
+}
+func (r *idRows) Close() error {
+	select {
+	case <-r.closed:
+	default:
+		close(r.closed)
+	}
+	return nil
+}
+
+func TestMapChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := newIdRows(100)
+	entities, errs := carta.MapChan[streamed](ctx, rows)
+	if first := <-entities; first.Id != 0 {
+		t.Fatalf("expected the first entity, got %+v", first)
+	}
+	cancel()
+	<-rows.closed
+	for range entities {
+		// entities sent before the cancellation was noticed
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMapChan(t *testing.T) {
+	entities, errs := carta.MapChan[streamed](context.Background(), newIdRows(3))
+	n := 0
+	for e := range entities {
+		if e.Id != n {
+			t.Fatalf("expected entity %d, got %+v", n, e)
+		}
+		n++
+	}
+	if err := <-errs; err != nil || n != 3 {
+		t.Fatalf("expected 3 entities without an error, got %d and %v", n, err)
+	}
+}
+
+func TestMapEach(t *testing.T) {
+	rows := &idRows{ids: []int64{0, 0, 1, 2, 2}, closed: make(chan struct{})}
+	ids := []int{}
+	err := carta.MapEach(rows, (*streamed)(nil), func(dst interface{}) error {
+		ids = append(ids, dst.(*streamed).Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != 0 || ids[1] != 1 || ids[2] != 2 {
+		t.Fatalf("expected entities 0, 1 and 2, got %v", ids)
+	}
+
+	rows = newIdRows(10)
+	stop := errors.New("stop")
+	n := 0
+	err = carta.MapEach(rows, (*streamed)(nil), func(dst interface{}) error {
+		if n++; n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Fatalf("expected the error of fn after 2 entities, got %d and %v", n, err)
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected rows to be closed")
+	}
+
+	var blogs []streamed
+	if err := carta.MapEach(newIdRows(1), &blogs, func(interface{}) error { return nil }); err == nil {
+		t.Fatal("expected an error for a destination which is not a pointer to a struct")
+	}
+}
+
+func TestMapChanError(t *testing.T) {
+	rows := newIdRows(3)
+	entities, errs := carta.MapChan[struct {
+		Id   int
+		Name string
+	}](context.Background(), rows, carta.WithStrictFields())
+	for e := range entities {
+		t.Fatalf("expected no entities, got %+v", e)
+	}
+	if err := <-errs; !errors.Is(err, carta.ErrUnmatchedField) {
+		t.Fatalf("expected ErrUnmatchedField, got %v", err)
+	}
+	if _, ok := <-errs; ok {
+		t.Fatal("expected the error channel to be closed after one error")
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected rows to be closed")
+	}
+}
+
+func TestMapToJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := carta.MapToJSON(newIdRows(3), &buf, (*[]streamed)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[{\"Id\":0},{\"Id\":1},{\"Id\":2}]\n" {
+		t.Fatalf("unexpected JSON %q", got)
+	}
+
+	buf.Reset()
+	if err := carta.MapToJSON(newIdRows(0), &buf, (*[]streamed)(nil)); err != nil || buf.String() != "[]\n" {
+		t.Fatalf("expected an empty array, got %q and %v", buf.String(), err)
+	}
+
+	// entities encoded before the error are written, the array is not terminated
+	buf.Reset()
+	rows := &oracleRows{
+		columns: []string{"id"},
+		types:   []string{""},
+		rows:    [][]interface{}{{int64(0)}, {int64(1)}, {"x"}},
+	}
+	if err := carta.MapToJSON(rows, &buf, (*[]streamed)(nil)); err == nil {
+		t.Fatal("expected an error loading text onto an int")
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "[{\"Id\":0}") || strings.HasSuffix(got, "]\n") {
+		t.Fatalf("expected the entities encoded before the error, got %q", got)
+	}
+
+	if err := carta.MapToJSON(newIdRows(1), &buf, []streamed{}); err == nil {
+		t.Fatal("expected an error for a shape which is not a pointer to a slice")
+	}
+}
+
+func TestMapToJSONContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows := newIdRows(3)
+	var buf bytes.Buffer
+	if err := carta.MapToJSONContext(ctx, rows, &buf, (*[]streamed)(nil)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected rows to be closed")
+	}
+}
+
+// stream which records the messages sent on it, as grpc.ServerStream does, sending fails with err once after messages were sent
+type fakeStream struct {
+	ctx   context.Context
+	sent  []*streamed
+	err   error
+	after int
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+func (s *fakeStream) SendMsg(m interface{}) error {
+	if s.err != nil && len(s.sent) == s.after {
+		return s.err
+	}
+	s.sent = append(s.sent, m.(*streamed))
+	return nil
+}
+
+func TestMapToStream(t *testing.T) {
+	stream := &fakeStream{ctx: context.Background()}
+	rows := &idRows{ids: []int64{0, 0, 1, 2}, closed: make(chan struct{})}
+	if err := carta.MapToStream(rows, stream, (*streamed)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.sent) != 3 || stream.sent[0].Id != 0 || stream.sent[1].Id != 1 || stream.sent[2].Id != 2 {
+		t.Fatalf("expected messages 0, 1 and 2, got %+v", stream.sent)
+	}
+
+	// the first send fails
+	errSend := errors.New("transport is closing")
+	stream = &fakeStream{ctx: context.Background(), err: errSend}
+	rows = newIdRows(10)
+	if err := carta.MapToStream(rows, stream, (*streamed)(nil)); err != errSend || len(stream.sent) != 0 {
+		t.Fatalf("expected the error of the first send, got %d messages and %v", len(stream.sent), err)
+	}
+	if rows.n > 2 {
+		t.Fatalf("expected reading to stop at the failed send, read %d rows", rows.n)
+	}
+	select {
+	case <-rows.closed:
+	default:
+		t.Fatal("expected rows to be closed")
+	}
+
+	// a later send fails
+	stream = &fakeStream{ctx: context.Background(), err: errSend, after: 2}
+	if err := carta.MapToStream(newIdRows(10), stream, (*streamed)(nil)); err != errSend || len(stream.sent) != 2 {
+		t.Fatalf("expected the error of the third send after 2 messages, got %d and %v", len(stream.sent), err)
+	}
+
+	// the context of the stream is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream = &fakeStream{ctx: ctx}
+	if err := carta.MapToStream(newIdRows(3), stream, (*streamed)(nil)); !errors.Is(err, context.Canceled) || len(stream.sent) != 0 {
+		t.Fatalf("expected context.Canceled without messages, got %d and %v", len(stream.sent), err)
+	}
+
+	if err := carta.MapToStream(newIdRows(1), stream, streamed{}); err == nil {
+		t.Fatal("expected an error for a message which is not a pointer to a struct")
+	}
+}
@@ -0,0 +1,44 @@
+package carta
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jackskj/carta/internal/tags"
+)
+
+// tagOptions are comma separated options following the column name in a tag, ie, `db:"ttl,unit=ms"`
+// options without a value, ie `db:"id,pk"`, are stored with an empty value
+type tagOptions map[string]string
+
+// tagKey is the key of tags read by default, set with SetTagKey
+var tagKey = CartaTagKey
+
+// SetTagKey sets the key of struct tags which carta reads by default, ie, "sql" or "json", instead of "db",
+// the key can also be set for a single call with WithTagKey, must be set before mapping
+func SetTagKey(key string) {
+	tagKey = key
+}
+
+func parseTag(t reflect.StructTag, key string) (string, tagOptions) {
+	name, opts := tags.Parse(t.Get(key))
+	return name, opts
+}
+
+func (o tagOptions) has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// unit of numeric columns loaded onto durations, seconds unless specified with the unit option
+func (o tagOptions) unit() (time.Duration, error) {
+	name, ok := o["unit"]
+	if !ok {
+		return time.Second, nil
+	}
+	if unit, ok := tags.Units[name]; ok {
+		return unit, nil
+	}
+	return 0, fmt.Errorf("carta: unknown unit %q, expected one of ns, us, ms, s, m, h", name)
+}
@@ -0,0 +1,30 @@
+package testdata
+
+import (
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/protobuf/runtime/protoimpl"
+)
+
+// OptionalTest mirrors the struct protoc-gen-go generates for a message with proto3 optional fields
+// message OptionalTest {
+//     optional bool   bool      = 1;
+//     optional string string    = 2;
+//     optional int32  int32     = 3;
+//     optional int64  int64     = 4;
+//     optional uint32 uint32    = 5;
+//     optional double float64   = 6;
+//     google.protobuf.Timestamp timestamp = 7;
+// }
+type OptionalTest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bool      *bool                `protobuf:"varint,1,opt,name=bool,proto3,oneof" json:"bool,omitempty"`
+	String_   *string              `protobuf:"bytes,2,opt,name=string,proto3,oneof" json:"string,omitempty" db:"string"`
+	Int32     *int32               `protobuf:"varint,3,opt,name=int32,proto3,oneof" json:"int32,omitempty"`
+	Int64     *int64               `protobuf:"varint,4,opt,name=int64,proto3,oneof" json:"int64,omitempty"`
+	Uint32    *uint32              `protobuf:"varint,5,opt,name=uint32,proto3,oneof" json:"uint32,omitempty"`
+	Float64   *float64             `protobuf:"fixed64,6,opt,name=float64,proto3,oneof" json:"float64,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
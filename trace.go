@@ -0,0 +1,120 @@
+package carta
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// MapStats describes a call of Map once it ended, as reported to tracers and metrics hooks
+type MapStats struct {
+	Destination reflect.Type  // type of the destination, ie, *[]Blog
+	Rows        int           // rows read
+	Entities    int           // entities of the destination, after removing duplicates, nested entities are not counted
+	CacheHit    bool          // the mapper was loaded from the cache, or built with NewMapper, instead of being planned
+	Duration    time.Duration // time taken to plan the mapper and to load the rows
+	Warnings    []Warning     // unmatched columns and fields
+	Err         error         // error of the mapping, if any
+}
+
+// Tracer traces calls of Map, ie, as spans of OpenTelemetry, see the cartaotel module.
+// StartMap is called before the rows are read, with the context passed to MapContext, and the returned function once mapping ends
+type Tracer interface {
+	StartMap(ctx context.Context, dst reflect.Type) (end func(stats MapStats))
+}
+
+// tracer is the tracer used by default, set with SetTracer, calls are not traced by default
+var tracer Tracer
+
+// SetTracer sets the tracer used by default, the tracer can also be set for a single call with WithTracer,
+// nil disables tracing, must be set before mapping
+func SetTracer(t Tracer) {
+	tracer = t
+}
+
+// WithTracer traces the call with the tracer, instead of the tracer set with SetTracer
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// observation of a call of Map, reported to the tracer and to the metrics hook once it ends
+type mapTrace struct {
+	stats   MapStats
+	start   time.Time
+	end     func(stats MapStats) // returned by the tracer
+	metrics MetricsHook
+}
+
+// starts observing a call, returns nil unless the call is traced or measured
+func startTrace(ctx context.Context, dst reflect.Type, o *options) *mapTrace {
+	if o.tracer == nil && o.metrics == nil {
+		return nil
+	}
+	t := &mapTrace{
+		stats:   MapStats{Destination: dst},
+		start:   time.Now(),
+		metrics: o.metrics,
+	}
+	if o.tracer != nil {
+		t.end = o.tracer.StartMap(ctx, dst)
+	}
+	if o.metrics != nil {
+		o.metrics.OnMapStart(dst)
+	}
+	return t
+}
+
+// records the mapper of the call
+func (t *mapTrace) mapper(m *Mapper, cacheHit bool) {
+	if t == nil {
+		return
+	}
+	t.stats.CacheHit = cacheHit
+	t.stats.Warnings = m.warnings()
+}
+
+// counts rows read from the source
+func (t *mapTrace) rows(rows RowsSource) RowsSource {
+	if t == nil {
+		return rows
+	}
+	return &countingSource{RowsSource: rows, n: &t.stats.Rows}
+}
+
+// records the number of entities of the destination
+func (t *mapTrace) entities(n int) {
+	if t != nil {
+		t.stats.Entities = n
+	}
+}
+
+// ends the observation with the error of the call
+func (t *mapTrace) finish(err error) {
+	if t == nil {
+		return
+	}
+	t.stats.Duration = time.Since(t.start)
+	t.stats.Err = err
+	if t.end != nil {
+		t.end(t.stats)
+	}
+	if t.metrics != nil {
+		t.metrics.OnMapDone(t.stats)
+	}
+}
+
+// source counting the rows read
+type countingSource struct {
+	RowsSource
+	n *int
+}
+
+func (s *countingSource) Next() bool {
+	if s.RowsSource.Next() {
+		*s.n++
+		return true
+	}
+	return false
+}
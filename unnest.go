@@ -0,0 +1,117 @@
+package carta
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+// WithUnnest loads a row for each element of the array columns of a row, ie, of Array and Nested columns of ClickHouse,
+// or LIST columns of DuckDB, so that elements map onto slices of basic types, or onto collections of nested structs,
+// as if the arrays were joined, example:
+//
+//	// select id, title, tags, `posts.id`, `posts.title` from blog, where tags is Array(String), and posts is Nested
+//	type Blog struct {
+//		Id    int
+//		Title string
+//		Tags  []string `db:"tags"`
+//		Posts []Post   // fields of Post are tagged with "posts.id" and "posts.title"
+//	}
+//
+// elements of arrays of the same row are loaded together, shorter arrays are padded with nulls, other columns are repeated,
+// rows with empty arrays are loaded once, with null elements. Arrays of bytes are not unnested
+func WithUnnest() Option {
+	return func(o *options) {
+		o.unnest = true
+	}
+}
+
+// source loading a row for each element of the array columns of the rows of the source
+type unnestSource struct {
+	RowsSource
+	values []rawValue // values of the current row of the source
+	n      int        // current element
+	len    int        // number of elements of the current row
+	err    error      // error scanning the current row
+}
+
+func newUnnestSource(rows RowsSource, columns int) *unnestSource {
+	return &unnestSource{RowsSource: rows, values: make([]rawValue, columns)}
+}
+
+func (s *unnestSource) Next() bool {
+	if s.n++; s.n < s.len {
+		return true
+	}
+	if !s.RowsSource.Next() {
+		return false
+	}
+	dest := make([]interface{}, len(s.values))
+	for i := range s.values {
+		s.values[i] = rawValue{}
+		dest[i] = &s.values[i]
+	}
+	s.n, s.len = 0, 1
+	if s.err = s.RowsSource.Scan(dest...); s.err != nil {
+		return true
+	}
+	for _, v := range s.values {
+		if arr, ok := unnestArray(v.v); ok && arr.Len() > s.len {
+			s.len = arr.Len()
+		}
+	}
+	return true
+}
+
+func (s *unnestSource) Scan(dest ...interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	for i, v := range s.values {
+		src := v.v
+		if arr, ok := unnestArray(src); ok {
+			src = nil
+			if s.n < arr.Len() {
+				src = arr.Index(s.n).Interface()
+			}
+		}
+		if err := dest[i].(sql.Scanner).Scan(src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// array of a value which is unnested, or which a value points to, arrays of bytes are not
+func unnestArray(v interface{}) (reflect.Value, bool) {
+	arr := reflect.ValueOf(v)
+	for arr.Kind() == reflect.Ptr && !arr.IsNil() {
+		arr = arr.Elem()
+	}
+	if k := arr.Kind(); (k != reflect.Slice && k != reflect.Array) || arr.Type().Elem().Kind() == reflect.Uint8 {
+		return arr, false
+	}
+	return arr, true
+}
+
+// value of a column as it arrived from the source
+type rawValue struct {
+	v interface{}
+}
+
+func (r *rawValue) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		// drivers may reuse the underlying array
+		src = append([]byte{}, b...)
+	}
+	r.v = src
+	return nil
+}
+
+func (r *rawValue) SetNull()             { r.v = nil }
+func (r *rawValue) SetInt64(v int64)     { r.v = v }
+func (r *rawValue) SetFloat64(v float64) { r.v = v }
+func (r *rawValue) SetBool(v bool)       { r.v = v }
+func (r *rawValue) SetString(v string)   { r.v = v }
+func (r *rawValue) SetBytes(v []byte)    { r.v = append([]byte{}, v...) }
+func (r *rawValue) SetTime(v time.Time)  { r.v = v }
@@ -2,21 +2,25 @@ package value
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math"
+	"net"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
-// TODO:  timestamp/time/ from string
-// TODO:  int/float/uint/bool from string
-
 type Cell struct {
 	kind       reflect.Kind // data type with which Cell will be instantiated
 	bits       uint64       //IEEE 754 binary representation of numeric value
@@ -24,14 +28,51 @@ type Cell struct {
 	time       time.Time    //  any data that arrives as time, that includes timestame w/ or w/o zone
 	colTypName string       // Used for parting if some data arrices in plain text format, ex, if time arrives as string
 	valid      bool
+	isBytes    bool // text arrived as []byte
+
+	location *time.Location // time data is converted to, instead of the location set with SetTimeLocation
 }
 
 func OverflowErr(i interface{}, typ reflect.Type) error {
-	return fmt.Errorf("carta: value %v overflows %v", i, typ)
+	return &OverflowError{Value: i, Type: typ}
+}
+
+// OverflowError is returned when a number does not fit the type it is converted to, or when a negative number
+// is converted to an unsigned type, carta returns it as a carta.OverflowError, with the column and the field
+type OverflowError struct {
+	Value interface{}
+	Type  reflect.Type
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("carta: value %v overflows %v", e.Value, e.Type)
 }
 
+var (
+	int32Type   = reflect.TypeOf(int32(0))
+	int64Type   = reflect.TypeOf(int64(0))
+	uint32Type  = reflect.TypeOf(uint32(0))
+	uint64Type  = reflect.TypeOf(uint64(0))
+	float32Type = reflect.TypeOf(float32(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
 func ConvertsionError(convErr error, typ reflect.Type) error {
-	return fmt.Errorf("carta: errors converting to %v: "+convErr.Error(), typ)
+	return &ConversionError{Type: typ, Err: convErr}
+}
+
+// ConversionError is returned when a value cannot be converted to the type
+type ConversionError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("carta: errors converting to %v: %s", e.Type, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
 }
 
 func NewCell(colTypName string) *Cell {
@@ -48,14 +89,80 @@ func (c *Cell) Scan(src interface{}) error {
 	case bool:
 		c.SetBool(src.(bool))
 	case []byte:
-		c.SetString(string(src.([]byte)))
+		if isMSSQLUUID(c.colTypName, src.([]byte)) {
+			c.SetString(formatMSSQLUUID(src.([]byte)))
+		} else {
+			c.SetBytes(src.([]byte))
+		}
 	case string:
 		c.SetString(src.(string))
 	case time.Time:
 		c.SetTime(src.(time.Time))
-	default:
-		// src is nil
+	case nil:
 		c.SetNull()
+	default:
+		return c.scanOther(src)
+	}
+	return nil
+}
+
+// scans values which are not driver values, as returned by drivers such as clickhouse-go or duckdb,
+// other integers and floats are scanned as int64 and float64, unsigned integers above the range of int64 as text,
+// pointers as the values they point to, types implementing driver.Valuer or fmt.Stringer as their values,
+// and other slices, arrays, maps and structs as JSON
+func (c *Cell) scanOther(src interface{}) error {
+	if valuer, ok := src.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		if _, ok := v.(driver.Valuer); ok {
+			return fmt.Errorf("carta: cannot scan %T, its value is %T", src, v)
+		}
+		return c.Scan(v)
+	}
+	v := reflect.ValueOf(src)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			c.SetNull()
+			return nil
+		}
+		if stringer, ok := src.(fmt.Stringer); ok && v.Elem().Kind() == reflect.Struct && v.Elem().Type() != reflect.TypeOf(time.Time{}) {
+			// ie, *big.Int
+			c.SetString(stringer.String())
+			return nil
+		}
+		return c.Scan(v.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.SetInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			c.SetInt64(int64(u))
+		} else {
+			c.SetString(strconv.FormatUint(u, 10))
+		}
+	case reflect.Float32, reflect.Float64:
+		c.SetFloat64(v.Float())
+	case reflect.Bool:
+		c.SetBool(v.Bool())
+	case reflect.String:
+		c.SetString(v.String())
+	default:
+		if stringer, ok := src.(fmt.Stringer); ok {
+			// ie, net.IP or uuid.UUID
+			c.SetString(stringer.String())
+		} else if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			c.SetBytes(v.Bytes())
+		} else if k := v.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map || k == reflect.Struct {
+			b, err := json.Marshal(src)
+			if err != nil {
+				return fmt.Errorf("carta: cannot scan %T: %w", src, err)
+			}
+			c.SetBytes(b)
+		} else {
+			return fmt.Errorf("carta: cannot scan %T", src)
+		}
 	}
 	return nil
 }
@@ -77,7 +184,7 @@ func (c *Cell) SetFloat64(d float64) {
 }
 
 func (c *Cell) SetInt64(d int64) {
-	c.kind = reflect.Float64
+	c.kind = reflect.Int64
 	c.valid = true
 	c.bits = uint64(d)
 }
@@ -86,6 +193,30 @@ func (c *Cell) SetString(d string) {
 	c.kind = reflect.String
 	c.valid = true
 	c.text = d
+	c.isBytes = false
+}
+
+// ParseNumber converts numbers which arrived as text, ie, Oracle NUMBER, to int64, or to float64 if they are not integral,
+// integers beyond the range of int64, and text which is not a number, are left as they are, the text is kept,
+// so that the number is still loaded as it arrived onto strings
+func (c *Cell) ParseNumber() {
+	if c.kind != reflect.String || !c.valid {
+		return
+	}
+	text := c.text
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		c.SetInt64(i)
+	} else if errors.Is(err, strconv.ErrRange) {
+		return
+	} else if f, err := strconv.ParseFloat(text, 64); err == nil {
+		c.SetFloat64(f)
+	}
+}
+
+// SetBytes copies d, since drivers may reuse the underlying array
+func (c *Cell) SetBytes(d []byte) {
+	c.SetString(string(d))
+	c.isBytes = true
 }
 
 func (c *Cell) SetTime(d time.Time) {
@@ -110,87 +241,346 @@ func (c Cell) IsValid() bool {
 	return c.valid
 }
 
+// Bool converts numeric data, ie, mysql TINYINT(1), any non zero value is true
+// text accepted by strconv.ParseBool, ie, "true" or "f", numbers arriving as text, as with the mysql text protocol,
+// and single byte BIT(1) values are also converted
 func (c Cell) Bool() (bool, error) {
+	switch c.kind {
+	case reflect.Float64:
+		return math.Float64frombits(c.bits) != 0, nil
+	case reflect.String:
+		if len(c.text) == 1 && (c.text[0] == 0 || c.text[0] == 1) {
+			return c.text[0] == 1, nil
+		}
+		if b, err := strconv.ParseBool(c.text); err == nil {
+			return b, nil
+		}
+		if num, err := strconv.ParseFloat(c.text, 64); err == nil {
+			return num != 0, nil
+		}
+		return false, fmt.Errorf("cannot convert %q to bool", c.text)
+	}
 	return (c.bits != 0), nil
 }
 
+var lenientBools = map[string]bool{
+	"t": true, "true": true, "y": true, "yes": true, "on": true, "1": true,
+	"f": false, "false": false, "n": false, "no": false, "off": false, "0": false,
+}
+
+// LenientBool additionally parses text commonly used for booleans in legacy schemas, ie, "Y", "N", "on", "off"
+// text is case insensitive and blank padding, as with CHAR columns, is ignored
+func (c Cell) LenientBool() (bool, error) {
+	if c.kind != reflect.String {
+		return c.Bool()
+	}
+	if b, ok := lenientBools[strings.ToLower(strings.TrimSpace(c.text))]; ok {
+		return b, nil
+	}
+	return c.Bool()
+}
+
 func (c Cell) Int32() (int32, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseInt(c.text, 10, 32); err != nil {
+		if num, err := parseInt(c.text, 32); err != nil {
 			return 0, err
 		} else {
 			return int32(num), nil
 		}
 	}
+	if c.kind == reflect.Float64 {
+		f := math.Float64frombits(c.bits)
+		if err := integral(f); err != nil {
+			return 0, err
+		}
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			return 0, OverflowErr(f, int32Type)
+		}
+		return int32(f), nil
+	}
+	if i := int64(c.bits); i < math.MinInt32 || i > math.MaxInt32 {
+		return 0, OverflowErr(i, int32Type)
+	}
 	return int32(c.bits), nil
 }
 
 func (c Cell) Int64() (int64, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseInt(c.text, 10, 64); err != nil {
+		if num, err := parseInt(c.text, 64); err != nil {
 			return 0, err
 		} else {
 			return int64(num), nil
 		}
 	}
+	if c.kind == reflect.Float64 {
+		f := math.Float64frombits(c.bits)
+		if err := integral(f); err != nil {
+			return 0, err
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, OverflowErr(f, int64Type)
+		}
+		return int64(f), nil
+	}
 	return int64(c.bits), nil
 }
 
 func (c Cell) Uint32() (uint32, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseUint(c.text, 10, 32); err != nil {
+		if num, err := parseUint(c.text, 32); err != nil {
 			return 0, err
 		} else {
 			return uint32(num), nil
 		}
 	}
+	if c.kind == reflect.Float64 {
+		f := math.Float64frombits(c.bits)
+		if err := integral(f); err != nil {
+			return 0, err
+		}
+		if f < 0 || f > math.MaxUint32 {
+			return 0, OverflowErr(f, uint32Type)
+		}
+		return uint32(f), nil
+	}
+	if c.kind == reflect.Int64 && int64(c.bits) < 0 {
+		return 0, OverflowErr(int64(c.bits), uint32Type)
+	}
+	if c.bits > math.MaxUint32 {
+		return 0, OverflowErr(c.bits, uint32Type)
+	}
 	return uint32(c.bits), nil
 }
 
 func (c Cell) Uint64() (uint64, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseUint(c.text, 10, 64); err != nil {
+		if num, err := parseUint(c.text, 64); err != nil {
 			return 0, err
 		} else {
 			return uint64(num), nil
 		}
 	}
+	if c.kind == reflect.Float64 {
+		f := math.Float64frombits(c.bits)
+		if err := integral(f); err != nil {
+			return 0, err
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, OverflowErr(f, uint64Type)
+		}
+		return uint64(f), nil
+	}
+	if c.kind == reflect.Int64 && int64(c.bits) < 0 {
+		return 0, OverflowErr(int64(c.bits), uint64Type)
+	}
 	return c.bits, nil
 }
 
+// floats are loaded onto integers only if they are integral, as text is, NaN is not integral
+func integral(f float64) error {
+	if f != math.Trunc(f) {
+		return fmt.Errorf("cannot convert %v to an integer, it is not integral", f)
+	}
+	return nil
+}
+
+// drivers such as go-sql-driver/mysql return DECIMAL, and in the text protocol all numeric columns, as []byte
+// integral decimals, ie, "10.00", are accepted when loading integers, integers which do not fit bitSize are an OverflowError
+func parseInt(s string, bitSize int) (int64, error) {
+	num, err := strconv.ParseInt(s, 10, bitSize)
+	if err == nil {
+		return num, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil || f != math.Trunc(f) {
+		return 0, err
+	}
+	if f < -math.Ldexp(1, bitSize-1) || f >= math.Ldexp(1, bitSize-1) {
+		return 0, OverflowErr(s, intType(bitSize, int32Type, int64Type))
+	}
+	return int64(f), nil
+}
+
+func parseUint(s string, bitSize int) (uint64, error) {
+	num, err := strconv.ParseUint(s, 10, bitSize)
+	if err == nil {
+		return num, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil || f != math.Trunc(f) {
+		return 0, err
+	}
+	if f < 0 || f >= math.Ldexp(1, bitSize) {
+		return 0, OverflowErr(s, intType(bitSize, uint32Type, uint64Type))
+	}
+	return uint64(f), nil
+}
+
+// the type of integers of the bit size, which is either 32 or 64
+func intType(bitSize int, typ32, typ64 reflect.Type) reflect.Type {
+	if bitSize == 32 {
+		return typ32
+	}
+	return typ64
+}
+
 func (c Cell) Float32() (float32, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseFloat(c.text, 32); err != nil {
+		if num, err := strconv.ParseFloat(c.text, 32); errors.Is(err, strconv.ErrRange) {
+			return 0, OverflowErr(c.text, float32Type)
+		} else if err != nil {
 			return 0, err
 		} else {
 			return float32(num), nil
 		}
 	}
-	return math.Float32frombits(uint32(c.bits)), nil
+	if c.kind == reflect.Int64 {
+		return float32(int64(c.bits)), nil
+	}
+	return float32(math.Float64frombits(c.bits)), nil
 }
 
 func (c Cell) Float64() (float64, error) {
 	if c.kind == reflect.String {
-		if num, err := strconv.ParseFloat(c.text, 64); err != nil {
+		if num, err := strconv.ParseFloat(c.text, 64); errors.Is(err, strconv.ErrRange) {
+			return 0, OverflowErr(c.text, float64Type)
+		} else if err != nil {
 			return 0, err
 		} else {
 			return num, nil
 		}
 	}
+	if c.kind == reflect.Int64 {
+		return float64(int64(c.bits)), nil
+	}
 	return math.Float64frombits(c.bits), nil
 }
 
 func (c Cell) String() (string, error) {
-	return c.text, nil
+	return unquoteVariant(c.colTypName, c.text), nil
+}
+
+// IsBinary reports whether the cell was loaded from a column of a binary database type, ie, bytea or blob
+func (c Cell) IsBinary() bool {
+	return BinaryTypes[strings.ToUpper(c.colTypName)]
+}
+
+func (c Cell) Bytes() ([]byte, error) {
+	if c.kind != reflect.String {
+		return nil, errors.New("cannot convert non text data to bytes")
+	}
+	return []byte(c.text), nil
+}
+
+// Duration loads intervals, which arrive as text, as well as numeric columns, which are multiplied by the unit
+func (c Cell) Duration(unit time.Duration) (time.Duration, error) {
+	switch c.kind {
+	case reflect.String:
+		if num, err := strconv.ParseFloat(c.text, 64); err == nil {
+			return time.Duration(math.Round(num * float64(unit))), nil
+		}
+		return parseInterval(c.text)
+	case reflect.Int64:
+		return time.Duration(int64(c.bits)) * unit, nil
+	case reflect.Float64:
+		return time.Duration(math.Round(math.Float64frombits(c.bits) * float64(unit))), nil
+	}
+	return 0, errors.New("cannot convert non numeric data to duration")
+}
+
+// ProtoJSON unmarshals json data onto the message, used for google.protobuf.Struct and related types
+func (c Cell) ProtoJSON(m proto.Message) error {
+	if c.kind != reflect.String {
+		return errors.New("cannot convert non text data to json")
+	}
+	return protojson.Unmarshal([]byte(c.text), m)
+}
+
+// layouts and location of time data, set with SetTimeLayouts and SetTimeLocation, which may be called while cells are loaded
+type timeSettings struct {
+	layouts  []string
+	location *time.Location
+}
+
+var (
+	timeSettingsMu sync.Mutex   // serializes setters
+	timeConfig     atomic.Value // *timeSettings
+)
+
+func init() {
+	timeConfig.Store(&timeSettings{layouts: []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02",
+	}})
+}
+
+func currentTimeSettings() *timeSettings {
+	return timeConfig.Load().(*timeSettings)
+}
+
+// SetTimeLayouts sets the layouts which are used, in order, to parse time data which arrives as text, ie, from SQLite
+// or MySQL without parseTime=true, text without a time zone is parsed as UTC, unless a location is set with SetTimeLocation
+func SetTimeLayouts(layouts ...string) {
+	timeSettingsMu.Lock()
+	defer timeSettingsMu.Unlock()
+	settings := *currentTimeSettings()
+	settings.layouts = append([]string{}, layouts...)
+	timeConfig.Store(&settings)
+}
+
+// SetTimeLocation sets the location all time data is converted to, regardless of the location set by the driver,
+// text without a time zone is parsed in this location, nil keeps the location set by the driver
+func SetTimeLocation(loc *time.Location) {
+	timeSettingsMu.Lock()
+	defer timeSettingsMu.Unlock()
+	settings := *currentTimeSettings()
+	settings.location = loc
+	timeConfig.Store(&settings)
+}
+
+// SetTimeLocation sets the location time data of the cell is converted to, overriding the location set with SetTimeLocation,
+// nil uses the location set with SetTimeLocation
+func (c *Cell) SetTimeLocation(loc *time.Location) {
+	c.location = loc
 }
 
 func (c Cell) Time() (time.Time, error) {
+	settings := currentTimeSettings()
+	location := settings.location
+	if c.location != nil {
+		location = c.location
+	}
 	if c.kind == reflect.String {
-		// TODO: Parse from string
-		// switch c.colTypName {
-		// }
-		log.Println(c.text, " >aslkdfjaslkfj")
-		return time.Time{}, errors.New("cannot convert time data which arrived as string or []uint8 from sql")
+		loc := time.UTC
+		if location != nil {
+			loc = location
+		}
+		text := unquoteVariant(c.colTypName, c.text)
+		t, ok := parseSnowflakeTime(c.colTypName, text, loc)
+		if !ok {
+			t, ok = parseMSSQLDateTimeOffset(c.colTypName, text)
+		}
+		if ok && location != nil {
+			return t.In(location), nil
+		} else if ok {
+			return t, nil
+		}
+		for _, layout := range settings.layouts {
+			if t, err := time.ParseInLocation(layout, text, loc); err == nil {
+				return t.In(loc), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot parse %q as time, expected one of the layouts: %s", text, strings.Join(settings.layouts, ", "))
+	}
+	if c.kind != reflect.Struct {
+		return time.Time{}, errors.New("cannot convert non time data to time")
+	}
+	if location != nil && isWallClock(c.colTypName) {
+		return inWallClock(c.time, location), nil
+	} else if location != nil {
+		return c.time.In(location), nil
 	}
 	return c.time, nil
 }
@@ -274,6 +664,108 @@ func (c Cell) NullTime() (sql.NullTime, error) {
 	}, err
 }
 
+// IP parses inet and cidr columns, which arrive as text, ie, "192.168.0.1" or "192.168.0.1/24"
+// if the column holds a network, the host address is returned
+// data arriving as 4 or 16 raw bytes, ie, from MySQL INET6_ATON, is used as is
+func (c Cell) IP() (net.IP, error) {
+	if !c.valid {
+		return nil, nil
+	}
+	if ip, ok := c.rawIP(); ok {
+		return ip, nil
+	}
+	if strings.Contains(c.text, "/") {
+		ip, _, err := net.ParseCIDR(c.text)
+		return ip, err
+	}
+	if ip := net.ParseIP(c.text); ip != nil {
+		return ip, nil
+	}
+	return nil, fmt.Errorf("cannot parse %q as IP address", c.text)
+}
+
+// raw addresses arrive as 4 or 16 bytes, from binary columns, or holding bytes which are not printable,
+// which tells them apart from addresses as text of the same length, ie, "192.168.100.1/24"
+func (c Cell) rawIP() (net.IP, bool) {
+	if !c.isBytes || (len(c.text) != net.IPv4len && len(c.text) != net.IPv6len) {
+		return nil, false
+	}
+	if c.IsBinary() {
+		return net.IP(c.text), true
+	}
+	for i := 0; i < len(c.text); i++ {
+		if c.text[i] < ' ' || c.text[i] > '~' {
+			return net.IP(c.text), true
+		}
+	}
+	return nil, false
+}
+
+// IPNet parses inet and cidr columns, addresses without a netmask are treated as single hosts (/32 or /128)
+func (c Cell) IPNet() (net.IPNet, error) {
+	if _, ok := c.rawIP(); !ok && strings.Contains(c.text, "/") {
+		ip, ipNet, err := net.ParseCIDR(c.text)
+		if err != nil {
+			return net.IPNet{}, err
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		// inet may hold a host address with a netmask, ie, 192.168.0.1/24, keep the host address
+		return net.IPNet{IP: ip, Mask: ipNet.Mask}, nil
+	}
+	ip, err := c.IP()
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return net.IPNet{IP: ip4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)}, nil
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}, nil
+}
+
+// Value returns the cell as it arrived from the driver, nil if null
+func (c Cell) Value() interface{} {
+	if !c.valid {
+		return nil
+	}
+	switch c.kind {
+	case reflect.Bool:
+		return c.bits != 0
+	case reflect.Int64:
+		return int64(c.bits)
+	case reflect.Float64:
+		return math.Float64frombits(c.bits)
+	case reflect.String:
+		if c.isBytes {
+			return []byte(c.text)
+		}
+		return c.text
+	case reflect.Struct:
+		return c.time
+	}
+	return nil
+}
+
+// RawBytes returns the cell as bytes, numbers and booleans are formatted as text and time as RFC3339, same as database/sql does for sql.RawBytes
+func (c Cell) RawBytes() []byte {
+	switch d := c.Value().(type) {
+	case []byte:
+		return d
+	case string:
+		return []byte(d)
+	case int64:
+		return strconv.AppendInt(nil, d, 10)
+	case float64:
+		return strconv.AppendFloat(nil, d, 'g', -1, 64)
+	case bool:
+		return strconv.AppendBool(nil, d)
+	case time.Time:
+		return d.AppendFormat(nil, time.RFC3339Nano)
+	}
+	return nil
+}
+
 func (c Cell) AsInterface() (interface{}, error) {
 	var i interface{}
 	var err error
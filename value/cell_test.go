@@ -0,0 +1,360 @@
+package value
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+)
+
+func TestIP(t *testing.T) {
+	tests := []struct {
+		src   interface{}
+		ip    string
+		ipNet string
+	}{
+		{"10.0.0.1", "10.0.0.1", "10.0.0.1/32"},
+		{[]byte("10.0.0.1/24"), "10.0.0.1", "10.0.0.1/24"},
+		{"192.168.0.0/16", "192.168.0.0", "192.168.0.0/16"},
+		{"::1", "::1", "::1/128"},
+		{[]byte{10, 0, 0, 1}, "10.0.0.1", "10.0.0.1/32"},
+		{[]byte("192.168.100.1/24"), "192.168.100.1", "192.168.100.1/24"},
+		{[]byte{0x20, 0x01, 0x0d, 0xb8, '/', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, "2001:db8:2f00::1", "2001:db8:2f00::1/128"},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		c.Scan(test.src)
+		ip, err := c.IP()
+		if err != nil {
+			t.Errorf("%v: %s", test.src, err)
+			continue
+		}
+		if ip.String() != test.ip {
+			t.Errorf("%v: expected ip %s, got %s", test.src, test.ip, ip)
+		}
+		ipNet, err := c.IPNet()
+		if err != nil {
+			t.Errorf("%v: %s", test.src, err)
+			continue
+		}
+		if ipNet.String() != test.ipNet {
+			t.Errorf("%v: expected network %s, got %s", test.src, test.ipNet, ipNet.String())
+		}
+	}
+}
+
+func TestRawIP(t *testing.T) {
+	// printable bytes of a binary column are still a raw address
+	c := NewCell("VARBINARY")
+	c.Scan([]byte("1.2."))
+	if ip, err := c.IP(); err != nil || ip.String() != "49.46.50.46" {
+		t.Errorf("expected 49.46.50.46, got %s, %v", ip, err)
+	}
+}
+
+func TestProtoJSON(t *testing.T) {
+	c := NewCell("JSONB")
+	c.Scan([]byte(`{"name": "carta", "tags": ["sql", "proto"]}`))
+	s := &structpb.Struct{}
+	if err := c.ProtoJSON(s); err != nil {
+		t.Fatal(err)
+	}
+	if name := s.Fields["name"].GetStringValue(); name != "carta" {
+		t.Errorf("expected name carta, got %s", name)
+	}
+	if tags := s.Fields["tags"].GetListValue().GetValues(); len(tags) != 2 {
+		t.Errorf("expected 2 tags, got %d", len(tags))
+	}
+}
+
+func TestNegativeUint(t *testing.T) {
+	c := NewCell("")
+	c.Scan(int64(-1))
+	if _, err := c.Uint64(); err == nil {
+		t.Error("expected an error converting -1 to uint64")
+	}
+	if _, err := c.Uint32(); err == nil {
+		t.Error("expected an error converting -1 to uint32")
+	}
+	if d, err := c.Int64(); err != nil || d != -1 {
+		t.Errorf("expected -1, got %d, %v", d, err)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		conv func(c *Cell) error
+	}{
+		{int64(1) << 40, func(c *Cell) error { _, err := c.Int32(); return err }},
+		{int64(1) << 40, func(c *Cell) error { _, err := c.Uint32(); return err }},
+		{float64(1e20), func(c *Cell) error { _, err := c.Int64(); return err }},
+		{float64(1e20), func(c *Cell) error { _, err := c.Uint64(); return err }},
+		{float64(-1), func(c *Cell) error { _, err := c.Uint32(); return err }},
+		{"99999999999", func(c *Cell) error { _, err := c.Int32(); return err }},
+		{"99999999999999999999", func(c *Cell) error { _, err := c.Int64(); return err }},
+		{"-1", func(c *Cell) error { _, err := c.Uint64(); return err }},
+		{int64(-1), func(c *Cell) error { _, err := c.Uint64(); return err }},
+		{int64(-1), func(c *Cell) error { _, err := c.Uint32(); return err }},
+		{"1e40", func(c *Cell) error { _, err := c.Float32(); return err }},
+		{"1e400", func(c *Cell) error { _, err := c.Float64(); return err }},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		c.Scan(test.src)
+		if err := test.conv(c); err == nil {
+			t.Errorf("expected an overflow error converting %v", test.src)
+		} else if _, ok := err.(*OverflowError); !ok {
+			t.Errorf("expected an OverflowError converting %v, got %T: %v", test.src, err, err)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		src interface{}
+		b   bool
+	}{
+		{true, true},
+		{int64(1), true},
+		{int64(0), false},
+		{float64(0), false},
+		{[]byte("1"), true},
+		{[]byte("0"), false},
+		{[]byte{1}, true},
+		{[]byte{0}, false},
+		{"true", true},
+		{"FALSE", false},
+		{"t", true},
+		{[]byte("false"), false},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		c.Scan(test.src)
+		if b, err := c.Bool(); err != nil {
+			t.Errorf("%v: %s", test.src, err)
+		} else if b != test.b {
+			t.Errorf("%v: expected %t, got %t", test.src, test.b, b)
+		}
+	}
+	c := NewCell("")
+	c.Scan("yes")
+	if _, err := c.Bool(); err == nil {
+		t.Error(`expected an error converting "yes" to bool`)
+	}
+}
+
+func TestLenientBool(t *testing.T) {
+	for src, b := range map[string]bool{"t": true, "F": false, "Y ": true, "no": false, "On": true, "0": false} {
+		c := NewCell("")
+		c.Scan(src)
+		if d, err := c.LenientBool(); err != nil {
+			t.Errorf("%q: %s", src, err)
+		} else if d != b {
+			t.Errorf("%q: expected %t, got %t", src, b, d)
+		}
+		if _, err := strconv.ParseBool(src); err == nil {
+			continue
+		}
+		if _, err := c.Bool(); err == nil {
+			t.Errorf("%q: expected an error without lenient parsing", src)
+		}
+	}
+}
+
+func TestNumericBytes(t *testing.T) {
+	c := NewCell("DECIMAL")
+	c.Scan([]byte("10.00"))
+	if d, err := c.Int64(); err != nil || d != 10 {
+		t.Errorf("expected 10, got %d, %v", d, err)
+	}
+	if d, err := c.Uint32(); err != nil || d != 10 {
+		t.Errorf("expected 10, got %d, %v", d, err)
+	}
+	if d, err := c.Float64(); err != nil || d != 10 {
+		t.Errorf("expected 10, got %f, %v", d, err)
+	}
+	c.Scan([]byte("10.50"))
+	if _, err := c.Int64(); err == nil {
+		t.Error("expected an error loading 10.50 onto an integer")
+	}
+	c.Scan([]byte("-1"))
+	if _, err := c.Uint64(); err == nil {
+		t.Error("expected an error loading -1 onto an unsigned integer")
+	}
+	c.Scan([]byte("3000000000.0"))
+	if _, err := c.Int32(); err == nil {
+		t.Error("expected an error loading 3000000000 onto int32")
+	}
+}
+
+func TestTimeFromText(t *testing.T) {
+	expected := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	for _, src := range []interface{}{"2006-01-02T15:04:05Z", "2006-01-02 15:04:05", []byte("2006-01-02 15:04:05+00:00")} {
+		c := NewCell("TEXT")
+		c.Scan(src)
+		if d, err := c.Time(); err != nil {
+			t.Errorf("%s: %s", src, err)
+		} else if !d.Equal(expected) {
+			t.Errorf("%s: expected %s, got %s", src, expected, d)
+		}
+	}
+	c := NewCell("TEXT")
+	c.Scan("yesterday")
+	if _, err := c.Time(); err == nil {
+		t.Error("expected an error parsing yesterday")
+	}
+}
+
+func TestTimeLocation(t *testing.T) {
+	defer SetTimeLocation(nil)
+	est := time.FixedZone("EST", -5*3600)
+	SetTimeLocation(est)
+
+	c := NewCell("TIMESTAMPTZ")
+	c.Scan(time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC))
+	if d, _ := c.Time(); d.Location() != est || d.Hour() != 10 {
+		t.Errorf("expected 10:00 EST, got %s", d)
+	}
+	c.Scan("2006-01-02 10:00:00")
+	if d, _ := c.Time(); !d.Equal(time.Date(2006, 1, 2, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected text to be parsed in EST, got %s", d)
+	}
+}
+
+func TestRawBytes(t *testing.T) {
+	tests := []struct {
+		src interface{}
+		raw string
+	}{
+		{[]byte("abc"), "abc"},
+		{"abc", "abc"},
+		{int64(-7), "-7"},
+		{float64(1.5), "1.5"},
+		{true, "true"},
+		{time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), "2006-01-02T15:04:05Z"},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		c.Scan(test.src)
+		if raw := string(c.RawBytes()); raw != test.raw {
+			t.Errorf("%v: expected %s, got %s", test.src, test.raw, raw)
+		}
+	}
+}
+
+func TestScanOther(t *testing.T) {
+	s, ts := "text", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		src  interface{}
+		conv func(c *Cell) (interface{}, error)
+		want interface{}
+	}{
+		{uint8(7), func(c *Cell) (interface{}, error) { return c.Int64() }, int64(7)},
+		{int32(-7), func(c *Cell) (interface{}, error) { return c.Int64() }, int64(-7)},
+		{uint64(1) << 63, func(c *Cell) (interface{}, error) { return c.Uint64() }, uint64(1) << 63},
+		{float32(1.5), func(c *Cell) (interface{}, error) { return c.Float64() }, 1.5},
+		{&s, func(c *Cell) (interface{}, error) { return c.String() }, "text"},
+		{&ts, func(c *Cell) (interface{}, error) { return c.Time() }, ts},
+		{net.IPv4(10, 0, 0, 1), func(c *Cell) (interface{}, error) { return c.String() }, "10.0.0.1"},
+		{[]string{"a", "b"}, func(c *Cell) (interface{}, error) { return c.String() }, `["a","b"]`},
+		{map[string]int{"a": 1}, func(c *Cell) (interface{}, error) { return c.String() }, `{"a":1}`},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		if err := c.Scan(test.src); err != nil {
+			t.Errorf("%v: %s", test.src, err)
+			continue
+		}
+		got, err := test.conv(c)
+		if err != nil {
+			t.Errorf("%v: %s", test.src, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%v: expected %v, got %v", test.src, test.want, got)
+		}
+	}
+	c := NewCell("")
+	if c.Scan((*int)(nil)); !c.IsNull() {
+		t.Error("expected a nil pointer to be null")
+	}
+	if err := c.Scan(make(chan int)); err == nil {
+		t.Error("expected an error scanning a channel")
+	}
+}
+
+func TestMSSQL(t *testing.T) {
+	c := NewCell("UNIQUEIDENTIFIER")
+	c.Scan([]byte{0xff, 0x19, 0x96, 0x6f, 0x86, 0x8b, 0x11, 0xd0, 0xb4, 0x2d, 0x00, 0xc0, 0x4f, 0xc9, 0x64, 0xff})
+	if s, _ := c.String(); s != "6f9619ff-8b86-d011-b42d-00c04fc964ff" {
+		t.Errorf("expected uuid 6f9619ff-8b86-d011-b42d-00c04fc964ff, got %s", s)
+	}
+
+	c = NewCell("DATETIMEOFFSET")
+	c.Scan("2020-01-02 03:04:05.1234567 +02:00")
+	ts, err := c.Time()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := ts.Zone(); offset != 2*60*60 || ts.UTC() != time.Date(2020, 1, 2, 1, 4, 5, 123456700, time.UTC) {
+		t.Errorf("expected 2020-01-02 03:04:05.1234567 +02:00, got %s", ts)
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	defer SetTimeLocation(nil)
+	ntz := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	loc := time.FixedZone("test", -8*60*60)
+
+	SetTimeLocation(loc)
+	c := NewCell("TIMESTAMP_NTZ")
+	c.Scan(ntz)
+	if ts, _ := c.Time(); ts != time.Date(2020, 1, 2, 3, 4, 5, 0, loc) {
+		t.Errorf("expected the wall clock of TIMESTAMP_NTZ to be kept, got %s", ts)
+	}
+	SetTimeLocation(nil)
+
+	c = NewCell("TIMESTAMP_TZ")
+	c.Scan("2020-01-02 03:04:05.123 -0800")
+	if ts, err := c.Time(); err != nil || !ts.Equal(time.Date(2020, 1, 2, 11, 4, 5, 123000000, time.UTC)) {
+		t.Errorf("expected 2020-01-02 03:04:05.123 -0800, got %s, %v", ts, err)
+	}
+
+	c = NewCell("VARIANT")
+	c.Scan(`"2020-01-02 03:04:05.000"`)
+	if ts, err := c.Time(); err != nil || !ts.Equal(ntz) {
+		t.Errorf("expected %s, got %s, %v", ntz, ts, err)
+	}
+	c.Scan(`"text"`)
+	if s, _ := c.String(); s != "text" {
+		t.Errorf("expected text, got %s", s)
+	}
+	c.Scan(`{"a": 1}`)
+	if s, _ := c.String(); s != `{"a": 1}` {
+		t.Errorf("expected objects to be kept, got %s", s)
+	}
+}
+
+func TestSetTimeLayoutsConcurrently(t *testing.T) {
+	defer SetTimeLayouts(currentTimeSettings().layouts...)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetTimeLayouts("2006-01-02")
+			SetTimeLocation(time.UTC)
+		}
+	}()
+	c := NewCell("TEXT")
+	c.Scan("2006-01-02")
+	for i := 0; i < 100; i++ {
+		if _, err := c.Time(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	SetTimeLocation(nil)
+}
@@ -0,0 +1,98 @@
+package value
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFloat32(t *testing.T) {
+	c := NewCell("")
+	c.Scan(float64(1.5))
+	if d, err := c.Float32(); err != nil || d != 1.5 {
+		t.Errorf("expected 1.5, got %v, %v", d, err)
+	}
+}
+
+func TestIntegerCells(t *testing.T) {
+	c := NewCell("")
+	c.Scan(int64(-3))
+	if c.Kind() != reflect.Int64 {
+		t.Errorf("expected an int64 cell, got %v", c.Kind())
+	}
+	if d, err := c.Int64(); err != nil || d != -3 {
+		t.Errorf("expected -3, got %d, %v", d, err)
+	}
+	if d, err := c.Float64(); err != nil || d != -3 {
+		t.Errorf("expected -3, got %v, %v", d, err)
+	}
+	if d, err := c.Float32(); err != nil || d != -3 {
+		t.Errorf("expected -3, got %v, %v", d, err)
+	}
+}
+
+func TestFloatCellsAsIntegers(t *testing.T) {
+	c := NewCell("")
+	c.Scan(float64(42))
+	if d, err := c.Int64(); err != nil || d != 42 {
+		t.Errorf("expected 42, got %d, %v", d, err)
+	}
+	if d, err := c.Int32(); err != nil || d != 42 {
+		t.Errorf("expected 42, got %d, %v", d, err)
+	}
+	if d, err := c.Uint64(); err != nil || d != 42 {
+		t.Errorf("expected 42, got %d, %v", d, err)
+	}
+	if d, err := c.Uint32(); err != nil || d != 42 {
+		t.Errorf("expected 42, got %d, %v", d, err)
+	}
+}
+
+func TestNonIntegralFloatCells(t *testing.T) {
+	for _, f := range []float64{1.9, -0.5, math.NaN()} {
+		c := NewCell("")
+		c.Scan(f)
+		if d, err := c.Int64(); err == nil {
+			t.Errorf("%v: expected an error loading onto int64, got %d", f, d)
+		}
+		if d, err := c.Int32(); err == nil {
+			t.Errorf("%v: expected an error loading onto int32, got %d", f, d)
+		}
+		if d, err := c.Uint64(); err == nil {
+			t.Errorf("%v: expected an error loading onto uint64, got %d", f, d)
+		}
+		if d, err := c.Uint32(); err == nil {
+			t.Errorf("%v: expected an error loading onto uint32, got %d", f, d)
+		}
+	}
+	c := NewCell("")
+	c.Scan(math.Inf(1))
+	if _, err := c.Int64(); err == nil {
+		t.Error("expected an error loading infinity onto int64")
+	} else if _, ok := err.(*OverflowError); !ok {
+		t.Errorf("expected an OverflowError loading infinity onto int64, got %v", err)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text  string
+		value interface{}
+	}{
+		{"12", int64(12)},
+		{"-1.5", -1.5},
+		{"99999999999999999999", "99999999999999999999"},
+		{"abc", "abc"},
+	}
+	for _, test := range tests {
+		c := NewCell("NUMBER")
+		c.Scan(test.text)
+		c.ParseNumber()
+		if v := c.Value(); v != test.value {
+			t.Errorf("%s: expected %#v, got %#v", test.text, test.value, v)
+		}
+		if s, _ := c.String(); s != test.text {
+			t.Errorf("%s: expected the text to be kept, got %q", test.text, s)
+		}
+	}
+}
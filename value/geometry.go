@@ -0,0 +1,195 @@
+package value
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"reflect"
+)
+
+const (
+	// EWKB flags set on the geometry type by PostGIS
+	ewkbZ    uint32 = 0x80000000
+	ewkbM    uint32 = 0x40000000
+	ewkbSRID uint32 = 0x20000000
+
+	// MySQL reports spatial columns with this database type name
+	mysqlGeometry = "GEOMETRY"
+)
+
+var errInvalidGeometry = errors.New("invalid WKB geometry")
+
+// Geometry returns the cell as plain WKB along with its SRID.
+// Geometry may arrive in one of the following formats:
+// PostGIS EWKB, either binary or hex encoded (lib/pq returns hex text)
+// MySQL internal format, 4 byte little endian SRID followed by WKB
+// plain WKB, in which case SRID is 0
+func (c Cell) Geometry() ([]byte, uint32, error) {
+	if c.kind != reflect.String {
+		return nil, 0, errors.New("cannot convert non binary data to geometry")
+	}
+	b := []byte(c.text)
+	if isHex(b) {
+		d := make([]byte, hex.DecodedLen(len(b)))
+		if _, err := hex.Decode(d, b); err != nil {
+			return nil, 0, err
+		}
+		b = d
+	} else if c.colTypName == mysqlGeometry {
+		if len(b) < 4 {
+			return nil, 0, errInvalidGeometry
+		}
+		srid := binary.LittleEndian.Uint32(b[:4])
+		wkb, _, err := parseEWKB(b[4:])
+		return wkb, srid, err
+	}
+	return parseEWKB(b)
+}
+
+// parseEWKB strips the SRID and the EWKB flags from the geometry, returning ISO WKB,
+// the type codes of nested geometries, ie, points of a MULTIPOINT Z, are rewritten as well, since PostGIS flags them too
+func parseEWKB(b []byte) ([]byte, uint32, error) {
+	wkb, n, srid, err := appendISOWKB(make([]byte, 0, len(b)), b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n != len(b) {
+		return nil, 0, errInvalidGeometry
+	}
+	return wkb, srid, nil
+}
+
+// appends the geometry at the start of b to wkb as ISO WKB, returns the number of bytes read and the SRID of the geometry, if any
+func appendISOWKB(wkb []byte, b []byte) ([]byte, int, uint32, error) {
+	var order binary.ByteOrder
+	if len(b) < 5 {
+		return nil, 0, 0, errInvalidGeometry
+	}
+	switch b[0] {
+	case 0:
+		order = binary.BigEndian
+	case 1:
+		order = binary.LittleEndian
+	default:
+		return nil, 0, 0, errInvalidGeometry
+	}
+	typ := order.Uint32(b[1:5])
+	base := typ &^ (ewkbZ | ewkbM | ewkbSRID)
+	z, m := typ&ewkbZ != 0, typ&ewkbM != 0
+
+	// geometries may already use ISO type codes, ie, 1001 for POINT Z
+	switch base / 1000 {
+	case 0:
+	case 1:
+		z = true
+	case 2:
+		m = true
+	case 3:
+		z, m = true, true
+	default:
+		return nil, 0, 0, errInvalidGeometry
+	}
+	base %= 1000
+
+	// convert EWKB to ISO WKB type codes, ie, Z adds 1000, M adds 2000
+	isoTyp, dims := base, 2
+	if z {
+		isoTyp += 1000
+		dims++
+	}
+	if m {
+		isoTyp += 2000
+		dims++
+	}
+
+	pos := 5
+	var srid uint32
+	if typ&ewkbSRID != 0 {
+		if len(b) < 9 {
+			return nil, 0, 0, errInvalidGeometry
+		}
+		srid = order.Uint32(b[5:9])
+		pos = 9
+	}
+	wkb = append(wkb, b[0], 0, 0, 0, 0)
+	order.PutUint32(wkb[len(wkb)-4:], isoTyp)
+
+	// reads a count of points, rings or geometries, each taking at least size bytes
+	count := func(size int) (int, error) {
+		if len(b)-pos < 4 {
+			return 0, errInvalidGeometry
+		}
+		n := order.Uint32(b[pos : pos+4])
+		wkb = append(wkb, b[pos:pos+4]...)
+		pos += 4
+		if uint64(n) > uint64((len(b)-pos)/size) {
+			return 0, errInvalidGeometry
+		}
+		return int(n), nil
+	}
+	point := 8 * dims
+	points := func(n int) error {
+		if len(b)-pos < n*point {
+			return errInvalidGeometry
+		}
+		wkb = append(wkb, b[pos:pos+n*point]...)
+		pos += n * point
+		return nil
+	}
+
+	switch base {
+	case 1: // point
+		if err := points(1); err != nil {
+			return nil, 0, 0, err
+		}
+	case 2, 8: // linestring, circularstring
+		n, err := count(point)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if err = points(n); err != nil {
+			return nil, 0, 0, err
+		}
+	case 3, 17: // polygon, triangle
+		rings, err := count(4)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for i := 0; i < rings; i++ {
+			n, err := count(point)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if err = points(n); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+	case 4, 5, 6, 7, 9, 10, 11, 12, 15, 16: // multi geometries, collections, compound curves and surfaces
+		geometries, err := count(5)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for i := 0; i < geometries; i++ {
+			var n int
+			if wkb, n, _, err = appendISOWKB(wkb, b[pos:]); err != nil {
+				return nil, 0, 0, err
+			}
+			pos += n
+		}
+	default:
+		return nil, 0, 0, errInvalidGeometry
+	}
+	return wkb, pos, srid, nil
+}
+
+func isHex(b []byte) bool {
+	if len(b) == 0 || len(b)%2 != 0 {
+		return false
+	}
+	for _, r := range b {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package value
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// POINT(1 2) as ISO WKB, little endian
+const pointWKB = "0101000000000000000000f03f0000000000000040"
+
+func TestGeometry(t *testing.T) {
+	wkb, _ := hex.DecodeString(pointWKB)
+	ewkb, _ := hex.DecodeString("0101000020e6100000000000000000f03f0000000000000040")
+	mysql, _ := hex.DecodeString("e6100000" + pointWKB)
+
+	tests := []struct {
+		name       string
+		colTypName string
+		src        []byte
+		srid       uint32
+	}{
+		{"wkb", "", wkb, 0},
+		{"ewkb", "", ewkb, 4326},
+		{"ewkb hex", "", []byte(hex.EncodeToString(ewkb)), 4326},
+		{"mysql", "GEOMETRY", mysql, 4326},
+	}
+	for _, test := range tests {
+		c := NewCell(test.colTypName)
+		c.Scan(test.src)
+		b, srid, err := c.Geometry()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if srid != test.srid {
+			t.Errorf("%s: expected srid %d, got %d", test.name, test.srid, srid)
+		}
+		if !bytes.Equal(b, wkb) {
+			t.Errorf("%s: expected %x, got %x", test.name, wkb, b)
+		}
+	}
+}
+
+func TestNestedGeometry(t *testing.T) {
+	tests := []struct {
+		name string
+		ewkb string
+		wkb  string
+		srid uint32
+	}{
+		{
+			"MULTIPOINT Z",
+			"01040000a0e6100000020000000101000080000000000000f03f000000000000004000000000000008400101000080000000000000104000000000000014400000000000001840",
+			"01ec0300000200000001e9030000000000000000f03f0000000000000040000000000000084001e9030000000000000000104000000000000014400000000000001840",
+			4326,
+		},
+		{
+			"GEOMETRYCOLLECTION M with a big endian LINESTRING M",
+			"0107000040020000000101000040000000000000f03f000000000000004000000000000008400040000002000000023ff000000000000040000000000000004008000000000000401000000000000040140000000000004018000000000000",
+			"01d70700000200000001d1070000000000000000f03f0000000000000040000000000000084000000007d2000000023ff000000000000040000000000000004008000000000000401000000000000040140000000000004018000000000000",
+			0,
+		},
+		{
+			"MULTIPOLYGON ZM",
+			"01060000e0110f00000100000001030000c0010000000400000000000000000000000000000000000000000000000000f03f0000000000000040000000000000f03f0000000000000000000000000000f03f0000000000000040000000000000f03f000000000000f03f000000000000f03f000000000000004000000000000000000000000000000000000000000000f03f0000000000000040",
+			"01be0b00000100000001bb0b0000010000000400000000000000000000000000000000000000000000000000f03f0000000000000040000000000000f03f0000000000000000000000000000f03f0000000000000040000000000000f03f000000000000f03f000000000000f03f000000000000004000000000000000000000000000000000000000000000f03f0000000000000040",
+			3857,
+		},
+	}
+	for _, test := range tests {
+		ewkb, _ := hex.DecodeString(test.ewkb)
+		wkb, _ := hex.DecodeString(test.wkb)
+		for _, src := range []string{"ewkb", "wkb"} {
+			c := NewCell("")
+			if src == "ewkb" {
+				c.Scan(ewkb)
+			} else {
+				c.Scan(wkb)
+			}
+			b, srid, err := c.Geometry()
+			if err != nil {
+				t.Errorf("%s from %s: %s", test.name, src, err)
+				continue
+			}
+			if src == "ewkb" && srid != test.srid {
+				t.Errorf("%s: expected srid %d, got %d", test.name, test.srid, srid)
+			}
+			if !bytes.Equal(b, wkb) {
+				t.Errorf("%s from %s: expected %x, got %x", test.name, src, wkb, b)
+			}
+		}
+		for _, invalid := range [][]byte{ewkb[:len(ewkb)-1], append(ewkb[:len(ewkb):len(ewkb)], 0)} {
+			c := NewCell("")
+			c.Scan(invalid)
+			if _, _, err := c.Geometry(); err == nil {
+				t.Errorf("%s: expected an error for %d bytes", test.name, len(invalid))
+			}
+		}
+	}
+}
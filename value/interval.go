@@ -0,0 +1,148 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// length of interval units which do not have a fixed length, same as postgres uses when extracting epoch from an interval
+const (
+	day   = 24 * time.Hour
+	month = 30 * day
+	year  = 365*day + 6*time.Hour
+)
+
+var intervalUnits = map[string]time.Duration{
+	"year": year, "years": year,
+	"mon": month, "mons": month, "month": month, "months": month,
+	"week": 7 * day, "weeks": 7 * day,
+	"day": day, "days": day,
+	"hour": time.Hour, "hours": time.Hour,
+	"min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+}
+
+// parseInterval parses textual intervals, the following formats are supported
+// postgres:          "1 year 2 mons 3 days 04:05:06.5", "-1 days +02:03:00"
+// postgres verbose:  "@ 1 day 2 hours ago"
+// iso 8601:          "P1Y2M3DT4H5M6.5S"
+// mysql time:        "838:59:59", "-01:00:00.5"
+func parseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "P") || strings.HasPrefix(s, "-P") {
+		return parseISOInterval(s)
+	}
+	var (
+		d   time.Duration
+		neg bool
+	)
+	fields := strings.Fields(strings.TrimPrefix(s, "@"))
+	if len(fields) > 0 && fields[len(fields)-1] == "ago" {
+		neg = true
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("cannot parse %q as interval", s)
+	}
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			clock, err := parseClock(fields[i])
+			if err != nil {
+				return 0, err
+			}
+			d += clock
+			continue
+		}
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("cannot parse %q as interval", s)
+		}
+		n, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse %q as interval", s)
+		}
+		unit, ok := intervalUnits[strings.ToLower(fields[i+1])]
+		if !ok {
+			return 0, fmt.Errorf("cannot parse %q as interval, unknown unit %s", s, fields[i+1])
+		}
+		d += time.Duration(math.Round(n * float64(unit)))
+		i++
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parses [-+]hh:mm[:ss[.fraction]], hours may exceed 24
+func parseClock(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	parts := strings.Split(strings.TrimLeft(s, "+-"), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("cannot parse %q as time of day", s)
+	}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse %q as time of day", s)
+		}
+		d += time.Duration(math.Round(n * float64(units[i])))
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+func parseISOInterval(s string) (time.Duration, error) {
+	var (
+		d      time.Duration
+		inTime bool
+		num    string
+	)
+	neg := strings.HasPrefix(s, "-")
+	for _, r := range strings.TrimPrefix(strings.TrimPrefix(s, "-"), "P") {
+		var unit time.Duration
+		switch {
+		case r == 'T':
+			inTime = true
+			continue
+		case r >= '0' && r <= '9' || r == '.' || r == ',' || r == '-':
+			num += string(r)
+			continue
+		case r == 'Y':
+			unit = year
+		case r == 'M' && !inTime:
+			unit = month
+		case r == 'W':
+			unit = 7 * day
+		case r == 'D':
+			unit = day
+		case r == 'H':
+			unit = time.Hour
+		case r == 'M':
+			unit = time.Minute
+		case r == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("cannot parse %q as interval", s)
+		}
+		n, err := strconv.ParseFloat(strings.Replace(num, ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse %q as interval", s)
+		}
+		d += time.Duration(math.Round(n * float64(unit)))
+		num = ""
+	}
+	if num != "" {
+		return 0, fmt.Errorf("cannot parse %q as interval", s)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,59 @@
+package value
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		src string
+		d   time.Duration
+	}{
+		{"00:00:01.5", 1500 * time.Millisecond},
+		{"3 days 04:05:06", 3*day + 4*time.Hour + 5*time.Minute + 6*time.Second},
+		{"1 year 2 mons", year + 2*month},
+		{"-1 days +02:03:00", -day + 2*time.Hour + 3*time.Minute},
+		{"@ 1 day 2 hours ago", -(day + 2*time.Hour)},
+		{"P1DT2H30M", day + 2*time.Hour + 30*time.Minute},
+		{"PT0.25S", 250 * time.Millisecond},
+		{"838:59:59", 838*time.Hour + 59*time.Minute + 59*time.Second},
+		{"-01:00:00", -time.Hour},
+	}
+	for _, test := range tests {
+		d, err := parseInterval(test.src)
+		if err != nil {
+			t.Errorf("%s: %s", test.src, err)
+		} else if d != test.d {
+			t.Errorf("%s: expected %s, got %s", test.src, test.d, d)
+		}
+	}
+	for _, src := range []string{"", "1 fortnight", "P1X", "12"} {
+		if _, err := parseInterval(src); err == nil {
+			t.Errorf("%q: expected an error", src)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		unit time.Duration
+		d    time.Duration
+	}{
+		{int64(1500), time.Millisecond, 1500 * time.Millisecond},
+		{float64(1.5), time.Second, 1500 * time.Millisecond},
+		{[]byte("90"), time.Second, 90 * time.Second},
+		{"01:00:00", time.Millisecond, time.Hour},
+	}
+	for _, test := range tests {
+		c := NewCell("")
+		c.Scan(test.src)
+		d, err := c.Duration(test.unit)
+		if err != nil {
+			t.Errorf("%v: %s", test.src, err)
+		} else if d != test.d {
+			t.Errorf("%v: expected %s, got %s", test.src, test.d, d)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package value
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	mssqlUniqueIdentifier = "UNIQUEIDENTIFIER"
+	mssqlDateTimeOffset   = "DATETIMEOFFSET"
+)
+
+// layout of datetimeoffset values of SQL Server which arrive as text
+const mssqlDateTimeOffsetLayout = "2006-01-02 15:04:05.9999999 -07:00"
+
+// formats a uniqueidentifier of SQL Server, which go-mssqldb returns as bytes in mixed-endian order,
+// where the first three groups are little-endian, as a canonical uuid, ie, "6F9619FF-8B86-D011-B42D-00C04FC964FF"
+// is returned as bytes ff 19 96 6f 86 8b 11 d0 b4 2d 00 c0 4f c9 64 ff
+func formatMSSQLUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x",
+		[]byte{b[3], b[2], b[1], b[0]}, []byte{b[5], b[4]}, []byte{b[7], b[6]}, b[8:10], b[10:16])
+}
+
+func isMSSQLUUID(colTypName string, b []byte) bool {
+	return len(b) == 16 && strings.EqualFold(colTypName, mssqlUniqueIdentifier)
+}
+
+// parses datetimeoffset values of SQL Server which arrive as text, keeping their offset
+func parseMSSQLDateTimeOffset(colTypName string, text string) (time.Time, bool) {
+	if !strings.EqualFold(colTypName, mssqlDateTimeOffset) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(mssqlDateTimeOffsetLayout, text)
+	return t, err == nil
+}
@@ -0,0 +1,59 @@
+package value
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// layouts of timestamps of Snowflake which arrive as text, ie, in VARIANT columns or with the default output formats,
+// which are tried before the layouts set with SetTimeLayouts
+var snowflakeTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// timestamps without a time zone, whose wall clock is kept when a location is set with SetTimeLocation, instead of converting them,
+// gosnowflake returns TIMESTAMP_NTZ in UTC, although it is not in any time zone
+func isWallClock(colTypName string) bool {
+	return strings.EqualFold(colTypName, "TIMESTAMP_NTZ")
+}
+
+func isSnowflakeTime(colTypName string) bool {
+	switch strings.ToUpper(colTypName) {
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ", "VARIANT":
+		return true
+	}
+	return false
+}
+
+// parses timestamps of Snowflake which arrive as text, timestamps without an offset are parsed in loc
+func parseSnowflakeTime(colTypName string, text string, loc *time.Location) (time.Time, bool) {
+	if !isSnowflakeTime(colTypName) {
+		return time.Time{}, false
+	}
+	for _, layout := range snowflakeTimeLayouts {
+		if t, err := time.ParseInLocation(layout, text, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// VARIANT columns of Snowflake hold JSON, strings are quoted, ie, "text" arrives as `"text"`
+func unquoteVariant(colTypName string, text string) string {
+	if !strings.EqualFold(colTypName, "VARIANT") || !strings.HasPrefix(text, `"`) {
+		return text
+	}
+	var s string
+	if err := json.Unmarshal([]byte(text), &s); err != nil {
+		return text
+	}
+	return s
+}
+
+// keeps the wall clock of t in loc
+func inWallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
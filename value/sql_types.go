@@ -2,10 +2,14 @@ package value
 
 import (
 	"database/sql"
+	"net"
 	"reflect"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
+	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 )
 
 // Value represents go data types which carta supports for loading as well as what data types arrive from the sql driver
@@ -23,6 +27,14 @@ const (
 	NullInt64
 	NullString
 	NullTime
+	IP
+	IPNet
+	Wrapper
+	ProtoJSON
+	Duration
+	GoDuration
+	RawBytes
+	Raw
 	Float64
 	Float32
 	Int
@@ -65,6 +77,68 @@ var BasicTypes = map[reflect.Type]Value{
 	reflect.TypeOf(sql.NullInt64{}):       NullInt64,
 	reflect.TypeOf(sql.NullString{}):      NullString,
 	reflect.TypeOf(sql.NullTime{}):        NullTime,
+	reflect.TypeOf(net.IP{}):              IP,
+	reflect.TypeOf(net.IPNet{}):           IPNet,
+	reflect.TypeOf(duration.Duration{}):   Duration,
+	reflect.TypeOf(time.Duration(0)):      GoDuration,
+	reflect.TypeOf(sql.RawBytes{}):        RawBytes,
+	reflect.TypeOf(RawValue{}):            Raw,
+}
+
+// RawValue holds the value of a column as it arrived from the driver,
+// one of nil, int64, float64, bool, []byte, string or time.Time
+type RawValue struct {
+	Value interface{}
+}
+
+// Database types holding binary data, as reported by lib/pq and go-sql-driver/mysql
+var BinaryTypes = map[string]bool{
+	"BYTEA":      true,
+	"BLOB":       true,
+	"TINYBLOB":   true,
+	"MEDIUMBLOB": true,
+	"LONGBLOB":   true,
+	"BINARY":     true,
+	"VARBINARY":  true,
+}
+
+// numeric database types of Oracle, whose values godror returns as godror.Number, which is text
+var OracleNumberTypes = map[string]bool{
+	"NUMBER":        true,
+	"FLOAT":         true,
+	"BINARY_FLOAT":  true,
+	"BINARY_DOUBLE": true,
+}
+
+// Proto wrapper types, ie, wrappers.StringValue, mapped to the index of their Value field
+// wrappers are loaded by setting the Value field, null columns leave the wrapper nil
+var WrapperTypes = map[reflect.Type]int{}
+
+func init() {
+	for _, t := range []reflect.Type{
+		reflect.TypeOf((*wrappers.DoubleValue)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.FloatValue)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.Int64Value)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.UInt64Value)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.Int32Value)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.UInt32Value)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.BoolValue)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.StringValue)(nil)).Elem(),
+		reflect.TypeOf((*wrappers.BytesValue)(nil)).Elem(),
+	} {
+		f, _ := t.FieldByName("Value")
+		WrapperTypes[t] = f.Index[0]
+		BasicTypes[t] = Wrapper
+	}
+
+	// google.protobuf.Struct, ListValue and Value are loaded from json columns
+	for _, t := range []reflect.Type{
+		reflect.TypeOf((*structpb.Struct)(nil)).Elem(),
+		reflect.TypeOf((*structpb.ListValue)(nil)).Elem(),
+		reflect.TypeOf((*structpb.Value)(nil)).Elem(),
+	} {
+		BasicTypes[t] = ProtoJSON
+	}
 }
 
 var NullableTypes = map[reflect.Type]Value{
@@ -74,6 +148,9 @@ var NullableTypes = map[reflect.Type]Value{
 	reflect.TypeOf(sql.NullInt64{}):   NullInt64,
 	reflect.TypeOf(sql.NullString{}):  NullString,
 	reflect.TypeOf(sql.NullTime{}):    NullTime,
+	reflect.TypeOf(net.IP{}):          IP,
+	reflect.TypeOf(sql.RawBytes{}):    RawBytes,
+	reflect.TypeOf(RawValue{}):        Raw,
 }
 
 // Map of database data types to go types
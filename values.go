@@ -0,0 +1,63 @@
+package carta
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// MapValues maps rows of values onto dst as Map does, without a database, ie, in unit tests, or for results of other sources,
+// such as responses of APIs or cached snapshots of results, example:
+//
+//	blogs := []Blog{}
+//	err := carta.MapValues([]string{"blog_id", "title"}, [][]interface{}{{1, "first"}, {2, "second"}}, &blogs)
+//
+// every row has a value for each column, values are converted as arguments of queries are, ie, int to int64,
+// or with driver.Valuer, nil values are null
+func MapValues(columns []string, rows [][]interface{}, dst interface{}, opts ...Option) error {
+	return Map(&valuesSource{columns: columns, rows: rows}, dst, opts...)
+}
+
+// source of rows held in memory
+type valuesSource struct {
+	columns []string
+	rows    [][]interface{}
+	n       int // number of rows read
+}
+
+func (s *valuesSource) Columns() ([]string, error) {
+	return s.columns, nil
+}
+
+func (s *valuesSource) Next() bool {
+	if s.n >= len(s.rows) {
+		return false
+	}
+	s.n++
+	return true
+}
+
+func (s *valuesSource) Scan(dest ...interface{}) error {
+	row := s.rows[s.n-1]
+	if len(row) != len(dest) {
+		return fmt.Errorf("carta: row %d has %d values, expected a value for each of %d columns", s.n, len(row), len(dest))
+	}
+	for i, v := range row {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			return fmt.Errorf("carta: cannot load value of column %s of row %d: %w", s.columns[i], s.n, err)
+		}
+		if err = dest[i].(sql.Scanner).Scan(dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *valuesSource) Err() error {
+	return nil
+}
+
+func (s *valuesSource) Close() error {
+	return nil
+}
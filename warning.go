@@ -0,0 +1,56 @@
+package carta
+
+import "fmt"
+
+// Warning describes a column or a field which did not match, which does not fail the mapping unless the strict options are set
+type Warning struct {
+	Column string // column which does not match any field, empty for fields
+	Field  string // path of a field which does not match any column, ie, "Posts.Title", empty for columns
+}
+
+func (w Warning) String() string {
+	if w.Column != "" {
+		return fmt.Sprintf("column %s does not match any field", w.Column)
+	}
+	return fmt.Sprintf("field %s does not match any column", w.Field)
+}
+
+// WithWarnings appends warnings of the mapping to warnings, ie, to report unmatched columns to telemetry
+func WithWarnings(warnings *[]Warning) Option {
+	return func(o *options) {
+		o.warnings = warnings
+	}
+}
+
+// appends warnings of the mapper to warnings set with WithWarnings, and logs them with the logger, if any
+func (m *Mapper) warn(o *options) {
+	if o.warnings == nil && o.logger == nil {
+		return
+	}
+	warnings := m.warnings()
+	if o.warnings != nil {
+		*o.warnings = append(*o.warnings, warnings...)
+	}
+	if o.logger != nil {
+		sl, structured := o.logger.(structuredLogger)
+		for _, w := range warnings {
+			if structured {
+				sl.logWarning(m.dstTyp, w)
+			} else {
+				o.logger.Printf("carta: mapping onto %s: %s", m.dstTyp, w)
+			}
+		}
+	}
+}
+
+// unmatched columns and fields of the mapper
+func (m *Mapper) warnings() []Warning {
+	warnings := []Warning{}
+	for _, i := range m.unmatchedColumns {
+		warnings = append(warnings, Warning{Column: m.columns[i]})
+	}
+	for _, path := range m.unmatchedFields {
+		warnings = append(warnings, Warning{Field: path})
+	}
+	return warnings
+}
@@ -0,0 +1,54 @@
+package carta_test
+
+import (
+	"testing"
+
+	"github.com/jackskj/carta"
+)
+
+type warnedPost struct {
+	PostId int
+	Title  *string
+}
+
+type warnedBlog struct {
+	BlogId int
+	Posts  []warnedPost
+}
+
+func TestWithWarnings(t *testing.T) {
+	columns := []string{"blog_id", "post_id", "extra"}
+	rows := [][]interface{}{{int64(1), int64(1), int64(0)}}
+	warnings := []carta.Warning{{Column: "earlier"}}
+	for i := 0; i < 2; i++ {
+		blogs := []warnedBlog{}
+		if err := carta.MapValues(columns, rows, &blogs, carta.WithWarnings(&warnings)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// warnings of every call are appended, including calls which used a cached mapper
+	expected := []carta.Warning{{Column: "earlier"}, {Column: "extra"}, {Field: "Posts.Title"}, {Column: "extra"}, {Field: "Posts.Title"}}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected warnings %v, got %v", expected, warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected warning %v, got %v", expected[i], warnings[i])
+		}
+	}
+	if s := warnings[1].String(); s != "column extra does not match any field" {
+		t.Errorf("unexpected description %q", s)
+	}
+	if s := warnings[2].String(); s != "field Posts.Title does not match any column" {
+		t.Errorf("unexpected description %q", s)
+	}
+
+	warnings = nil
+	blogs := []warnedBlog{}
+	if err := carta.MapValues([]string{"blog_id", "post_id", "title"}, [][]interface{}{{int64(1), int64(1), "a"}}, &blogs, carta.WithWarnings(&warnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings when every column and field match, got %v", warnings)
+	}
+}